@@ -6,44 +6,93 @@ import (
 	"io"
 	"strings"
 
+	"github.com/ppiankov/ancc/internal/probe"
 	"github.com/ppiankov/ancc/internal/validator"
 )
 
 // Check name to human-readable label mapping.
 var checkLabels = map[string]string{
-	validator.CheckSkillMDExists:    "SKILL.md exists",
-	validator.CheckSkillMDInstall:   "Install section",
-	validator.CheckSkillMDCommands:  "Commands section",
-	validator.CheckSkillMDFlags:     "Flags documented",
-	validator.CheckSkillMDJSON:      "JSON output schema",
-	validator.CheckSkillMDExitCodes: "Exit codes documented",
-	validator.CheckSkillMDNotDo:     "What this does NOT do",
-	validator.CheckSkillMDParsing:   "Parsing examples",
-	validator.CheckHasInitCommand:   "Init command",
-	validator.CheckHasDoctorCommand: "Doctor command",
-	validator.CheckHasBinaryRelease: "Binary release",
+	validator.CheckSkillMDExists:     "SKILL.md exists",
+	validator.CheckSkillMDInstall:    "Install section",
+	validator.CheckSkillMDCommands:   "Commands section",
+	validator.CheckSkillMDFlags:      "Flags documented",
+	validator.CheckSkillMDJSON:       "JSON output schema",
+	validator.CheckSkillMDExitCodes:  "Exit codes documented",
+	validator.CheckSkillMDNotDo:      "What this does NOT do",
+	validator.CheckSkillMDParsing:    "Parsing examples",
+	validator.CheckHasInitCommand:    "Init command",
+	validator.CheckHasDoctorCommand:  "Doctor command",
+	validator.CheckHasBinaryRelease:  "Binary release",
+	validator.CheckCommandDrift:      "Commands match source",
+	validator.CheckInstallAssets:     "Install download paths",
+	validator.CheckReleaseChecksums:  "Release checksums",
+	validator.CheckReleaseSignatures: "Release signatures",
+	validator.CheckReleaseSBOM:       "Release SBOM",
+	validator.CheckReleaseProvenance: "Release provenance",
+	probe.CheckJSONOutput:            "Probe JSON output",
+	probe.CheckErrorPath:             "Probe error path",
+	probe.CheckBounded:               "Probe bounded execution",
+	probe.CheckDeterminism:           "Probe determinism",
 }
 
 const labelWidth = 35
 
 func formatText(w io.Writer, result *validator.ValidationResult, verbose bool) {
-	for _, c := range result.Checks {
-		if !verbose && c.Status == validator.StatusPass {
-			continue
+	if len(result.Tools) == 0 {
+		writeChecks(w, "  ", result.Checks, verbose)
+	} else {
+		// Several tools: each tool's checks, then the repo-level ones.
+		for _, t := range result.Tools {
+			_, _ = fmt.Fprintf(w, "  %s: %s\n", toolLabel(t), strings.ToUpper(t.Status))
+			writeChecks(w, "    ", t.Checks, verbose)
 		}
+		_, _ = fmt.Fprintln(w, "  Repository:")
+		writeChecks(w, "    ", result.Checks, verbose)
+	}
 
-		label := checkLabels[c.Name]
-		if label == "" {
-			label = c.Name
+	_, _ = fmt.Fprintln(w)
+	if result.Commit != "" {
+		_, _ = fmt.Fprintf(w, "  Commit: %s\n", result.Commit)
+	}
+	if result.Release != "" {
+		_, _ = fmt.Fprintf(w, "  Release: %s\n", result.Release)
+	}
+	_, _ = fmt.Fprintf(w, "  Result: %s (%d pass, %d fail, %d warn)\n",
+		strings.ToUpper(result.Status),
+		result.Summary.Pass,
+		result.Summary.Fail,
+		result.Summary.Warn,
+	)
+}
+
+// toolLabel names a tool by its directory, by its name for a tool of a
+// multi-tool SKILL.md at the root, or by both.
+func toolLabel(t validator.ToolResult) string {
+	switch {
+	case t.Name == "":
+		return t.Path
+	case t.Path == ".":
+		return t.Name
+	}
+	return fmt.Sprintf("%s (%s)", t.Path, t.Name)
+}
+
+// writeChecks writes one line per check, skipping passing checks unless
+// verbose.
+func writeChecks(w io.Writer, indent string, checks []validator.CheckResult, verbose bool) {
+	for _, c := range checks {
+		if !verbose && c.Status == validator.StatusPass {
+			continue
 		}
 
+		label := checkLabel(c.Name)
 		dots := labelWidth - len(label)
 		if dots < 3 {
 			dots = 3
 		}
 
 		status := strings.ToUpper(c.Status)
-		line := fmt.Sprintf("  %s %s %s", label, strings.Repeat(".", dots), status)
+		line := fmt.Sprintf("%s%s %s %s", indent, label, strings.Repeat(".", dots), status)
 
 		if c.Status != validator.StatusPass && c.Message != "" {
 			line += "  " + c.Message
@@ -51,17 +100,60 @@ func formatText(w io.Writer, result *validator.ValidationResult, verbose bool) {
 
 		_, _ = fmt.Fprintln(w, line)
 	}
+}
+
+// formatBatchText lists each repo with its status, then the checks that
+// did not pass in most repos. verbose adds each repo's failing and warning
+// checks.
+func formatBatchText(w io.Writer, result *validator.BatchResult, verbose bool) {
+	width := 0
+	for _, r := range result.Repos {
+		width = max(width, len(r.Path))
+	}
+	for _, r := range result.Repos {
+		status := strings.ToUpper(r.Status)
+		detail := ""
+		switch {
+		case r.Error != "":
+			status, detail = "ERROR", r.Error
+		case r.Result.Summary.Fail+r.Result.Summary.Warn > 0:
+			detail = fmt.Sprintf("%d fail, %d warn", r.Result.Summary.Fail, r.Result.Summary.Warn)
+		}
+		line := fmt.Sprintf("  %-7s  %-*s  %s", status, width, r.Path, detail)
+		_, _ = fmt.Fprintln(w, strings.TrimRight(line, " "))
+		if verbose && r.Result != nil {
+			for _, c := range r.Result.AllChecks() {
+				if c.Status != validator.StatusPass {
+					_, _ = fmt.Fprintf(w, "             %s %s  %s\n", checkLabel(c.Name), strings.ToUpper(c.Status), c.Message)
+				}
+			}
+		}
+	}
+
+	if len(result.Checks) > 0 {
+		_, _ = fmt.Fprintln(w)
+		_, _ = fmt.Fprintln(w, "  Checks not passing, by repos affected:")
+		for _, t := range result.Checks {
+			label := checkLabel(t.Name)
+			dots := max(labelWidth-len(label), 3)
+			_, _ = fmt.Fprintf(w, "  %s %s %d fail, %d warn\n", label, strings.Repeat(".", dots), t.Fail, t.Warn)
+		}
+	}
 
+	s := result.Summary
 	_, _ = fmt.Fprintln(w)
-	_, _ = fmt.Fprintf(w, "  Result: %s (%d pass, %d fail, %d warn)\n",
-		strings.ToUpper(result.Status),
-		result.Summary.Pass,
-		result.Summary.Fail,
-		result.Summary.Warn,
-	)
+	_, _ = fmt.Fprintf(w, "  Result: %s (%d repo(s): %d pass, %d partial, %d fail, %d error)\n",
+		strings.ToUpper(result.Status), s.Total, s.Pass, s.Partial, s.Fail, s.Error)
+}
+
+func checkLabel(name string) string {
+	if label := checkLabels[name]; label != "" {
+		return label
+	}
+	return name
 }
 
-func formatJSON(w io.Writer, result *validator.ValidationResult) error {
+func formatJSON(w io.Writer, result any) error {
 	enc := json.NewEncoder(w)
 	enc.SetIndent("", "  ")
 	return enc.Encode(result)
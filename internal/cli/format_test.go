@@ -112,3 +112,106 @@ func TestFormatText_FailMessage(t *testing.T) {
 		t.Error("expected failure message in output")
 	}
 }
+
+func TestFormatText_Release(t *testing.T) {
+	r := sampleResult()
+	buf := new(bytes.Buffer)
+	formatText(buf, r, false)
+	if strings.Contains(buf.String(), "Release:") {
+		t.Error("expected no release line when none was inspected")
+	}
+
+	r.Release = "v1.2.0"
+	buf.Reset()
+	formatText(buf, r, false)
+	if !strings.Contains(buf.String(), "Release: v1.2.0") {
+		t.Errorf("expected the inspected release, got:\n%s", buf.String())
+	}
+}
+
+func TestFormatText_Tools(t *testing.T) {
+	r := &validator.ValidationResult{
+		Path:   "/src/mono",
+		Status: validator.OverallFail,
+		Tools: []validator.ToolResult{
+			{Path: "cmd/a", Status: validator.OverallPass, Checks: []validator.CheckResult{
+				{Name: validator.CheckSkillMDExists, Status: validator.StatusPass, Message: "SKILL.md found in cmd/a"},
+			}},
+			{Path: "cmd/b", Status: validator.OverallFail, Checks: []validator.CheckResult{
+				{Name: validator.CheckSkillMDExists, Status: validator.StatusFail, Message: "SKILL.md not found in cmd/b"},
+			}},
+		},
+		Checks: []validator.CheckResult{
+			{Name: validator.CheckHasBinaryRelease, Status: validator.StatusWarn, Message: "no binary release config found"},
+		},
+		Summary: validator.Summary{Total: 3, Pass: 1, Fail: 1, Warn: 1},
+	}
+	buf := new(bytes.Buffer)
+	formatText(buf, r, false)
+	out := buf.String()
+
+	for _, want := range []string{"  cmd/a: PASS\n", "  cmd/b: FAIL\n    SKILL.md exists", "  Repository:\n    Binary release", "Result: FAIL (1 pass, 1 fail, 1 warn)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+	if strings.Index(out, "cmd/a") > strings.Index(out, "cmd/b") {
+		t.Errorf("tools out of order:\n%s", out)
+	}
+}
+
+func TestToolLabel(t *testing.T) {
+	for _, tt := range []struct {
+		tool validator.ToolResult
+		want string
+	}{
+		{validator.ToolResult{Path: "cmd/a"}, "cmd/a"},
+		{validator.ToolResult{Path: ".", Name: "foo-agent"}, "foo-agent"},
+		{validator.ToolResult{Path: "cmd/a", Name: "foo-agent"}, "cmd/a (foo-agent)"},
+	} {
+		if got := toolLabel(tt.tool); got != tt.want {
+			t.Errorf("toolLabel(%+v) = %q, want %q", tt.tool, got, tt.want)
+		}
+	}
+}
+
+func sampleBatch() *validator.BatchResult {
+	return &validator.BatchResult{
+		Status: validator.OverallFail,
+		Repos: []validator.RepoResult{
+			{Path: "/src/tools/alpha", Status: validator.OverallFail, Result: sampleResult()},
+			{Path: "/src/tools/b", Status: validator.OverallFail, Error: "repository not found"},
+		},
+		Checks: []validator.CheckTally{
+			{Name: validator.CheckSkillMDExitCodes, Fail: 1, Failing: []string{"/src/tools/alpha"}},
+			{Name: validator.CheckHasBinaryRelease, Warn: 1},
+		},
+		Summary: validator.BatchSummary{Total: 2, Fail: 1, Error: 1},
+	}
+}
+
+func TestFormatBatchText(t *testing.T) {
+	buf := new(bytes.Buffer)
+	formatBatchText(buf, sampleBatch(), false)
+	out := buf.String()
+
+	for _, want := range []string{
+		"  FAIL     /src/tools/alpha  1 fail, 1 warn\n",
+		"  ERROR    /src/tools/b      repository not found\n",
+		"  Exit codes documented .............. 1 fail, 0 warn\n",
+		"  Result: FAIL (2 repo(s): 0 pass, 0 partial, 1 fail, 1 error)\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "missing exit codes section") {
+		t.Error("non-verbose output should not list checks per repo")
+	}
+
+	buf.Reset()
+	formatBatchText(buf, sampleBatch(), true)
+	if !strings.Contains(buf.String(), "Exit codes documented FAIL  missing exit codes section") {
+		t.Errorf("verbose output should list failing checks per repo:\n%s", buf.String())
+	}
+}
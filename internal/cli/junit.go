@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ppiankov/ancc/internal/validator"
+)
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Errors   int          `xml:"errors,attr"`
+	Skipped  int          `xml:"skipped,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Errors   int         `xml:"errors,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+	Skipped   *junitProblem `xml:"skipped,omitempty"`
+}
+
+// junitProblem is the body of a failure, error or skipped element.
+type junitProblem struct {
+	Message string `xml:"message,attr,omitempty"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// formatJUnit writes one testsuite per repo and one testcase per check.
+// Failing checks are failures and warnings are skipped, so CI dashboards
+// count them apart; a repo that could not be validated is a suite with a
+// single errored testcase.
+func formatJUnit(w io.Writer, repos []validator.RepoResult) error {
+	doc := junitSuites{Name: "ancc", Suites: []junitSuite{}}
+	for _, r := range repos {
+		suite := junitSuite{Name: r.Path}
+		if r.Error != "" {
+			suite.Cases = append(suite.Cases, junitCase{
+				Name:      "validate",
+				ClassName: r.Path,
+				Error:     &junitProblem{Message: r.Error, Text: r.Error},
+			})
+			suite.Errors++
+		}
+		if r.Result != nil {
+			for _, t := range r.Result.Tools {
+				for _, c := range t.Checks {
+					suite.add(junitCheck(r.Path+"/"+toolLabel(t), c))
+				}
+			}
+			for _, c := range r.Result.Checks {
+				suite.add(junitCheck(r.Path, c))
+			}
+		}
+		suite.Tests = len(suite.Cases)
+
+		doc.Tests += suite.Tests
+		doc.Failures += suite.Failures
+		doc.Errors += suite.Errors
+		doc.Skipped += suite.Skipped
+		doc.Suites = append(doc.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+// add appends a testcase and counts its outcome.
+func (s *junitSuite) add(tc junitCase) {
+	switch {
+	case tc.Failure != nil:
+		s.Failures++
+	case tc.Skipped != nil:
+		s.Skipped++
+	}
+	s.Cases = append(s.Cases, tc)
+}
+
+// junitCheck turns a check into a testcase of class, the repo or tool it
+// belongs to.
+func junitCheck(class string, c validator.CheckResult) junitCase {
+	tc := junitCase{Name: c.Name, ClassName: class}
+	switch c.Status {
+	case validator.StatusFail:
+		tc.Failure = &junitProblem{Message: c.Message, Type: c.Name, Text: problemText(c)}
+	case validator.StatusWarn:
+		tc.Skipped = &junitProblem{Message: c.Message}
+	}
+	return tc
+}
+
+// problemText is the message of a check followed by the places it points
+// at, one per line, as path:line.
+func problemText(c validator.CheckResult) string {
+	lines := []string{c.Message}
+	for _, l := range locationStrings(c.Locations) {
+		lines = append(lines, "at "+l)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// locationStrings renders locations as path or path:line.
+func locationStrings(locs []validator.Location) []string {
+	var out []string
+	for _, l := range locs {
+		if l.Line > 0 {
+			out = append(out, fmt.Sprintf("%s:%d", l.Path, l.Line))
+		} else {
+			out = append(out, l.Path)
+		}
+	}
+	return out
+}
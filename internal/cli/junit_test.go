@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ppiankov/ancc/internal/validator"
+)
+
+func decodeJUnit(t *testing.T, data []byte) junitSuites {
+	t.Helper()
+	var doc junitSuites
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("invalid JUnit XML: %v\nraw: %s", err, data)
+	}
+	return doc
+}
+
+func TestFormatJUnit(t *testing.T) {
+	r := sampleResult()
+	r.Checks[2].Locations = []validator.Location{{Path: "SKILL.md", Line: 12}}
+	buf := new(bytes.Buffer)
+	if err := formatJUnit(buf, []validator.RepoResult{{Path: r.Path, Status: r.Status, Result: r}}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), xml.Header) {
+		t.Errorf("missing XML header: %q", buf.String())
+	}
+	doc := decodeJUnit(t, buf.Bytes())
+	if len(doc.Suites) != 1 {
+		t.Fatalf("got %d suites, want 1", len(doc.Suites))
+	}
+	s := doc.Suites[0]
+	if s.Name != "/tmp/test" || s.Tests != 4 || s.Failures != 1 || s.Skipped != 1 || s.Errors != 0 {
+		t.Errorf("suite = %+v", s)
+	}
+	if doc.Tests != 4 || doc.Failures != 1 || doc.Skipped != 1 {
+		t.Errorf("totals = %d tests, %d failures, %d skipped", doc.Tests, doc.Failures, doc.Skipped)
+	}
+
+	pass, failure, warning := s.Cases[0], s.Cases[2], s.Cases[3]
+	if pass.Name != validator.CheckSkillMDExists || pass.ClassName != "/tmp/test" || pass.Failure != nil || pass.Skipped != nil {
+		t.Errorf("passing case = %+v", pass)
+	}
+	if failure.Failure == nil || failure.Failure.Message != "missing exit codes section" ||
+		failure.Failure.Text != "missing exit codes section\nat SKILL.md:12" {
+		t.Errorf("failing case = %+v", failure.Failure)
+	}
+	if warning.Skipped == nil || warning.Skipped.Message != "skipped" || warning.Failure != nil {
+		t.Errorf("warning case = %+v", warning)
+	}
+}
+
+func TestFormatJUnit_Batch(t *testing.T) {
+	multi := &validator.ValidationResult{
+		Path:   "github.com/acme/foo",
+		Status: validator.OverallPartial,
+		Tools: []validator.ToolResult{{Path: ".", Name: "foo-agent", Checks: []validator.CheckResult{
+			{Name: validator.CheckHasDoctorCommand, Status: validator.StatusWarn, Message: "no doctor command documented (recommended)"},
+		}}},
+		Checks: []validator.CheckResult{
+			{Name: validator.CheckHasBinaryRelease, Status: validator.StatusPass},
+		},
+	}
+	repos := append(sampleBatch().Repos, validator.RepoResult{Path: multi.Path, Status: multi.Status, Result: multi})
+	buf := new(bytes.Buffer)
+	if err := formatJUnit(buf, repos); err != nil {
+		t.Fatal(err)
+	}
+	doc := decodeJUnit(t, buf.Bytes())
+	if len(doc.Suites) != 3 {
+		t.Fatalf("got %d suites, want one per repo", len(doc.Suites))
+	}
+	if doc.Tests != 7 || doc.Errors != 1 {
+		t.Errorf("totals = %d tests, %d errors", doc.Tests, doc.Errors)
+	}
+	errSuite := doc.Suites[1]
+	if errSuite.Errors != 1 || len(errSuite.Cases) != 1 || errSuite.Cases[0].Error == nil ||
+		errSuite.Cases[0].Error.Message != "repository not found" {
+		t.Errorf("error suite = %+v", errSuite)
+	}
+	cases := doc.Suites[2].Cases
+	if cases[0].ClassName != "github.com/acme/foo/foo-agent" || cases[1].ClassName != "github.com/acme/foo" {
+		t.Errorf("classnames = %q, %q", cases[0].ClassName, cases[1].ClassName)
+	}
+}
+
+func TestValidateCmd_JUnit(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte("# tool\n\nDoes things.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := newRootCmd("dev")
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"validate", "--offline", "--format", "junit", dir, repoRoot()})
+
+	err := cmd.Execute()
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 1 {
+		t.Fatalf("expected exit code 1, got %v", err)
+	}
+	doc := decodeJUnit(t, buf.Bytes())
+	if len(doc.Suites) != 2 || doc.Suites[0].Failures == 0 || doc.Suites[1].Failures != 0 {
+		t.Errorf("suites = %+v", doc.Suites)
+	}
+}
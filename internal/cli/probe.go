@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ppiankov/ancc/internal/config"
+	"github.com/ppiankov/ancc/internal/probe"
+	"github.com/ppiankov/ancc/internal/skillmd"
+	"github.com/ppiankov/ancc/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+func newProbeCmd() *cobra.Command {
+	var binary string
+	var format string
+	var verbose bool
+	var timeout time.Duration
+	var runs int
+	var budget time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "probe [path]",
+		Short: "Run a built binary and verify its JSON output against SKILL.md",
+		Long: `Run each documented command that is safe to execute and check its
+--format json output against SKILL.md, then feed it an unknown flag,
+an invalid --format value and (when the heading shows a <required>
+argument) a missing argument, and check that it fails cleanly.
+
+A command is safe when SKILL.md marks it with "**Read-only:** yes" or it is
+listed under probe.allow in .ancc.yml. Each run happens in a temp dir with
+a scrubbed environment and a timeout.
+
+Every probed command is also checked for bounded execution: it must finish
+within --budget and leave no child processes running. With --runs N (two or
+more) each command runs N times in identical environments and any JSON field
+that changes between runs is reported, unless SKILL.md lists it under
+"**Volatile fields:**".
+
+When SKILL.md documents several tools under "## Tool: <name>" headings,
+the tool named like the binary is probed.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if timeout <= 0 {
+				return fmt.Errorf("--timeout must be positive")
+			}
+			if runs < 1 {
+				return fmt.Errorf("--runs must be at least 1")
+			}
+			path := "."
+			if len(args) > 0 {
+				path = args[0]
+			}
+			path, err := filepath.Abs(path)
+			if err != nil {
+				return fmt.Errorf("resolving path: %w", err)
+			}
+
+			bin, err := resolveBinary(binary)
+			if err != nil {
+				return err
+			}
+
+			sf, err := skillmd.ParseFile(filepath.Join(path, "SKILL.md"))
+			if err != nil {
+				return fmt.Errorf("parsing SKILL.md: %w", err)
+			}
+			// A SKILL.md documenting several tools is probed for the one
+			// the binary is.
+			if len(sf.Tools) > 0 {
+				name := strings.TrimSuffix(filepath.Base(bin), ".exe")
+				tool := sf.Tool(name)
+				if tool == nil {
+					return fmt.Errorf("SKILL.md documents no tool named %q", name)
+				}
+				sf = tool
+			}
+			cfg, err := config.Load(path)
+			if err != nil {
+				return err
+			}
+
+			opts := probe.Options{
+				Binary:  bin,
+				Timeout: cfg.Probe.Timeout,
+				Allow:   cfg.Probe.Allow,
+				Args:    cfg.Probe.Args,
+				Env:     cfg.Probe.Env,
+				Runs:    cfg.Probe.Runs,
+				Budget:  cfg.Probe.Budget,
+			}
+			if cmd.Flags().Changed("timeout") {
+				opts.Timeout = timeout
+			}
+			if cmd.Flags().Changed("runs") {
+				opts.Runs = runs
+			}
+			if cmd.Flags().Changed("budget") {
+				opts.Budget = budget
+			}
+
+			result := validator.NewResult(path, probe.Run(sf, opts))
+			return writeResult(cmd.OutOrStdout(), result, format, verbose, cmd.Root().Version)
+		},
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	cmd.Flags().StringVar(&binary, "binary", "", "path to the built tool binary (required)")
+	cmd.Flags().StringVar(&format, "format", "text", "output format (text, json, sarif, junit, tap)")
+	cmd.Flags().BoolVar(&verbose, "verbose", false, "show all checks including passing")
+	cmd.Flags().DurationVar(&timeout, "timeout", config.DefaultProbeTimeout, "per-command timeout")
+	cmd.Flags().IntVar(&runs, "runs", 1, "runs per command; 2 or more check output determinism")
+	cmd.Flags().DurationVar(&budget, "budget", 0, "wall-clock budget per command run (0 disables)")
+	_ = cmd.MarkFlagRequired("binary")
+
+	return cmd
+}
+
+// resolveBinary makes a binary path absolute, since probes run in a temp
+// dir. Bare names are looked up on PATH.
+func resolveBinary(binary string) (string, error) {
+	if !strings.ContainsRune(binary, filepath.Separator) && !strings.ContainsRune(binary, '/') {
+		p, err := exec.LookPath(binary)
+		if err != nil {
+			return "", fmt.Errorf("binary %q not found: %w", binary, err)
+		}
+		return p, nil
+	}
+	p, err := filepath.Abs(binary)
+	if err != nil {
+		return "", fmt.Errorf("resolving binary: %w", err)
+	}
+	if _, err := os.Stat(p); err != nil {
+		return "", fmt.Errorf("binary not found: %w", err)
+	}
+	return p, nil
+}
@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProbeCmd_Help(t *testing.T) {
+	cmd := newRootCmd("dev")
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"probe", "--help"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := buf.String()
+	for _, flag := range []string{"--binary", "--timeout", "--format", "--verbose"} {
+		if !strings.Contains(got, flag) {
+			t.Errorf("expected %s in help output", flag)
+		}
+	}
+}
+
+func TestProbeCmd_RequiresBinary(t *testing.T) {
+	cmd := newRootCmd("dev")
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetArgs([]string{"probe", repoRoot()})
+
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected error without --binary")
+	}
+}
+
+func TestProbeCmd_MissingBinary(t *testing.T) {
+	cmd := newRootCmd("dev")
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetArgs([]string{"probe", "--binary", "./does/not/exist", repoRoot()})
+
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "binary not found") {
+		t.Fatalf("expected binary not found error, got %v", err)
+	}
+}
+
+func TestProbeCmd_InvalidFlags(t *testing.T) {
+	for _, args := range [][]string{
+		{"--timeout", "0s"},
+		{"--timeout", "-1s"},
+		{"--runs", "0"},
+	} {
+		cmd := newRootCmd("dev")
+		cmd.SetOut(new(bytes.Buffer))
+		cmd.SetArgs(append([]string{"probe", "--binary", os.Args[0]}, append(args, repoRoot())...))
+		err := cmd.Execute()
+		if err == nil || !strings.Contains(err.Error(), args[0]) {
+			t.Errorf("%v: err = %v, want an error about %s", args, err, args[0])
+		}
+	}
+}
+
+func TestProbeCmd_MultiTool(t *testing.T) {
+	dir := t.TempDir()
+	data, err := os.ReadFile(filepath.Join(repoRoot(), "testdata", "multi-tool-skill.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "SKILL.md"), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"foo-migrate", "bar"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// foo-migrate documents no read-only command, so nothing runs.
+	cmd := newRootCmd("dev")
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"probe", "--binary", filepath.Join(dir, "foo-migrate"), dir})
+	var exitErr *ExitError
+	if err := cmd.Execute(); err != nil && !errors.As(err, &exitErr) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "no command is both") {
+		t.Errorf("expected the foo-migrate commands to be considered, got:\n%s", buf.String())
+	}
+
+	cmd = newRootCmd("dev")
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetArgs([]string{"probe", "--binary", filepath.Join(dir, "bar"), dir})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), `no tool named "bar"`) {
+		t.Fatalf("expected an error for an undocumented tool, got %v", err)
+	}
+}
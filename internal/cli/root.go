@@ -14,6 +14,8 @@ func newRootCmd(version string) *cobra.Command {
 
 	cmd.AddCommand(newValidateCmd())
 	cmd.AddCommand(newInitCmd())
+	cmd.AddCommand(newProbeCmd())
+	cmd.AddCommand(newScanCmd())
 
 	return cmd
 }
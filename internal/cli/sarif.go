@@ -0,0 +1,303 @@
+package cli
+
+import (
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/ppiankov/ancc/internal/probe"
+	"github.com/ppiankov/ancc/internal/validator"
+)
+
+// SARIF 2.1.0, as read by GitHub code scanning and IDE viewers.
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifBaseID  = "SRCROOT"
+	anccURI      = "https://github.com/ppiankov/ancc"
+)
+
+// checkRules describes each check for SARIF rules: what it validates and
+// how to fix a result.
+var checkRules = map[string]struct{ desc, help string }{
+	validator.CheckSkillMDExists: {
+		"SKILL.md is present at the repo root and readable as markdown.",
+		"Add a SKILL.md describing the tool to agents; `ancc init` writes a template. It must be UTF-8 text within --max-skill-size.",
+	},
+	validator.CheckSkillMDInstall: {
+		"SKILL.md documents how to install the tool.",
+		"Add a `## Install` section with the install command, e.g. a package manager or a release download.",
+	},
+	validator.CheckSkillMDCommands: {
+		"SKILL.md has a Commands section documenting the subcommands.",
+		"Add a `## Commands` section with one `### <tool> <command>` heading per command.",
+	},
+	validator.CheckSkillMDFlags: {
+		"At least one command documents a `--format json` flag.",
+		"List the flags of each command under `**Flags:**`, including `` `--format json` `` for machine-readable output.",
+	},
+	validator.CheckSkillMDJSON: {
+		"At least one command shows the shape of its JSON output.",
+		"Add a `**JSON output:**` label followed by a fenced json block with an example or JSON Schema.",
+	},
+	validator.CheckSkillMDExitCodes: {
+		"At least one command documents its exit codes.",
+		"Add an `**Exit codes:**` label followed by list items such as `- 0: success`.",
+	},
+	validator.CheckSkillMDNotDo: {
+		"SKILL.md states what the tool does not do.",
+		"Add a `## What this does NOT do` section listing what agents should not expect of the tool.",
+	},
+	validator.CheckSkillMDParsing: {
+		"SKILL.md shows how to parse the tool's output.",
+		"Add a `## Parsing examples` section, e.g. a `jq` pipeline over `--format json` output.",
+	},
+	validator.CheckHasInitCommand: {
+		"An init command is documented.",
+		"Document a `### <tool> init` command that sets the tool up non-interactively.",
+	},
+	validator.CheckHasDoctorCommand: {
+		"A doctor command is documented (recommended).",
+		"Document a `### <tool> doctor` command that reports the tool's health and dependencies.",
+	},
+	validator.CheckHasBinaryRelease: {
+		"Binary releases cover the minimum platform matrix, or a release pipeline is configured.",
+		"Publish binaries for every platform in --platforms, e.g. with GoReleaser or cargo-dist.",
+	},
+	validator.CheckCommandDrift: {
+		"The commands and flags in SKILL.md match those the source implements.",
+		"Document every implemented command and flag, and remove those the source no longer implements.",
+	},
+	validator.CheckInstallAssets: {
+		"Release download URLs in the Install section resolve to published assets.",
+		"Fix the download URLs in `## Install`, or publish the assets they name.",
+	},
+	validator.CheckReleaseChecksums: {
+		"A checksum file lists every binary asset of the release.",
+		"Publish a checksums.txt or SHA256SUMS covering every binary.",
+	},
+	validator.CheckReleaseSignatures: {
+		"The checksum file or every binary of the release is signed.",
+		"Sign the checksum file, e.g. with cosign or minisign, and publish the signature.",
+	},
+	validator.CheckReleaseSBOM: {
+		"The release ships an SPDX or CycloneDX SBOM.",
+		"Generate an SBOM in the release pipeline, e.g. with syft, and publish it.",
+	},
+	validator.CheckReleaseProvenance: {
+		"The release has SLSA provenance or a GitHub artifact attestation.",
+		"Attest the release binaries, e.g. with actions/attest-build-provenance.",
+	},
+	probe.CheckJSONOutput: {
+		"Safe commands emit one JSON document matching the documented JSON output.",
+		"Make `--format json` print only JSON to stdout, shaped like the `**JSON output:**` block.",
+	},
+	probe.CheckErrorPath: {
+		"Bad invocations exit with the documented usage code and report on stderr.",
+		"Exit non-zero with the documented code on usage errors and keep stdout free of non-JSON output.",
+	},
+	probe.CheckBounded: {
+		"Commands finish within the budget and leave no child processes running.",
+		"Bound the command's run time and wait for or kill the processes it starts.",
+	},
+	probe.CheckDeterminism: {
+		"Repeated runs produce the same JSON output.",
+		"Remove nondeterminism from the output, or list changing fields under `**Volatile fields:**`.",
+	},
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                   `json:"tool"`
+	OriginalURIBaseIDs map[string]sarifArtifactLoc `json:"originalUriBaseIds,omitempty"`
+	Invocations        []sarifInvocation           `json:"invocations,omitempty"`
+	Results            []sarifResult               `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name,omitempty"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+	FullDescription  sarifMessage `json:"fullDescription"`
+	Help             sarifMessage `json:"help"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful bool                `json:"executionSuccessful"`
+	Notifications       []sarifNotification `json:"toolExecutionNotifications,omitempty"`
+}
+
+type sarifNotification struct {
+	Level   string       `json:"level"`
+	Message sarifMessage `json:"message"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLoc `json:"physicalLocation"`
+}
+
+type sarifPhysicalLoc struct {
+	ArtifactLocation sarifArtifactLoc `json:"artifactLocation"`
+	Region           *sarifRegion     `json:"region,omitempty"`
+}
+
+type sarifArtifactLoc struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// sarifLevels maps check statuses to SARIF result levels.
+var sarifLevels = map[string]string{
+	validator.StatusFail: "error",
+	validator.StatusWarn: "warning",
+	validator.StatusPass: "note",
+}
+
+// formatSARIF writes one SARIF run per repo. Failing and warning checks are
+// results located in SKILL.md or, for drift, the source; verbose adds
+// passing checks as notes. A repo that could not be validated is a run
+// whose invocation failed.
+func formatSARIF(w io.Writer, version string, repos []validator.RepoResult, verbose bool) error {
+	log := sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{}}
+	for _, r := range repos {
+		run := sarifRun{Tool: sarifTool{Driver: sarifDriver{
+			Name:           "ancc",
+			Version:        version,
+			InformationURI: anccURI,
+		}}, Results: []sarifResult{}}
+		if fi, err := os.Stat(r.Path); err == nil && fi.IsDir() {
+			if abs, err := filepath.Abs(r.Path); err == nil {
+				root := url.URL{Scheme: "file", Path: filepath.ToSlash(abs) + "/"}
+				run.OriginalURIBaseIDs = map[string]sarifArtifactLoc{sarifBaseID: {URI: root.String()}}
+			}
+		}
+		if r.Error != "" {
+			run.Invocations = []sarifInvocation{{Notifications: []sarifNotification{
+				{Level: "error", Message: sarifMessage{Text: r.Error}},
+			}}}
+		}
+
+		var checks []validator.CheckResult
+		var prefixes []string
+		if r.Result != nil {
+			for _, t := range r.Result.Tools {
+				for _, c := range t.Checks {
+					checks = append(checks, c)
+					prefixes = append(prefixes, toolPrefix(t))
+				}
+			}
+			for _, c := range r.Result.Checks {
+				checks = append(checks, c)
+				prefixes = append(prefixes, "")
+			}
+		}
+
+		run.Tool.Driver.Rules = sarifRules(checks)
+		for i, c := range checks {
+			if c.Status == validator.StatusPass && !verbose {
+				continue
+			}
+			res := sarifResult{
+				RuleID:    c.Name,
+				RuleIndex: slices.IndexFunc(run.Tool.Driver.Rules, func(rule sarifRule) bool { return rule.ID == c.Name }),
+				Level:     sarifLevels[c.Status],
+				Message:   sarifMessage{Text: prefixes[i] + sarifText(c)},
+			}
+			for _, l := range c.Locations {
+				loc := sarifLocation{PhysicalLocation: sarifPhysicalLoc{
+					ArtifactLocation: sarifArtifactLoc{URI: (&url.URL{Path: l.Path}).String(), URIBaseID: sarifBaseID},
+				}}
+				if l.Line > 0 {
+					loc.PhysicalLocation.Region = &sarifRegion{StartLine: l.Line}
+				}
+				res.Locations = append(res.Locations, loc)
+			}
+			run.Results = append(run.Results, res)
+		}
+		log.Runs = append(log.Runs, run)
+	}
+	return formatJSON(w, log)
+}
+
+// sarifRules lists a rule for every known check, in name order, followed by
+// any other check among checks.
+func sarifRules(checks []validator.CheckResult) []sarifRule {
+	names := make([]string, 0, len(checkRules))
+	for name := range checkRules {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, c := range checks {
+		if _, ok := checkRules[c.Name]; !ok && !slices.Contains(names, c.Name) {
+			names = append(names, c.Name)
+		}
+	}
+
+	rules := make([]sarifRule, 0, len(names))
+	for _, name := range names {
+		info, ok := checkRules[name]
+		if !ok {
+			info.desc, info.help = checkLabel(name), checkLabel(name)
+		}
+		rules = append(rules, sarifRule{
+			ID:               name,
+			Name:             checkLabel(name),
+			ShortDescription: sarifMessage{Text: checkLabel(name)},
+			FullDescription:  sarifMessage{Text: info.desc},
+			Help:             sarifMessage{Text: info.help},
+		})
+	}
+	return rules
+}
+
+// toolPrefix names the tool of a multi-tool SKILL.md in its results, whose
+// locations share one file.
+func toolPrefix(t validator.ToolResult) string {
+	if t.Name == "" {
+		return ""
+	}
+	return toolLabel(t) + ": "
+}
+
+// sarifText is the message of a result, which SARIF requires to be
+// non-empty.
+func sarifText(c validator.CheckResult) string {
+	if c.Message != "" {
+		return c.Message
+	}
+	return checkLabel(c.Name)
+}
@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ppiankov/ancc/internal/validator"
+)
+
+func decodeSARIF(t *testing.T, data []byte) sarifLog {
+	t.Helper()
+	var log sarifLog
+	if err := json.Unmarshal(data, &log); err != nil {
+		t.Fatalf("invalid SARIF: %v\nraw: %s", err, data)
+	}
+	if log.Version != sarifVersion || log.Schema != sarifSchema {
+		t.Errorf("version %q, schema %q", log.Version, log.Schema)
+	}
+	return log
+}
+
+func TestFormatSARIF(t *testing.T) {
+	r := sampleResult()
+	r.Checks[2].Locations = []validator.Location{{Path: "SKILL.md", Line: 12}}
+	r.Checks[3].Locations = []validator.Location{{Path: "cmd/my tool/SKILL.md"}}
+	buf := new(bytes.Buffer)
+	repos := []validator.RepoResult{{Path: r.Path, Status: r.Status, Result: r}}
+	if err := formatSARIF(buf, "1.2.3", repos, false); err != nil {
+		t.Fatal(err)
+	}
+	log := decodeSARIF(t, buf.Bytes())
+	if len(log.Runs) != 1 {
+		t.Fatalf("got %d runs, want 1", len(log.Runs))
+	}
+	run := log.Runs[0]
+	if run.Tool.Driver.Name != "ancc" || run.Tool.Driver.Version != "1.2.3" {
+		t.Errorf("driver = %+v", run.Tool.Driver)
+	}
+	if len(run.Tool.Driver.Rules) != len(checkRules) {
+		t.Errorf("got %d rules, want one per check (%d)", len(run.Tool.Driver.Rules), len(checkRules))
+	}
+	for _, rule := range run.Tool.Driver.Rules {
+		if rule.FullDescription.Text == "" || rule.Help.Text == "" {
+			t.Errorf("rule %s lacks a description or help", rule.ID)
+		}
+	}
+
+	// Passing checks are left out.
+	if len(run.Results) != 2 {
+		t.Fatalf("got %d results, want the failure and the warning", len(run.Results))
+	}
+	failure, warning := run.Results[0], run.Results[1]
+	if failure.RuleID != validator.CheckSkillMDExitCodes || failure.Level != "error" || failure.Message.Text != "missing exit codes section" {
+		t.Errorf("failure = %+v", failure)
+	}
+	if rule := run.Tool.Driver.Rules[failure.RuleIndex]; rule.ID != failure.RuleID {
+		t.Errorf("ruleIndex %d names %s, want %s", failure.RuleIndex, rule.ID, failure.RuleID)
+	}
+	loc := failure.Locations[0].PhysicalLocation
+	if loc.ArtifactLocation.URI != "SKILL.md" || loc.ArtifactLocation.URIBaseID != sarifBaseID || loc.Region == nil || loc.Region.StartLine != 12 {
+		t.Errorf("failure location = %+v", loc)
+	}
+	if warning.Level != "warning" {
+		t.Errorf("warning level = %q", warning.Level)
+	}
+	loc = warning.Locations[0].PhysicalLocation
+	if loc.ArtifactLocation.URI != "cmd/my%20tool/SKILL.md" || loc.Region != nil {
+		t.Errorf("whole-file location = %+v", loc)
+	}
+
+	buf.Reset()
+	if err := formatSARIF(buf, "1.2.3", repos, true); err != nil {
+		t.Fatal(err)
+	}
+	results := decodeSARIF(t, buf.Bytes()).Runs[0].Results
+	if len(results) != 4 || results[0].Level != "note" {
+		t.Errorf("verbose results = %+v, want passing checks as notes", results)
+	}
+}
+
+func TestFormatSARIF_Batch(t *testing.T) {
+	multi := &validator.ValidationResult{
+		Path:   "github.com/acme/foo",
+		Status: validator.OverallPartial,
+		Tools: []validator.ToolResult{{Path: ".", Name: "foo-agent", Checks: []validator.CheckResult{
+			{Name: validator.CheckHasDoctorCommand, Status: validator.StatusWarn, Message: "no doctor command documented (recommended)"},
+		}}},
+	}
+	repos := append(sampleBatch().Repos, validator.RepoResult{Path: multi.Path, Status: multi.Status, Result: multi})
+	buf := new(bytes.Buffer)
+	if err := formatSARIF(buf, "dev", repos, false); err != nil {
+		t.Fatal(err)
+	}
+	log := decodeSARIF(t, buf.Bytes())
+	if len(log.Runs) != 3 {
+		t.Fatalf("got %d runs, want one per repo", len(log.Runs))
+	}
+	errRun := log.Runs[1]
+	if len(errRun.Invocations) != 1 || errRun.Invocations[0].ExecutionSuccessful || errRun.Invocations[0].Notifications[0].Message.Text != "repository not found" {
+		t.Errorf("error repo invocations = %+v", errRun.Invocations)
+	}
+	if got := log.Runs[2].Results[0].Message.Text; got != "foo-agent: no doctor command documented (recommended)" {
+		t.Errorf("tool result message = %q", got)
+	}
+	if log.Runs[2].OriginalURIBaseIDs != nil {
+		t.Errorf("remote repo has a local base: %+v", log.Runs[2].OriginalURIBaseIDs)
+	}
+}
+
+func TestValidateCmd_SARIF(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte("# tool\n\nDoes things.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := newRootCmd("1.0.0")
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"validate", "--offline", "--format", "sarif", dir})
+
+	err := cmd.Execute()
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 1 {
+		t.Fatalf("expected exit code 1, got %v", err)
+	}
+	run := decodeSARIF(t, buf.Bytes()).Runs[0]
+	if base := run.OriginalURIBaseIDs[sarifBaseID].URI; !strings.HasPrefix(base, "file://") || !strings.HasSuffix(base, "/") {
+		t.Errorf("base URI = %q", base)
+	}
+	var install *sarifResult
+	for i, r := range run.Results {
+		if r.RuleID == validator.CheckSkillMDInstall {
+			install = &run.Results[i]
+		}
+	}
+	if install == nil || install.Locations[0].PhysicalLocation.Region.StartLine != 1 {
+		t.Errorf("install result = %+v, want one at the H1 heading", install)
+	}
+}
+
+func TestValidateCmd_InvalidFormat(t *testing.T) {
+	cmd := newRootCmd("dev")
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetArgs([]string{"validate", "--format", "xml", repoRoot()})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "--format must be") {
+		t.Fatalf("expected a format error, got %v", err)
+	}
+}
+
+func TestValidateCmd_SARIFEveryResultLocated(t *testing.T) {
+	// Neither repo has a release config; one lacks SKILL.md altogether.
+	missing := t.TempDir()
+	bare := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bare, "SKILL.md"), []byte("# tool\n\nDoes things.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := newRootCmd("dev")
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"validate", "--offline", "--verbose", "--format", "sarif", missing, bare})
+	_ = cmd.Execute()
+
+	log := decodeSARIF(t, buf.Bytes())
+	if len(log.Runs) != 2 {
+		t.Fatalf("got %d runs, want 2", len(log.Runs))
+	}
+	for _, run := range log.Runs {
+		if len(run.Results) == 0 {
+			t.Error("run has no results")
+		}
+		for _, r := range run.Results {
+			if len(r.Locations) == 0 || r.Locations[0].PhysicalLocation.ArtifactLocation.URI == "" {
+				t.Errorf("%s %s has no location", r.RuleID, r.Level)
+			}
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ppiankov/ancc/internal/config"
+	"github.com/ppiankov/ancc/internal/httpclient"
+	"github.com/ppiankov/ancc/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+// defaultScanWait is how long a scan waits for the rate limit to reset by
+// default: long enough for GitHub's hourly window to come round.
+const defaultScanWait = time.Hour
+
+func newScanCmd() *cobra.Command {
+	var format string
+	var includeArchived, includeForks bool
+	var topics []string
+	var jobs int
+	var maxWait time.Duration
+	var githubAPIURL string
+	var githubHosts []string
+	var timeout time.Duration
+	var noCache bool
+	var record, replay string
+
+	cmd := &cobra.Command{
+		Use:   "scan github.com/<owner>",
+		Short: "Validate every repo of a GitHub organization or user",
+		Long: `List the repositories of a GitHub organization, or of a user, and
+validate each one as ancc validate would, then report them ranked by
+compliance score: passing checks count fully, warnings half. Archived repos
+and forks are skipped unless --include-archived or --include-forks is
+given; --topic keeps only repos with one of the given topics.
+
+Up to --jobs repos are validated at once. The scan paces itself by the
+rate limit GitHub reports: when fewer requests remain than the running
+validations may need, it waits for the reset, for at most --max-wait;
+repos that would wait longer are reported as errors.
+
+GitHub Enterprise organizations are scanned as ghe.example.com/<owner>
+with --github-api-url or --github-host, as for ancc validate.
+
+The report is text, json or markdown (--format). The exit code follows the
+worst repo, as for ancc validate.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if jobs < 1 {
+				return fmt.Errorf("--jobs must be at least 1")
+			}
+			switch format {
+			case "text", "json", "markdown":
+			default:
+				return fmt.Errorf("--format must be text, json or markdown")
+			}
+			cfg, err := config.Load(".")
+			if err != nil {
+				return err
+			}
+			opts := validator.ScanOptions{
+				IncludeArchived: includeArchived,
+				IncludeForks:    includeForks,
+				Topics:          topics,
+				Jobs:            jobs,
+				MaxWait:         maxWait,
+			}
+			opts.Forges = validator.Forges{GitHubAPIURL: githubAPIURL, GitHubHosts: slices.Concat(githubHosts, cfg.GitHub.Hosts)}
+			if opts.Forges.GitHubAPIURL == "" {
+				opts.Forges.GitHubAPIURL = os.Getenv("GITHUB_API_URL")
+			}
+			if opts.Forges.GitHubAPIURL == "" {
+				opts.Forges.GitHubAPIURL = cfg.GitHub.APIURL
+			}
+			opts.Forges.HTTPClient, err = newHTTPClient(httpclient.Options{
+				Timeout:   timeout,
+				RecordDir: record,
+				ReplayDir: replay,
+			}, noCache)
+			if err != nil {
+				return err
+			}
+
+			report, err := validator.Scan(args[0], opts)
+			if err != nil {
+				return fmt.Errorf("scan error: %w", err)
+			}
+			return writeScanReport(cmd.OutOrStdout(), report, format)
+		},
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	cmd.Flags().StringVar(&format, "format", "text", "output format (text, json, markdown)")
+	cmd.Flags().BoolVar(&includeArchived, "include-archived", false, "scan archived repos too")
+	cmd.Flags().BoolVar(&includeForks, "include-forks", false, "scan forks too")
+	cmd.Flags().StringSliceVar(&topics, "topic", nil, "scan only repos with one of these topics")
+	cmd.Flags().IntVar(&jobs, "jobs", defaultJobs, "repos validated at once")
+	cmd.Flags().DurationVar(&maxWait, "max-wait", defaultScanWait, "longest wait for the rate limit to reset")
+	cmd.Flags().StringVar(&githubAPIURL, "github-api-url", "", "API base of GitHub Enterprise hosts, e.g. https://ghe.example.com/api/v3 (default $GITHUB_API_URL)")
+	cmd.Flags().StringSliceVar(&githubHosts, "github-host", nil, "GitHub Enterprise hosts to recognize in URLs")
+	cmd.Flags().DurationVar(&timeout, "timeout", httpclient.DefaultTimeout, "time limit for each GitHub API request")
+	cmd.Flags().BoolVar(&noCache, "no-cache", false, "do not cache GitHub API responses on disk")
+	cmd.Flags().StringVar(&record, "record", "", "record GitHub HTTP interactions to a cassette directory, credentials scrubbed")
+	cmd.Flags().StringVar(&replay, "replay", "", "serve GitHub HTTP interactions from a cassette directory without network access")
+
+	return cmd
+}
+
+// writeScanReport renders a scan report and maps the worst repo status to
+// the exit code.
+func writeScanReport(w io.Writer, report *validator.ScanReport, format string) error {
+	switch format {
+	case "json":
+		if err := formatJSON(w, report); err != nil {
+			return fmt.Errorf("formatting output: %w", err)
+		}
+	case "markdown":
+		formatScanMarkdown(w, report)
+	default:
+		formatScanText(w, report)
+	}
+	return statusExit(report.Status)
+}
+
+func formatScanText(w io.Writer, report *validator.ScanReport) {
+	width := 0
+	for _, e := range report.Repos {
+		width = max(width, len(e.Repo))
+	}
+	for i, e := range report.Repos {
+		status := strings.ToUpper(e.Status)
+		if e.Error != "" {
+			status = "ERROR"
+		}
+		line := fmt.Sprintf("  %3d. %-*s  %3d  %-7s  %s", i+1, width, e.Repo, e.Score, status, e.Error)
+		_, _ = fmt.Fprintln(w, strings.TrimRight(line, " "))
+	}
+
+	if len(report.Checks) > 0 {
+		_, _ = fmt.Fprintln(w)
+		_, _ = fmt.Fprintln(w, "  Checks not passing, by repos affected:")
+		for _, t := range report.Checks {
+			label := checkLabel(t.Name)
+			dots := max(labelWidth-len(label), 3)
+			_, _ = fmt.Fprintf(w, "  %s %s %d fail, %d warn\n", label, strings.Repeat(".", dots), t.Fail, t.Warn)
+		}
+	}
+
+	s := report.Summary
+	_, _ = fmt.Fprintln(w)
+	_, _ = fmt.Fprintf(w, "  Adoption: %d of %d scanned repo(s) have a SKILL.md; mean score %d\n", s.Adopted, s.Scanned, s.Score)
+	_, _ = fmt.Fprintf(w, "  Skipped: %d archived, %d fork(s), %d off topic, of %d listed\n", s.Archived, s.Forks, s.OffTopic, s.Listed)
+	_, _ = fmt.Fprintf(w, "  Result: %s (%d pass, %d partial, %d fail, %d error)\n",
+		strings.ToUpper(report.Status), s.Pass, s.Partial, s.Fail, s.Error)
+}
+
+// formatScanMarkdown renders the report as a Markdown document for a wiki
+// page or an issue.
+func formatScanMarkdown(w io.Writer, report *validator.ScanReport) {
+	s := report.Summary
+	_, _ = fmt.Fprintf(w, "# ANCC compliance: %s/%s\n\n", report.Host, report.Owner)
+	_, _ = fmt.Fprintf(w, "%d of %d scanned repositories have a SKILL.md. Mean score: %d.\n\n", s.Adopted, s.Scanned, s.Score)
+	_, _ = fmt.Fprintf(w, "| Result | Repos |\n|---|---:|\n| pass | %d |\n| partial | %d |\n| fail | %d |\n| error | %d |\n| skipped | %d |\n\n",
+		s.Pass, s.Partial, s.Fail, s.Error, s.Archived+s.Forks+s.OffTopic)
+
+	_, _ = fmt.Fprintln(w, "## Repositories")
+	_, _ = fmt.Fprintln(w)
+	_, _ = fmt.Fprintln(w, "| # | Repository | Score | Status | Failing checks |")
+	_, _ = fmt.Fprintln(w, "|---:|---|---:|---|---|")
+	for i, e := range report.Repos {
+		repo := markdownEscape(e.Repo)
+		if e.URL != "" {
+			repo = fmt.Sprintf("[%s](%s)", repo, e.URL)
+		}
+		status, detail := e.Status, ""
+		if e.Error != "" {
+			status, detail = "error", markdownEscape(e.Error)
+		} else if e.Result != nil {
+			var failing []string
+			for _, c := range e.Result.AllChecks() {
+				if c.Status == validator.StatusFail {
+					failing = append(failing, "`"+c.Name+"`")
+				}
+			}
+			detail = strings.Join(failing, ", ")
+		}
+		_, _ = fmt.Fprintf(w, "| %d | %s | %d | %s | %s |\n", i+1, repo, e.Score, status, detail)
+	}
+
+	if len(report.Checks) > 0 {
+		_, _ = fmt.Fprintln(w)
+		_, _ = fmt.Fprintln(w, "## Checks not passing")
+		_, _ = fmt.Fprintln(w)
+		_, _ = fmt.Fprintln(w, "| Check | Failing repos | Warning repos |")
+		_, _ = fmt.Fprintln(w, "|---|---:|---:|")
+		for _, t := range report.Checks {
+			_, _ = fmt.Fprintf(w, "| %s (`%s`) | %d | %d |\n", checkLabel(t.Name), t.Name, t.Fail, t.Warn)
+		}
+	}
+}
+
+// markdownEscape keeps text from breaking a table row.
+func markdownEscape(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
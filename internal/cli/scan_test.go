@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ppiankov/ancc/internal/validator"
+)
+
+// fakeGitHub serves the acme organization: "tool" with the project's
+// SKILL.md and "bare" without one.
+func fakeGitHub(t *testing.T) *httptest.Server {
+	t.Helper()
+	skill, err := os.ReadFile(filepath.Join(repoRoot(), "SKILL.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive := func(files map[string]string) []byte {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		for name, data := range files {
+			w, err := zw.Create("acme-repo-0123456/" + name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, _ = w.Write([]byte(data))
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/orgs/acme/repos", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[
+			{"name": "bare", "full_name": "acme/bare", "html_url": "https://github.com/acme/bare"},
+			{"name": "tool", "full_name": "acme/tool", "html_url": "https://github.com/acme/tool"},
+			{"name": "old", "full_name": "acme/old", "archived": true}
+		]`))
+	})
+	mux.HandleFunc("/repos/acme/", func(w http.ResponseWriter, r *http.Request) {
+		name, rest, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/repos/acme/"), "/")
+		switch {
+		case strings.HasPrefix(rest, "commits/"):
+			_, _ = w.Write([]byte("0123456789abcdef0123456789abcdef01234567"))
+		case strings.HasPrefix(rest, "zipball/") && name == "tool":
+			_, _ = w.Write(archive(map[string]string{"SKILL.md": string(skill)}))
+		case strings.HasPrefix(rest, "zipball/"):
+			_, _ = w.Write(archive(map[string]string{"README.md": "# bare"}))
+		case rest == "releases":
+			_, _ = w.Write([]byte("[]"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	return httptest.NewServer(mux)
+}
+
+func runScan(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	srv := fakeGitHub(t)
+	t.Cleanup(srv.Close)
+	cmd := newRootCmd("dev")
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetArgs(append([]string{"scan", "--no-cache", "--github-api-url", srv.URL, "127.0.0.1/acme"}, args...))
+	err := cmd.Execute()
+	return buf.String(), err
+}
+
+func TestScanCmd_JSON(t *testing.T) {
+	out, err := runScan(t, "--format", "json")
+
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 1 {
+		t.Fatalf("expected exit code 1 from the repo without SKILL.md, got %v", err)
+	}
+	var report validator.ScanReport
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("invalid JSON output: %v\nraw: %s", err, out)
+	}
+	if len(report.Repos) != 2 || report.Repos[0].Repo != "acme/tool" || report.Repos[1].Repo != "acme/bare" {
+		t.Fatalf("repos = %+v, want tool ranked above bare", report.Repos)
+	}
+	if report.Summary.Archived != 1 || report.Summary.Adopted != 1 {
+		t.Errorf("summary = %+v", report.Summary)
+	}
+}
+
+func TestScanCmd_Markdown(t *testing.T) {
+	out, _ := runScan(t, "--format", "markdown")
+
+	for _, want := range []string{
+		"# ANCC compliance: 127.0.0.1/acme\n",
+		"1 of 2 scanned repositories have a SKILL.md.",
+		"| 1 | [acme/tool](https://github.com/acme/tool) |",
+		"| 2 | [acme/bare](https://github.com/acme/bare) | ",
+		"| fail | `skill-md-exists`, `skill-md-install`,",
+		"| SKILL.md exists (`skill-md-exists`) | 1 | 0 |",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("markdown missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestScanCmd_Text(t *testing.T) {
+	out, _ := runScan(t, "--topic", "none")
+
+	if !strings.Contains(out, "Skipped: 1 archived, 0 fork(s), 2 off topic, of 3 listed") {
+		t.Errorf("output = %q", out)
+	}
+}
+
+func TestScanCmd_Errors(t *testing.T) {
+	for _, args := range [][]string{
+		{"scan", "gitlab.com/acme"},
+		{"scan", "github.com/acme/tool"},
+		{"scan", "--format", "html", "github.com/acme"},
+		{"scan", "--jobs", "0", "github.com/acme"},
+	} {
+		cmd := newRootCmd("dev")
+		cmd.SetOut(new(bytes.Buffer))
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		var exitErr *ExitError
+		if err == nil || errors.As(err, &exitErr) {
+			t.Errorf("%v: err = %v, want a usage error", args, err)
+		}
+	}
+}
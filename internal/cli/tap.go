@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ppiankov/ancc/internal/validator"
+)
+
+// tapVersion is the TAP version written; 14 adds the subtests several
+// repos are reported as.
+const tapVersion = "TAP version 14"
+
+// tapDiag is the YAML diagnostic block under a test point that did not
+// pass.
+type tapDiag struct {
+	Check   string   `yaml:"check,omitempty"`
+	Message string   `yaml:"message,omitempty"`
+	At      []string `yaml:"at,omitempty"`
+}
+
+// formatTAP writes one test point per check of a repo. A failing check is
+// "not ok" and a warning is an "ok" point with a SKIP directive.
+func formatTAP(w io.Writer, result *validator.ValidationResult) error {
+	if _, err := fmt.Fprintln(w, tapVersion); err != nil {
+		return err
+	}
+	return writeTAPChecks(w, "", result)
+}
+
+// formatBatchTAP writes each repo as a subtest of its checks, summed up by
+// a test point that fails when the repo failed or could not be validated.
+func formatBatchTAP(w io.Writer, result *validator.BatchResult) error {
+	var b strings.Builder
+	b.WriteString(tapVersion + "\n")
+	fmt.Fprintf(&b, "1..%d\n", len(result.Repos))
+	for i, r := range result.Repos {
+		fmt.Fprintf(&b, "# Subtest: %s\n", r.Path)
+		if r.Result != nil {
+			if err := writeTAPChecks(&b, "    ", r.Result); err != nil {
+				return err
+			}
+		} else {
+			b.WriteString("    1..0\n")
+		}
+		ok := r.Error == "" && r.Status != validator.OverallFail
+		if err := writeTAPPoint(&b, "", i+1, ok, r.Path, "", tapDiag{Message: r.Error}); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeTAPChecks writes the plan and test points of a repo's checks,
+// indented for a subtest.
+func writeTAPChecks(w io.Writer, indent string, result *validator.ValidationResult) error {
+	type point struct {
+		prefix string
+		check  validator.CheckResult
+	}
+	var points []point
+	for _, t := range result.Tools {
+		for _, c := range t.Checks {
+			points = append(points, point{toolPrefix(t), c})
+		}
+	}
+	for _, c := range result.Checks {
+		points = append(points, point{"", c})
+	}
+
+	if _, err := fmt.Fprintf(w, "%s1..%d\n", indent, len(points)); err != nil {
+		return err
+	}
+	for i, p := range points {
+		c := p.check
+		desc := p.prefix + checkLabel(c.Name)
+		directive := ""
+		if c.Status == validator.StatusWarn {
+			directive = "SKIP " + c.Message
+		}
+		diag := tapDiag{Check: c.Name, Message: c.Message, At: locationStrings(c.Locations)}
+		if err := writeTAPPoint(w, indent, i+1, c.Status != validator.StatusFail, desc, directive, diag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTAPPoint writes a test point, with a YAML diagnostic block when it
+// is not ok.
+func writeTAPPoint(w io.Writer, indent string, n int, ok bool, desc, directive string, diag tapDiag) error {
+	line := fmt.Sprintf("%sok %d - %s", indent, n, tapEscape(desc))
+	if !ok {
+		line = indent + "not " + line[len(indent):]
+	}
+	if directive != "" {
+		line += " # " + tapEscape(directive)
+	}
+	if _, err := fmt.Fprintln(w, strings.TrimRight(line, " ")); err != nil {
+		return err
+	}
+	if ok || diag.Message == "" && len(diag.At) == 0 {
+		return nil
+	}
+
+	var data strings.Builder
+	enc := yaml.NewEncoder(&data)
+	enc.SetIndent(2)
+	if err := enc.Encode(diag); err != nil {
+		return err
+	}
+	var b strings.Builder
+	b.WriteString(indent + "  ---\n")
+	for _, l := range strings.SplitAfter(strings.TrimSuffix(data.String(), "\n"), "\n") {
+		b.WriteString(indent + "  " + l)
+	}
+	b.WriteString("\n" + indent + "  ...\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// tapEscape escapes the characters that would end a description early or
+// start a directive.
+func tapEscape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "#", `\#`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ppiankov/ancc/internal/validator"
+)
+
+func TestFormatTAP(t *testing.T) {
+	r := sampleResult()
+	r.Checks[2].Locations = []validator.Location{{Path: "SKILL.md", Line: 12}}
+	buf := new(bytes.Buffer)
+	if err := formatTAP(buf, r); err != nil {
+		t.Fatal(err)
+	}
+	want := `TAP version 14
+1..4
+ok 1 - SKILL.md exists
+ok 2 - Install section
+not ok 3 - Exit codes documented
+  ---
+  check: skill-md-exit-codes
+  message: missing exit codes section
+  at:
+    - SKILL.md:12
+  ...
+ok 4 - Binary release # SKIP skipped
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFormatBatchTAP(t *testing.T) {
+	buf := new(bytes.Buffer)
+	if err := formatBatchTAP(buf, sampleBatch()); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"TAP version 14\n1..2\n# Subtest: /src/tools/alpha\n    1..4\n",
+		"    ok 4 - Binary release # SKIP skipped\nnot ok 1 - /src/tools/alpha\n",
+		"# Subtest: /src/tools/b\n    1..0\nnot ok 2 - /src/tools/b\n  ---\n  message: repository not found\n  ...\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestTAPEscape(t *testing.T) {
+	if got := tapEscape("a # b \\ c\nd"); got != `a \# b \\ c d` {
+		t.Errorf("tapEscape = %q", got)
+	}
+}
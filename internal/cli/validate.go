@@ -1,8 +1,19 @@
 package cli
 
 import (
+	"bufio"
 	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/ppiankov/ancc/internal/config"
+	"github.com/ppiankov/ancc/internal/httpclient"
+	"github.com/ppiankov/ancc/internal/release"
+	"github.com/ppiankov/ancc/internal/skillmd"
 	"github.com/ppiankov/ancc/internal/validator"
 	"github.com/spf13/cobra"
 )
@@ -10,47 +21,313 @@ import (
 func newValidateCmd() *cobra.Command {
 	var format string
 	var verbose bool
+	var offline bool
+	var platforms []string
+	var require []string
+	var releaseSel string
+	var ref string
+	var githubAPIURL string
+	var githubHosts []string
+	var timeout time.Duration
+	var waitRateLimit, noCache bool
+	var record, replay string
+	var maxSkillSize int64
+	var tools []string
+	var fromFile string
+	var discover []string
+	var jobs int
 
 	cmd := &cobra.Command{
-		Use:   "validate [path]",
+		Use:   "validate [path...]",
 		Short: "Validate a repo against the ANCC convention",
-		Args:  cobra.MaximumNArgs(1),
+		Long: `Validate a local repo, a .tar.gz or .zip archive of one, or a repo URL on
+GitHub, GitHub Enterprise, GitLab, Gitea/Forgejo or Bitbucket Cloud against
+the ANCC convention. Forge tokens are read from GITHUB_TOKEN, GITLAB_TOKEN,
+GITEA_TOKEN (or FORGEJO_TOKEN) and BITBUCKET_TOKEN.
+
+A remote repo is read at its default branch, or at the branch, tag or
+commit given as owner/repo@ref, in a branch URL or with --ref. The release
+checks inspect the latest stable release, or the one picked by --release,
+of the repo or of a local checkout's origin; --offline reads only the
+release config in the working tree.
+
+Several repos are validated in one run when several paths are given, or
+with --from-file or --discover; the exit code follows the worst repo. A
+monorepo with several SKILL.md files is validated tool by tool.
+
+--format text (default), json, sarif, junit or tap. See the README for
+forges, release selection, supply-chain checks and output formats.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			path := "."
-			if len(args) > 0 {
-				path = args[0]
+			if releaseSel == "" && cmd.Flags().Changed("release") {
+				return fmt.Errorf("--release must be latest, any or a tag")
+			}
+			if maxSkillSize <= 0 {
+				return fmt.Errorf("--max-skill-size must be positive")
+			}
+			if jobs < 1 {
+				return fmt.Errorf("--jobs must be at least 1")
+			}
+			switch format {
+			case "text", "json", "sarif", "junit", "tap":
+			default:
+				return fmt.Errorf("--format must be text, json, sarif, junit or tap")
+			}
+			paths, err := validatePaths(cmd.InOrStdin(), args, fromFile, discover)
+			if err != nil {
+				return err
 			}
 
-			result, err := validator.Validate(path)
+			hc, err := newHTTPClient(httpclient.Options{
+				Timeout:        timeout,
+				WaitRetryAfter: waitRateLimit,
+				RecordDir:      record,
+				ReplayDir:      replay,
+			}, noCache)
 			if err != nil {
-				return fmt.Errorf("validation error: %w", err)
+				return err
 			}
 
-			w := cmd.OutOrStdout()
-			switch format {
-			case "json":
-				if err := formatJSON(w, result); err != nil {
-					return fmt.Errorf("formatting output: %w", err)
+			// optionsFor applies the .ancc.yml a directory brings; for other
+			// targets only the GitHub settings of the working directory's
+			// apply.
+			optionsFor := func(path string) (validator.Options, error) {
+				opts := validator.Options{Offline: offline, Release: releaseSel, Ref: ref, MaxSkillSize: maxSkillSize, Tools: tools}
+				opts.Forges = validator.Forges{GitHubAPIURL: githubAPIURL, HTTPClient: hc}
+				if opts.Forges.GitHubAPIURL == "" {
+					opts.Forges.GitHubAPIURL = os.Getenv("GITHUB_API_URL")
 				}
-			default:
-				formatText(w, result, verbose)
-			}
 
-			switch result.Status {
-			case validator.OverallFail:
-				return &ExitError{Code: 1}
-			case validator.OverallPartial:
-				return &ExitError{Code: 2}
+				cfgDir := "."
+				if opts.Forges.ParseRepoURL(path) == nil {
+					if fi, err := os.Stat(path); err == nil && fi.IsDir() {
+						cfgDir = path
+					}
+				}
+				cfg, err := config.Load(cfgDir)
+				if err != nil {
+					return opts, err
+				}
+				if opts.Forges.GitHubAPIURL == "" {
+					opts.Forges.GitHubAPIURL = cfg.GitHub.APIURL
+				}
+				opts.Forges.GitHubHosts = slices.Concat(githubHosts, cfg.GitHub.Hosts)
+
+				minPlatforms := platforms
+				integrity := map[string]string{}
+				if cfgDir == path && opts.Forges.ParseRepoURL(path) == nil {
+					if !cmd.Flags().Changed("platforms") {
+						minPlatforms = cfg.Release.Platforms
+					}
+					for k, v := range cfg.Release.Integrity {
+						integrity[k] = v
+					}
+				}
+				for _, r := range require {
+					integrity[r] = validator.SeverityFail
+				}
+				if opts.Platforms, err = release.ParseTargets(minPlatforms); err != nil {
+					return opts, err
+				}
+				if opts.Integrity, err = validator.ParseIntegrity(integrity); err != nil {
+					return opts, err
+				}
+				return opts, nil
+			}
+			validate := func(path string) (*validator.ValidationResult, error) {
+				opts, err := optionsFor(path)
+				if err != nil {
+					return nil, err
+				}
+				return validator.ValidateWithOptions(path, opts)
 			}
 
-			return nil
+			if len(paths) == 1 && fromFile == "" && len(discover) == 0 {
+				opts, err := optionsFor(paths[0])
+				if err != nil {
+					return err
+				}
+				result, err := validator.ValidateWithOptions(paths[0], opts)
+				if err != nil {
+					return fmt.Errorf("validation error: %w", err)
+				}
+				return writeResult(cmd.OutOrStdout(), result, format, verbose, cmd.Root().Version)
+			}
+			// Bad flags fail the run once rather than every repo.
+			if _, err := optionsFor("."); err != nil {
+				return err
+			}
+			return writeBatchResult(cmd.OutOrStdout(), validator.ValidateAll(paths, jobs, validate), format, verbose, cmd.Root().Version)
 		},
 		SilenceUsage:  true,
 		SilenceErrors: true,
 	}
 
-	cmd.Flags().StringVar(&format, "format", "text", "output format (text, json)")
+	cmd.Flags().StringVar(&format, "format", "text", "output format (text, json, sarif, junit, tap)")
 	cmd.Flags().BoolVar(&verbose, "verbose", false, "show all checks including passing")
+	cmd.Flags().BoolVar(&offline, "offline", false, "do not contact the forge; check release config locally")
+	cmd.Flags().StringSliceVar(&require, "require", nil, "integrity checks that fail instead of warn (checksums, signatures, sbom, provenance)")
+	cmd.Flags().StringVar(&releaseSel, "release", "", "release to inspect: latest, any or a tag (default the release tagged --ref, else latest)")
+	cmd.Flags().StringVar(&ref, "ref", "", "branch, tag or commit of a remote repo to validate")
+	cmd.Flags().StringVar(&githubAPIURL, "github-api-url", "", "API base of GitHub Enterprise hosts, e.g. https://ghe.example.com/api/v3 (default $GITHUB_API_URL)")
+	cmd.Flags().StringSliceVar(&githubHosts, "github-host", nil, "GitHub Enterprise hosts to recognize in repo URLs")
+	cmd.Flags().DurationVar(&timeout, "timeout", httpclient.DefaultTimeout, "time limit for each forge API request")
+	cmd.Flags().BoolVar(&waitRateLimit, "wait-rate-limit", false, "wait out rate limits that reset within 2m instead of failing")
+	cmd.Flags().BoolVar(&noCache, "no-cache", false, "do not cache forge API responses on disk")
+	cmd.Flags().StringVar(&record, "record", "", "record forge HTTP interactions to a cassette directory, credentials scrubbed")
+	cmd.Flags().StringVar(&replay, "replay", "", "serve forge HTTP interactions from a cassette directory without network access")
+	cmd.Flags().Int64Var(&maxSkillSize, "max-skill-size", skillmd.DefaultLimits.MaxBytes, "largest SKILL.md to read, in bytes")
+	cmd.Flags().StringSliceVar(&tools, "tool", nil, "directories of a monorepo's tools, each with a SKILL.md (default tools in .ancc.yml, else every SKILL.md)")
+	cmd.Flags().StringVar(&fromFile, "from-file", "", "validate the paths listed in a file, one per line (- for stdin)")
+	cmd.Flags().StringSliceVar(&discover, "discover", nil, "validate every directory under these directories holding a SKILL.md or go.mod")
+	cmd.Flags().IntVar(&jobs, "jobs", defaultJobs, "repos validated at once")
+	cmd.Flags().StringSliceVar(&platforms, "platforms", nil, "minimum release platforms as os/arch (default linux,darwin x amd64,arm64)")
 
 	return cmd
 }
+
+// defaultJobs bounds concurrent validations, which mostly wait on forge
+// APIs and their rate limits.
+const defaultJobs = 4
+
+// validatePaths gathers the repos to validate from args, the file of paths
+// fromFile and the directories to discover repos in, without duplicates.
+// With none given, the working directory is validated.
+func validatePaths(stdin io.Reader, args []string, fromFile string, discover []string) ([]string, error) {
+	paths := slices.Clone(args)
+	if fromFile != "" {
+		listed, err := readPathList(stdin, fromFile)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, listed...)
+	}
+	for _, dir := range discover {
+		found, err := validator.Discover(dir)
+		if err != nil {
+			return nil, fmt.Errorf("discovering repos: %w", err)
+		}
+		if len(found) == 0 {
+			return nil, fmt.Errorf("no SKILL.md or go.mod under %s", dir)
+		}
+		paths = append(paths, found...)
+	}
+	if len(paths) == 0 {
+		if fromFile != "" {
+			return nil, fmt.Errorf("%s lists no paths", fromFile)
+		}
+		return []string{"."}, nil
+	}
+
+	seen := make(map[string]bool)
+	out := paths[:0]
+	for _, p := range paths {
+		if !seen[p] {
+			seen[p] = true
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+// readPathList reads one path per line from file, or stdin for "-",
+// skipping blank lines and # comments.
+func readPathList(stdin io.Reader, file string) ([]string, error) {
+	r := stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = f.Close() }()
+		r = f
+	}
+	var paths []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		paths = append(paths, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", file, err)
+	}
+	return paths, nil
+}
+
+// newHTTPClient returns the client forge APIs are called through. Responses
+// are revalidated with ETags from the user cache dir unless noCache is set,
+// there is no cache dir or a cassette is recorded or replayed.
+func newHTTPClient(opts httpclient.Options, noCache bool) (*http.Client, error) {
+	if !noCache {
+		opts.CacheDir, _ = httpclient.DefaultCacheDir()
+	}
+	return httpclient.New(opts)
+}
+
+// writeResult renders a result in the requested format and maps its
+// overall status to the documented exit code.
+func writeResult(w io.Writer, result *validator.ValidationResult, format string, verbose bool, version string) error {
+	switch format {
+	case "json":
+		if err := formatJSON(w, result); err != nil {
+			return fmt.Errorf("formatting output: %w", err)
+		}
+	case "sarif":
+		repo := validator.RepoResult{Path: result.Path, Status: result.Status, Result: result}
+		if err := formatSARIF(w, version, []validator.RepoResult{repo}, verbose); err != nil {
+			return fmt.Errorf("formatting output: %w", err)
+		}
+	case "junit":
+		repo := validator.RepoResult{Path: result.Path, Status: result.Status, Result: result}
+		if err := formatJUnit(w, []validator.RepoResult{repo}); err != nil {
+			return fmt.Errorf("formatting output: %w", err)
+		}
+	case "tap":
+		if err := formatTAP(w, result); err != nil {
+			return fmt.Errorf("formatting output: %w", err)
+		}
+	default:
+		formatText(w, result, verbose)
+	}
+
+	return statusExit(result.Status)
+}
+
+// writeBatchResult renders the result of several repos and maps the worst
+// repo status to the exit code.
+func writeBatchResult(w io.Writer, result *validator.BatchResult, format string, verbose bool, version string) error {
+	switch format {
+	case "json":
+		if err := formatJSON(w, result); err != nil {
+			return fmt.Errorf("formatting output: %w", err)
+		}
+	case "sarif":
+		if err := formatSARIF(w, version, result.Repos, verbose); err != nil {
+			return fmt.Errorf("formatting output: %w", err)
+		}
+	case "junit":
+		if err := formatJUnit(w, result.Repos); err != nil {
+			return fmt.Errorf("formatting output: %w", err)
+		}
+	case "tap":
+		if err := formatBatchTAP(w, result); err != nil {
+			return fmt.Errorf("formatting output: %w", err)
+		}
+	default:
+		formatBatchText(w, result, verbose)
+	}
+	return statusExit(result.Status)
+}
+
+// statusExit maps an overall status to the documented exit code.
+func statusExit(status string) error {
+	switch status {
+	case validator.OverallFail:
+		return &ExitError{Code: 1}
+	case validator.OverallPartial:
+		return &ExitError{Code: 2}
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -21,7 +22,7 @@ func TestValidateCmd_RepoRoot(t *testing.T) {
 	cmd := newRootCmd("dev")
 	buf := new(bytes.Buffer)
 	cmd.SetOut(buf)
-	cmd.SetArgs([]string{"validate", "--verbose", repoRoot()})
+	cmd.SetArgs([]string{"validate", "--offline", "--verbose", repoRoot()})
 
 	err := cmd.Execute()
 
@@ -42,7 +43,7 @@ func TestValidateCmd_JSON(t *testing.T) {
 	cmd := newRootCmd("dev")
 	buf := new(bytes.Buffer)
 	cmd.SetOut(buf)
-	cmd.SetArgs([]string{"validate", "--format", "json", repoRoot()})
+	cmd.SetArgs([]string{"validate", "--offline", "--format", "json", repoRoot()})
 
 	err := cmd.Execute()
 
@@ -77,23 +78,47 @@ func TestValidateCmd_MissingPath(t *testing.T) {
 	}
 }
 
-func TestValidateCmd_ExitCode2_WarnOnly(t *testing.T) {
-	// Use repo root which has SKILL.md — should produce at least binary-release warn.
+func TestValidateCmd_MaxSkillSize(t *testing.T) {
 	cmd := newRootCmd("dev")
 	buf := new(bytes.Buffer)
 	cmd.SetOut(buf)
-	cmd.SetArgs([]string{"validate", "--format", "json", repoRoot()})
+	cmd.SetArgs([]string{"validate", "--offline", "--max-skill-size", "16", repoRoot()})
 
 	err := cmd.Execute()
 
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 1 {
+		t.Fatalf("expected exit code 1, got %v", err)
+	}
+	if !strings.Contains(buf.String(), "SKILL.md unusable: size exceeds the limit of 16") {
+		t.Errorf("output = %q", buf.String())
+	}
+}
+
+func TestValidateCmd_ExitCode2_WarnOnly(t *testing.T) {
+	// A valid SKILL.md without any release config warns on binary-release only.
+	data, err := os.ReadFile(filepath.Join(repoRoot(), "testdata", "valid-skill.md"))
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "SKILL.md"), data, 0o644); err != nil {
+		t.Fatalf("writing SKILL.md: %v", err)
+	}
+
+	cmd := newRootCmd("dev")
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"validate", "--format", "json", dir})
+
+	err = cmd.Execute()
+
 	var exitErr *ExitError
 	if !errors.As(err, &exitErr) {
-		t.Skipf("repo may have fails, skipping exit code 2 test: err=%v", err)
+		t.Fatalf("expected ExitError, got %v", err)
 	}
-	// If we get exit 2, great — that's warn-only.
-	// If we get exit 1, that's also valid (repo has fails).
-	if exitErr.Code != 1 && exitErr.Code != 2 {
-		t.Errorf("exit code = %d, want 1 or 2", exitErr.Code)
+	if exitErr.Code != 2 {
+		t.Errorf("exit code = %d, want 2", exitErr.Code)
 	}
 }
 
@@ -114,4 +139,225 @@ func TestValidateCmd_Help(t *testing.T) {
 	if !strings.Contains(got, "--verbose") {
 		t.Error("expected --verbose in help output")
 	}
+	if !strings.Contains(got, "--offline") {
+		t.Error("expected --offline in help output")
+	}
+}
+
+func TestValidateCmd_InvalidPlatforms(t *testing.T) {
+	cmd := newRootCmd("dev")
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetArgs([]string{"validate", "--offline", "--platforms", "linux", t.TempDir()})
+
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "invalid platform") {
+		t.Errorf("expected invalid platform error, got %v", err)
+	}
+}
+
+func TestValidateCmd_InvalidRequire(t *testing.T) {
+	cmd := newRootCmd("dev")
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetArgs([]string{"validate", "--offline", "--require", "licenses", t.TempDir()})
+
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "unknown integrity check") {
+		t.Errorf("expected unknown integrity check error, got %v", err)
+	}
+}
+
+func TestValidateCmd_EmptyRelease(t *testing.T) {
+	cmd := newRootCmd("dev")
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetArgs([]string{"validate", "--offline", "--release", "", t.TempDir()})
+
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "--release") {
+		t.Errorf("expected --release error, got %v", err)
+	}
+}
+
+func TestValidateCmd_Replay(t *testing.T) {
+	cmd := newRootCmd("dev")
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cassette := filepath.Join(repoRoot(), "testdata", "cassettes", "github-tool")
+	cmd.SetArgs([]string{"validate", "--format", "json", "--replay", cassette, "github.com/owner/tool"})
+
+	err := cmd.Execute()
+	var exitErr *ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var result validator.ValidationResult
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if result.Release != "v1.2.0" {
+		t.Errorf("release = %q, want v1.2.0 from the cassette", result.Release)
+	}
+}
+
+func TestValidateCmd_RecordAndReplay(t *testing.T) {
+	cmd := newRootCmd("dev")
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetArgs([]string{"validate", "--record", t.TempDir(), "--replay", t.TempDir(), "github.com/owner/tool"})
+
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "record and replay") {
+		t.Errorf("expected record/replay error, got %v", err)
+	}
+}
+
+// batchRepos creates a repo with the project's SKILL.md and one without.
+func batchRepos(t *testing.T) (good, bad string) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(repoRoot(), "SKILL.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := t.TempDir()
+	good, bad = filepath.Join(root, "good"), filepath.Join(root, "bad")
+	for _, dir := range []string{good, bad} {
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(good, "SKILL.md"), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(bad, "go.mod"), []byte("module bad\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return good, bad
+}
+
+func TestValidateCmd_Monorepo(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join(repoRoot(), "SKILL.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := t.TempDir()
+	for _, dir := range []string{"cmd/a", "cmd/b"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, dir, "SKILL.md"), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run := func(args ...string) validator.ValidationResult {
+		t.Helper()
+		cmd := newRootCmd("dev")
+		buf := new(bytes.Buffer)
+		cmd.SetOut(buf)
+		cmd.SetArgs(append([]string{"validate", "--offline", "--format", "json"}, args...))
+		var exitErr *ExitError
+		if err := cmd.Execute(); err != nil && !errors.As(err, &exitErr) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var parsed validator.ValidationResult
+		if err := json.Unmarshal(buf.Bytes(), &parsed); err != nil {
+			t.Fatalf("invalid JSON output: %v\nraw: %s", err, buf.String())
+		}
+		return parsed
+	}
+
+	parsed := run(root)
+	if len(parsed.Tools) != 2 || parsed.Tools[0].Path != "cmd/a" || parsed.Tools[1].Path != "cmd/b" {
+		t.Fatalf("tools = %+v, want cmd/a and cmd/b", parsed.Tools)
+	}
+	if len(parsed.Checks) != 1 || parsed.Checks[0].Name != validator.CheckHasBinaryRelease {
+		t.Errorf("repo checks = %+v, want the release check once", parsed.Checks)
+	}
+
+	parsed = run("--tool", "cmd/b", root)
+	if len(parsed.Tools) != 1 || parsed.Tools[0].Path != "cmd/b" {
+		t.Errorf("tools = %+v, want only cmd/b", parsed.Tools)
+	}
+}
+
+func TestValidateCmd_MultiplePaths(t *testing.T) {
+	good, bad := batchRepos(t)
+	cmd := newRootCmd("dev")
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"validate", "--offline", "--format", "json", bad, good, bad})
+
+	err := cmd.Execute()
+
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 1 {
+		t.Fatalf("expected exit code 1 from the failing repo, got %v", err)
+	}
+	var parsed validator.BatchResult
+	if err := json.Unmarshal(buf.Bytes(), &parsed); err != nil {
+		t.Fatalf("invalid JSON output: %v\nraw: %s", err, buf.String())
+	}
+	if len(parsed.Repos) != 2 || parsed.Repos[0].Path != bad || parsed.Repos[1].Path != good {
+		t.Fatalf("repos = %+v, want bad then good, once each", parsed.Repos)
+	}
+	if parsed.Repos[1].Status != validator.OverallPartial {
+		t.Errorf("good repo status = %q", parsed.Repos[1].Status)
+	}
+	if len(parsed.Checks) == 0 || parsed.Checks[0].Fail != 1 {
+		t.Errorf("checks = %+v, want failures counted", parsed.Checks)
+	}
+}
+
+func TestValidateCmd_FromFile(t *testing.T) {
+	good, _ := batchRepos(t)
+	cmd := newRootCmd("dev")
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetIn(strings.NewReader("# tools\n\n" + good + "\n"))
+	cmd.SetArgs([]string{"validate", "--offline", "--from-file", "-"})
+
+	err := cmd.Execute()
+
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 2 {
+		t.Fatalf("expected exit code 2 from warnings only, got %v", err)
+	}
+	if !strings.Contains(buf.String(), "PARTIAL  "+good) || !strings.Contains(buf.String(), "Result: PARTIAL (1 repo(s)") {
+		t.Errorf("output = %q", buf.String())
+	}
+}
+
+func TestValidateCmd_Discover(t *testing.T) {
+	good, bad := batchRepos(t)
+	cmd := newRootCmd("dev")
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"validate", "--offline", "--jobs", "1", "--discover", filepath.Dir(good)})
+
+	_ = cmd.Execute()
+
+	out := buf.String()
+	b, g := strings.Index(out, bad), strings.Index(out, good)
+	if b < 0 || g < 0 || b > g {
+		t.Errorf("want bad then good in path order:\n%s", out)
+	}
+}
+
+func TestValidateCmd_BatchErrors(t *testing.T) {
+	empty := filepath.Join(t.TempDir(), "paths.txt")
+	if err := os.WriteFile(empty, []byte("# nothing\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"--jobs", "0", "."},
+		{"--from-file", empty},
+		{"--discover", t.TempDir()},
+		{"--platforms", "plan9", ".", ".."},
+	} {
+		cmd := newRootCmd("dev")
+		cmd.SetOut(new(bytes.Buffer))
+		cmd.SetArgs(append([]string{"validate", "--offline"}, args...))
+		err := cmd.Execute()
+		var exitErr *ExitError
+		if err == nil || errors.As(err, &exitErr) {
+			t.Errorf("%v: err = %v, want a usage error", args, err)
+		}
+	}
 }
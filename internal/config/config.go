@@ -0,0 +1,100 @@
+// Package config loads optional per-repo ancc settings from .ancc.yml.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the config file looked up at the repo root.
+const FileName = ".ancc.yml"
+
+// DefaultProbeTimeout bounds a single probed command.
+const DefaultProbeTimeout = 10 * time.Second
+
+// Config holds repo-level ancc settings. Every field is optional.
+type Config struct {
+	Probe   Probe   `yaml:"probe"`
+	Release Release `yaml:"release"`
+	GitHub  GitHub  `yaml:"github"`
+	// Tools lists the directories of a monorepo's tools, each with its own
+	// SKILL.md, e.g. cmd/mytool. Empty means every directory holding one.
+	Tools []string `yaml:"tools"`
+}
+
+// GitHub configures access to GitHub Enterprise hosts.
+type GitHub struct {
+	// APIURL is the API base of GitHub Enterprise hosts, e.g.
+	// https://ghe.example.com/api/v3.
+	APIURL string `yaml:"api_url"`
+	// Hosts lists GitHub Enterprise hosts whose repo URLs are recognized,
+	// e.g. ghe.example.com.
+	Hosts []string `yaml:"hosts"`
+}
+
+// Release configures the binary release check.
+type Release struct {
+	// Platforms is the minimum os/arch matrix a release must ship, e.g.
+	// "linux/amd64". Empty means linux and darwin on amd64 and arm64.
+	Platforms []string `yaml:"platforms"`
+	// Integrity sets the severity of the supply-chain checks (checksums,
+	// signatures, sbom, provenance): warn (default), fail or off.
+	Integrity map[string]string `yaml:"integrity"`
+}
+
+// Probe configures runtime probing of a built binary.
+type Probe struct {
+	// Allow lists commands that are safe to execute even though SKILL.md
+	// does not mark them read-only, e.g. "mytool status".
+	Allow []string `yaml:"allow"`
+	// Args supplies positional arguments per command, keyed like Allow.
+	Args map[string][]string `yaml:"args"`
+	// Env lists NAME=value pairs added to the otherwise scrubbed environment.
+	Env []string `yaml:"env"`
+	// Timeout bounds each probed command.
+	Timeout time.Duration `yaml:"timeout"`
+	// Runs is how many times each command runs; two or more compare the
+	// JSON outputs for nondeterministic fields.
+	Runs int `yaml:"runs"`
+	// Budget is the wall-clock time each command should finish within.
+	Budget time.Duration `yaml:"budget"`
+}
+
+// Default returns the configuration used when no file is present.
+func Default() *Config {
+	return &Config{
+		Probe: Probe{Timeout: DefaultProbeTimeout, Runs: 1},
+	}
+}
+
+// Load reads .ancc.yml from dir. A missing file yields the defaults.
+func Load(dir string) (*Config, error) {
+	return LoadFS(os.DirFS(dir))
+}
+
+// LoadFS reads .ancc.yml from the root of fsys. A missing file yields the defaults.
+func LoadFS(fsys fs.FS) (*Config, error) {
+	cfg := Default()
+	data, err := fs.ReadFile(fsys, FileName)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", FileName, err)
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", FileName, err)
+	}
+	if cfg.Probe.Timeout <= 0 {
+		cfg.Probe.Timeout = DefaultProbeTimeout
+	}
+	if cfg.Probe.Runs < 1 {
+		cfg.Probe.Runs = 1
+	}
+	return cfg, nil
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestLoadFS_Missing(t *testing.T) {
+	cfg, err := LoadFS(fstest.MapFS{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Probe.Timeout != DefaultProbeTimeout {
+		t.Errorf("timeout = %v, want %v", cfg.Probe.Timeout, DefaultProbeTimeout)
+	}
+}
+
+func TestLoadFS_Probe(t *testing.T) {
+	data := `probe:
+  allow:
+    - mytool status
+  args:
+    mytool status: ["."]
+  env:
+    - MYTOOL_CONFIG=/dev/null
+  timeout: 3s
+  runs: 3
+  budget: 500ms
+`
+	cfg, err := LoadFS(fstest.MapFS{FileName: {Data: []byte(data)}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.Probe.Allow) != 1 || cfg.Probe.Allow[0] != "mytool status" {
+		t.Errorf("allow = %v, want [mytool status]", cfg.Probe.Allow)
+	}
+	if got := cfg.Probe.Args["mytool status"]; len(got) != 1 || got[0] != "." {
+		t.Errorf("args = %v, want [.]", got)
+	}
+	if len(cfg.Probe.Env) != 1 {
+		t.Errorf("env = %v, want one entry", cfg.Probe.Env)
+	}
+	if cfg.Probe.Timeout != 3*time.Second {
+		t.Errorf("timeout = %v, want 3s", cfg.Probe.Timeout)
+	}
+	if cfg.Probe.Runs != 3 || cfg.Probe.Budget != 500*time.Millisecond {
+		t.Errorf("runs = %d, budget = %v, want 3 and 500ms", cfg.Probe.Runs, cfg.Probe.Budget)
+	}
+}
+
+func TestLoadFS_Invalid(t *testing.T) {
+	_, err := LoadFS(fstest.MapFS{FileName: {Data: []byte("probe: [unclosed")}})
+	if err == nil {
+		t.Error("expected error for invalid YAML")
+	}
+}
+
+func TestLoadFS_Tools(t *testing.T) {
+	data := "tools:\n  - cmd/alpha\n  - cmd/beta\n"
+	cfg, err := LoadFS(fstest.MapFS{FileName: {Data: []byte(data)}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := cfg.Tools; len(got) != 2 || got[0] != "cmd/alpha" || got[1] != "cmd/beta" {
+		t.Errorf("tools = %v, want [cmd/alpha cmd/beta]", got)
+	}
+}
+
+func TestLoadFS_ReleasePlatforms(t *testing.T) {
+	data := "release:\n  platforms: [linux/amd64, windows/amd64]\n  integrity:\n    checksums: fail\n"
+	cfg, err := LoadFS(fstest.MapFS{FileName: {Data: []byte(data)}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := cfg.Release.Platforms; len(got) != 2 || got[1] != "windows/amd64" {
+		t.Errorf("platforms = %v, want [linux/amd64 windows/amd64]", got)
+	}
+	if got := cfg.Release.Integrity["checksums"]; got != "fail" {
+		t.Errorf("integrity checksums = %q, want fail", got)
+	}
+}
+
+func TestLoadFS_GitHub(t *testing.T) {
+	data := "github:\n  api_url: https://ghe.example.com/api/v3\n  hosts: [ghe.example.com, git.corp.example]\n"
+	cfg, err := LoadFS(fstest.MapFS{FileName: {Data: []byte(data)}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.GitHub.APIURL != "https://ghe.example.com/api/v3" {
+		t.Errorf("api_url = %q", cfg.GitHub.APIURL)
+	}
+	if got := cfg.GitHub.Hosts; len(got) != 2 || got[1] != "git.corp.example" {
+		t.Errorf("hosts = %v", got)
+	}
+}
@@ -0,0 +1,276 @@
+// Package drift compares the commands documented in SKILL.md against the
+// commands a tool actually implements, as recovered from its source code.
+package drift
+
+import (
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"github.com/ppiankov/ancc/internal/skillmd"
+)
+
+// Analyzer extracts the implemented command tree from a repo's source.
+//
+// Extracted commands use the SKILL.md model: Name is the subcommand path
+// without the program name ("" for the root command, "config set" for a
+// nested one) and each Flag.Name lists the option strings joined by ", ".
+type Analyzer interface {
+	// Name identifies the analyzer in check messages.
+	Name() string
+	// Detect reports whether the analyzer applies to the repo.
+	Detect(fsys fs.FS) bool
+	// Analyze walks the repo source and returns the implemented commands.
+	Analyze(fsys fs.FS) ([]skillmd.Command, error)
+}
+
+// analyzers is the ordered list consulted by Detect.
+var analyzers = []Analyzer{
+	pythonAnalyzer{},
+	rustAnalyzer{},
+}
+
+// Detect returns the first analyzer that applies to the repo, or nil.
+func Detect(fsys fs.FS) Analyzer {
+	for _, a := range analyzers {
+		if a.Detect(fsys) {
+			return a
+		}
+	}
+	return nil
+}
+
+// Issue kinds.
+const (
+	// IssueMissing marks something documented in SKILL.md but absent from source.
+	IssueMissing = "missing"
+	// IssueUndocumented marks something implemented but absent from SKILL.md.
+	IssueUndocumented = "undocumented"
+)
+
+// Issue is a single difference between SKILL.md and the source.
+type Issue struct {
+	Kind    string
+	Command string      // subcommand path, "" for the root command
+	Flag    string      // empty for command-level issues
+	Pos     skillmd.Pos // in SKILL.md when missing, in the source when undocumented
+}
+
+func (i Issue) String() string {
+	s := i.Command
+	if i.Flag != "" {
+		s = strings.TrimSpace(s + " " + i.Flag)
+	}
+	if s == "" {
+		s = "(root)"
+	}
+	return s
+}
+
+// ignoredFlags are provided by every CLI framework and never need documenting.
+var ignoredFlags = map[string]bool{
+	"-h":        true,
+	"--help":    true,
+	"--version": true,
+}
+
+// Diff compares the documented commands in sf against the implemented ones.
+// Issues are sorted with missing items first, then by command and flag.
+func Diff(sf *skillmd.SkillFile, implemented []skillmd.Command) []Issue {
+	impl := make(map[string]skillmd.Command, len(implemented))
+	topLevel := make(map[string]bool)
+	for _, c := range implemented {
+		impl[c.Name] = c
+		if c.Name != "" {
+			topLevel[strings.Fields(c.Name)[0]] = true
+		}
+	}
+
+	var issues []Issue
+	documented := make(map[string]bool)
+	documentedFlags := make(map[string]bool)
+
+	for _, dc := range sf.Commands {
+		path := commandPath(dc.Name, sf.Name, topLevel)
+		documented[path] = true
+
+		ic, ok := impl[path]
+		if !ok {
+			issues = append(issues, Issue{Kind: IssueMissing, Command: path, Pos: dc.Pos})
+			continue
+		}
+
+		// Flags may be defined on the command itself or inherited from a parent.
+		available := make(map[string]bool)
+		for _, p := range ancestors(path) {
+			for _, f := range impl[p].Flags {
+				for _, alias := range flagAliases(f.Name) {
+					available[alias] = true
+				}
+			}
+		}
+
+		own := make(map[string]bool)
+		for _, f := range dc.Flags {
+			aliases := flagAliases(f.Name)
+			if len(aliases) == 0 {
+				continue
+			}
+			found := false
+			for _, alias := range aliases {
+				own[alias] = true
+				documentedFlags[alias] = true
+				if available[alias] {
+					found = true
+				}
+			}
+			if !found {
+				issues = append(issues, Issue{Kind: IssueMissing, Command: path, Flag: aliases[0], Pos: f.Pos})
+			}
+		}
+
+		for _, f := range ic.Flags {
+			if !flagDocumented(f.Name, own) {
+				issues = append(issues, Issue{Kind: IssueUndocumented, Command: path, Flag: primaryFlag(f.Name), Pos: f.Pos})
+			}
+		}
+	}
+
+	for _, ic := range implemented {
+		if documented[ic.Name] {
+			continue
+		}
+		if ic.Name == "" {
+			// Root flags apply everywhere; report them only if no command mentions them.
+			for _, f := range ic.Flags {
+				if !flagDocumented(f.Name, documentedFlags) {
+					issues = append(issues, Issue{Kind: IssueUndocumented, Flag: primaryFlag(f.Name), Pos: f.Pos})
+				}
+			}
+			continue
+		}
+		// A group is covered when any of its subcommands is documented.
+		if hasDocumentedChild(ic.Name, documented) {
+			continue
+		}
+		issues = append(issues, Issue{Kind: IssueUndocumented, Command: ic.Name, Pos: ic.Pos})
+	}
+
+	sort.SliceStable(issues, func(a, b int) bool {
+		if issues[a].Kind != issues[b].Kind {
+			return issues[a].Kind == IssueMissing
+		}
+		if issues[a].Command != issues[b].Command {
+			return issues[a].Command < issues[b].Command
+		}
+		return issues[a].Flag < issues[b].Flag
+	})
+	return issues
+}
+
+// commandPath converts a documented heading such as "mytool config set <key>"
+// into a subcommand path ("config set") comparable with extracted commands.
+func commandPath(name, program string, topLevel map[string]bool) string {
+	var words []string
+	for _, w := range strings.Fields(name) {
+		if strings.HasPrefix(w, "-") {
+			// Flags and their values end the command path.
+			break
+		}
+		if strings.ContainsAny(w[:1], "<[{") {
+			continue
+		}
+		words = append(words, w)
+	}
+	if len(words) == 0 {
+		return ""
+	}
+
+	// The first word is the binary name unless it is itself a subcommand.
+	if words[0] == program || (len(words) > 1 && !topLevel[words[0]]) {
+		words = words[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+// ancestors returns path and every parent path up to the root ("").
+func ancestors(path string) []string {
+	out := []string{path}
+	words := strings.Fields(path)
+	for i := len(words) - 1; i >= 0; i-- {
+		out = append(out, strings.Join(words[:i], " "))
+	}
+	return out
+}
+
+func hasDocumentedChild(path string, documented map[string]bool) bool {
+	prefix := path + " "
+	for d := range documented {
+		if strings.HasPrefix(d, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// flagAliases extracts option strings from a flag name such as
+// "--format json", "-f, --format" or "--shout/--no-shout".
+func flagAliases(name string) []string {
+	var out []string
+	for _, field := range strings.FieldsFunc(name, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '/' || r == '='
+	}) {
+		if strings.HasPrefix(field, "-") && len(field) > 1 {
+			out = append(out, field)
+		}
+	}
+	return out
+}
+
+// primaryFlag returns the preferred spelling of a flag for reporting:
+// the first long option, or the first option if none is long.
+func primaryFlag(name string) string {
+	aliases := flagAliases(name)
+	for _, a := range aliases {
+		if strings.HasPrefix(a, "--") {
+			return a
+		}
+	}
+	if len(aliases) > 0 {
+		return aliases[0]
+	}
+	return name
+}
+
+func flagDocumented(name string, documented map[string]bool) bool {
+	aliases := flagAliases(name)
+	for _, a := range aliases {
+		if ignoredFlags[a] || documented[a] {
+			return true
+		}
+	}
+	return len(aliases) == 0
+}
+
+// Summarize renders issues as a short human-readable message.
+func Summarize(analyzer string, issues []Issue) string {
+	var missing, undocumented []string
+	for _, i := range issues {
+		switch i.Kind {
+		case IssueMissing:
+			missing = append(missing, i.String())
+		case IssueUndocumented:
+			undocumented = append(undocumented, i.String())
+		}
+	}
+
+	var parts []string
+	if len(missing) > 0 {
+		parts = append(parts, "documented but not implemented: "+strings.Join(missing, ", "))
+	}
+	if len(undocumented) > 0 {
+		parts = append(parts, "implemented but not documented: "+strings.Join(undocumented, ", "))
+	}
+	return fmt.Sprintf("%s: %s", analyzer, strings.Join(parts, "; "))
+}
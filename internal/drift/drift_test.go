@@ -0,0 +1,161 @@
+package drift
+
+import (
+	"testing"
+
+	"github.com/ppiankov/ancc/internal/skillmd"
+)
+
+func skill(cmds ...skillmd.Command) *skillmd.SkillFile {
+	return &skillmd.SkillFile{Name: "mytool", Commands: cmds}
+}
+
+func flags(names ...string) []skillmd.Flag {
+	var out []skillmd.Flag
+	for _, n := range names {
+		out = append(out, skillmd.Flag{Name: n})
+	}
+	return out
+}
+
+func TestDiff_Match(t *testing.T) {
+	sf := skill(
+		skillmd.Command{Name: "mytool run", Flags: flags("--format json", "--verbose")},
+		skillmd.Command{Name: "mytool config set <key>"},
+	)
+	impl := []skillmd.Command{
+		{Name: ""},
+		{Name: "run", Flags: flags("-f, --format", "--verbose")},
+		{Name: "config"},
+		{Name: "config set"},
+	}
+
+	if issues := Diff(sf, impl); len(issues) != 0 {
+		t.Errorf("expected no issues, got %v", issues)
+	}
+}
+
+func TestDiff_MissingAndUndocumented(t *testing.T) {
+	sf := skill(
+		skillmd.Command{Name: "mytool run", Flags: flags("--format json", "--dry-run")},
+		skillmd.Command{Name: "mytool gone"},
+	)
+	impl := []skillmd.Command{
+		{Name: "", Flags: flags("--debug", "-h, --help")},
+		{Name: "run", Flags: flags("--format", "--limit")},
+		{Name: "extra"},
+	}
+
+	issues := Diff(sf, impl)
+	want := []Issue{
+		{Kind: IssueMissing, Command: "gone"},
+		{Kind: IssueMissing, Command: "run", Flag: "--dry-run"},
+		{Kind: IssueUndocumented, Command: "", Flag: "--debug"},
+		{Kind: IssueUndocumented, Command: "extra"},
+		{Kind: IssueUndocumented, Command: "run", Flag: "--limit"},
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("got %d issues %v, want %d", len(issues), issues, len(want))
+	}
+	for i := range want {
+		if issues[i] != want[i] {
+			t.Errorf("issues[%d] = %+v, want %+v", i, issues[i], want[i])
+		}
+	}
+}
+
+func TestDiff_InheritedFlag(t *testing.T) {
+	sf := skill(skillmd.Command{Name: "mytool run", Flags: flags("--config")})
+	impl := []skillmd.Command{
+		{Name: "", Flags: flags("--config")},
+		{Name: "run"},
+	}
+
+	if issues := Diff(sf, impl); len(issues) != 0 {
+		t.Errorf("expected inherited flag to match, got %v", issues)
+	}
+}
+
+func TestCommandPath(t *testing.T) {
+	top := map[string]bool{"run": true, "config": true}
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"mytool run", "run"},
+		{"mytool config set <key> [value]", "config set"},
+		{"run", "run"},
+		{"config set", "config set"},
+		{"mytool", ""},
+		{"othername run --format json", "run"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := commandPath(tt.name, "mytool", top); got != tt.want {
+				t.Errorf("commandPath(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlagAliases(t *testing.T) {
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"--format json", []string{"--format"}},
+		{"-f, --format", []string{"-f", "--format"}},
+		{"--shout/--no-shout", []string{"--shout", "--no-shout"}},
+		{"--out=FILE", []string{"--out"}},
+		{"verbose", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := flagAliases(tt.name)
+			if len(got) != len(tt.want) {
+				t.Fatalf("flagAliases(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("flagAliases(%q)[%d] = %q, want %q", tt.name, i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	got := Summarize("python", []Issue{
+		{Kind: IssueMissing, Command: "run", Flag: "--x"},
+		{Kind: IssueUndocumented, Command: "extra"},
+	})
+	want := "python: documented but not implemented: run --x; implemented but not documented: extra"
+	if got != want {
+		t.Errorf("Summarize = %q, want %q", got, want)
+	}
+}
+
+func TestDiff_Positions(t *testing.T) {
+	sf := skill(
+		skillmd.Command{Name: "mytool run", Pos: skillmd.Pos{Line: 12}, Flags: []skillmd.Flag{{Name: "--dry-run", Pos: skillmd.Pos{Line: 15}}}},
+		skillmd.Command{Name: "mytool gone", Pos: skillmd.Pos{Line: 20}},
+	)
+	impl := []skillmd.Command{
+		{Name: "run", Pos: skillmd.Pos{File: "cli.py", Line: 3}},
+		{Name: "extra", Pos: skillmd.Pos{File: "cli.py", Line: 9}},
+	}
+	want := map[string]skillmd.Pos{
+		"gone":          {Line: 20},
+		"run --dry-run": {Line: 15},
+		"extra":         {File: "cli.py", Line: 9},
+	}
+	issues := Diff(sf, impl)
+	if len(issues) != len(want) {
+		t.Fatalf("got issues %v, want %d", issues, len(want))
+	}
+	for _, i := range issues {
+		if i.Pos != want[i.String()] {
+			t.Errorf("%s at %+v, want %+v", i, i.Pos, want[i.String()])
+		}
+	}
+}
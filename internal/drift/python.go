@@ -0,0 +1,637 @@
+package drift
+
+import (
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/ppiankov/ancc/internal/skillmd"
+)
+
+// pythonAnalyzer recovers argparse and click command trees from Python
+// source without executing it.
+type pythonAnalyzer struct{}
+
+func (pythonAnalyzer) Name() string { return "python (argparse/click)" }
+
+func (pythonAnalyzer) Detect(fsys fs.FS) bool {
+	return fileExists(fsys, "pyproject.toml") || fileExists(fsys, "setup.cfg")
+}
+
+// pythonSkipDirs are never scanned: virtualenvs, caches, build output and tests.
+var pythonSkipDirs = map[string]bool{
+	".git":          true,
+	".venv":         true,
+	"venv":          true,
+	".tox":          true,
+	".nox":          true,
+	"node_modules":  true,
+	"__pycache__":   true,
+	"build":         true,
+	"dist":          true,
+	"site-packages": true,
+	"tests":         true,
+	"test":          true,
+}
+
+func (pythonAnalyzer) Analyze(fsys fs.FS) ([]skillmd.Command, error) {
+	var files []pyFile
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != "." && pythonSkipDirs[d.Name()] {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		name := d.Name()
+		if path.Ext(name) != ".py" || strings.HasPrefix(name, "test_") || strings.HasSuffix(name, "_test.py") {
+			return nil
+		}
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, pyFile{path: p, toks: tokenizePython(string(data))})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Click groups are often declared in one module and extended in another,
+	// so a first pass learns every group before the second builds the tree.
+	groups := map[string]string{}
+	for pass := 0; pass < 2; pass++ {
+		st := newPythonState(groups)
+		for _, f := range files {
+			st.file = f.path
+			st.extract(f.toks)
+		}
+		if pass == 1 {
+			st.finish()
+			return st.tree.commands(), nil
+		}
+		groups = st.groups
+	}
+	return nil, nil
+}
+
+// pyFile is the token stream of one Python source file.
+type pyFile struct {
+	path string
+	toks []token
+}
+
+// commandTree accumulates commands and flags keyed by subcommand path.
+type commandTree struct {
+	cmds map[string]*skillmd.Command
+	pos  skillmd.Pos // source being read, recorded on new commands and flags
+}
+
+func newCommandTree() *commandTree {
+	return &commandTree{cmds: make(map[string]*skillmd.Command)}
+}
+
+func (t *commandTree) add(path string) *skillmd.Command {
+	if c, ok := t.cmds[path]; ok {
+		return c
+	}
+	c := &skillmd.Command{Name: path, Pos: t.pos}
+	t.cmds[path] = c
+	return c
+}
+
+func (t *commandTree) addFlag(path string, options []string) {
+	if len(options) == 0 {
+		return
+	}
+	c := t.add(path)
+	name := strings.Join(options, ", ")
+	for _, f := range c.Flags {
+		if f.Name == name {
+			return
+		}
+	}
+	c.Flags = append(c.Flags, skillmd.Flag{Name: name, Pos: t.pos})
+}
+
+func (t *commandTree) commands() []skillmd.Command {
+	out := make([]skillmd.Command, 0, len(t.cmds))
+	for _, c := range t.cmds {
+		out = append(out, *c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+func joinPath(parent, name string) string {
+	return strings.TrimSpace(parent + " " + name)
+}
+
+func fileExists(fsys fs.FS, name string) bool {
+	_, err := fs.Stat(fsys, name)
+	return err == nil
+}
+
+// --- Tokenizer ---
+
+// Token kinds.
+const (
+	tokIdent = iota
+	tokString
+	tokNumber
+	tokOp
+)
+
+type token struct {
+	kind int
+	text string // identifier, operator, or unquoted string value
+	line int
+}
+
+// tokenizePython splits Python source into identifiers, string literals,
+// numbers and single-character operators. Comments and whitespace are
+// dropped. The tokenizer never fails: malformed input yields best-effort
+// tokens so a single odd file cannot abort the analysis.
+func tokenizePython(src string) []token {
+	var toks []token
+	line := 1
+	i := 0
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r' || c == '\\' || c == '\f':
+			i++
+		case c == '#':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case c == '"' || c == '\'':
+			start := line
+			val, n, lines := scanPythonString(src[i:], false)
+			toks = append(toks, token{kind: tokString, text: val, line: start})
+			line += lines
+			i += n
+		case isIdentStart(c):
+			j := i
+			for j < len(src) && isIdentChar(src[j]) {
+				j++
+			}
+			word := src[i:j]
+			// String prefixes: r"", b"", f"", rb"", u"" and friends.
+			if j < len(src) && (src[j] == '"' || src[j] == '\'') && len(word) <= 2 && isStringPrefix(word) {
+				start := line
+				raw := strings.ContainsAny(word, "rR")
+				val, n, lines := scanPythonString(src[j:], raw)
+				toks = append(toks, token{kind: tokString, text: val, line: start})
+				line += lines
+				i = j + n
+				continue
+			}
+			toks = append(toks, token{kind: tokIdent, text: word, line: line})
+			i = j
+		case c >= '0' && c <= '9':
+			j := i
+			for j < len(src) && (isIdentChar(src[j]) || src[j] == '.') {
+				j++
+			}
+			toks = append(toks, token{kind: tokNumber, text: src[i:j], line: line})
+			i = j
+		default:
+			toks = append(toks, token{kind: tokOp, text: string(c), line: line})
+			i++
+		}
+	}
+	return toks
+}
+
+// scanPythonString reads a string literal at the start of s. It returns the
+// unquoted value, the number of bytes consumed and the newlines crossed.
+func scanPythonString(s string, raw bool) (string, int, int) {
+	q := s[0]
+	delim := string(q)
+	if strings.HasPrefix(s, strings.Repeat(delim, 3)) {
+		delim = strings.Repeat(delim, 3)
+	}
+
+	var b strings.Builder
+	lines := 0
+	i := len(delim)
+	for i < len(s) {
+		if strings.HasPrefix(s[i:], delim) {
+			return b.String(), i + len(delim), lines
+		}
+		c := s[i]
+		if c == '\n' {
+			lines++
+			if len(delim) == 1 {
+				// Unterminated single-line string.
+				return b.String(), i, lines - 1
+			}
+		}
+		if c == '\\' && i+1 < len(s) {
+			if raw {
+				b.WriteByte(c)
+			}
+			if s[i+1] == '\n' {
+				lines++
+			}
+			b.WriteByte(s[i+1])
+			i += 2
+			continue
+		}
+		b.WriteByte(c)
+		i++
+	}
+	return b.String(), i, lines
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || (c >= '0' && c <= '9')
+}
+
+func isStringPrefix(w string) bool {
+	for _, r := range strings.ToLower(w) {
+		if r != 'r' && r != 'b' && r != 'f' && r != 'u' {
+			return false
+		}
+	}
+	return true
+}
+
+// --- Call parsing ---
+
+// callArgs holds the positional string values and keyword arguments of a call.
+type callArgs struct {
+	strings  []string           // positional string literals, in order
+	keywords map[string][]token // keyword name -> value tokens
+}
+
+// parseCall parses the argument list opening at toks[i] == "(" and returns
+// the arguments and the index just past the closing parenthesis.
+func parseCall(toks []token, i int) (callArgs, int) {
+	args := callArgs{keywords: map[string][]token{}}
+	if i >= len(toks) || toks[i].text != "(" || toks[i].kind != tokOp {
+		return args, i
+	}
+
+	depth := 0
+	var cur []token
+	flush := func() {
+		switch {
+		case len(cur) == 1 && cur[0].kind == tokString:
+			args.strings = append(args.strings, cur[0].text)
+		case len(cur) >= 2 && cur[0].kind == tokIdent && cur[1].text == "=" && cur[1].kind == tokOp &&
+			(len(cur) == 2 || cur[2].text != "="):
+			args.keywords[cur[0].text] = cur[2:]
+		}
+		cur = nil
+	}
+
+	for j := i; j < len(toks); j++ {
+		t := toks[j]
+		if t.kind == tokOp {
+			switch t.text {
+			case "(", "[", "{":
+				depth++
+				if depth == 1 {
+					continue
+				}
+			case ")", "]", "}":
+				depth--
+				if depth == 0 {
+					flush()
+					return args, j + 1
+				}
+			case ",":
+				if depth == 1 {
+					flush()
+					continue
+				}
+			}
+		}
+		cur = append(cur, t)
+	}
+	flush()
+	return args, len(toks)
+}
+
+// kwString returns the value of a keyword argument if it is a single string literal.
+func (a callArgs) kwString(key string) string {
+	v := a.keywords[key]
+	if len(v) == 1 && v[0].kind == tokString {
+		return v[0].text
+	}
+	return ""
+}
+
+// optionStrings returns the option strings ("-f", "--format") among the
+// positional arguments. Click's "--on/--off" pairs are split.
+func (a callArgs) optionStrings() []string {
+	var out []string
+	for _, s := range a.strings {
+		for _, part := range strings.Split(s, "/") {
+			part = strings.TrimSpace(part)
+			if strings.HasPrefix(part, "-") && len(part) > 1 {
+				out = append(out, part)
+			}
+		}
+	}
+	return out
+}
+
+// --- argparse and click extraction ---
+
+// pyDecorator is a parsed "@a.b.c(args)" line.
+type pyDecorator struct {
+	name string // dotted name, e.g. "click.option" or "cli.command"
+	args callArgs
+	line int
+}
+
+// pyAttach is a deferred "group.add_command(func, name)" call.
+type pyAttach struct {
+	group string
+	fn    string
+	name  string
+}
+
+// pythonState carries click state across files; argparse variables are
+// tracked per file since parsers rarely escape the module that builds them.
+type pythonState struct {
+	file      string // path of the file being extracted
+	tree      *commandTree
+	groups    map[string]string // click group function -> command path
+	known     map[string]string // groups learned by a previous pass
+	funcPaths map[string]string // click command function -> command path
+	detached  []string          // click.command functions without a parent group
+	attach    []pyAttach
+}
+
+func newPythonState(known map[string]string) *pythonState {
+	return &pythonState{
+		tree:      newCommandTree(),
+		groups:    map[string]string{},
+		known:     known,
+		funcPaths: map[string]string{},
+	}
+}
+
+// extract walks the token stream of one file.
+func (st *pythonState) extract(toks []token) {
+	parsers := map[string]string{}    // argparse parser variable -> command path
+	subparsers := map[string]string{} // add_subparsers() variable -> parent path
+	var pending []pyDecorator
+
+	for i := 0; i < len(toks); i++ {
+		t := toks[i]
+
+		// Decorators: collect until the function definition.
+		if t.kind == tokOp && t.text == "@" && i+1 < len(toks) && toks[i+1].kind == tokIdent {
+			name, j := dottedName(toks, i+1)
+			d := pyDecorator{name: name, line: t.line}
+			if j < len(toks) && toks[j].text == "(" {
+				d.args, j = parseCall(toks, j)
+			}
+			pending = append(pending, d)
+			i = j - 1
+			continue
+		}
+
+		if t.kind == tokIdent && (t.text == "def" || t.text == "class") {
+			if t.text == "def" && i+1 < len(toks) && toks[i+1].kind == tokIdent && len(pending) > 0 {
+				st.define(toks[i+1].text, pending)
+			}
+			pending = nil
+			continue
+		}
+
+		// Only match at the start of a dotted expression.
+		if t.kind != tokIdent || (i > 0 && toks[i-1].kind == tokOp && toks[i-1].text == ".") {
+			continue
+		}
+
+		// Optional assignment target: var = recv.method(args). Attribute
+		// targets such as self.parser are tracked by their last component.
+		var target string
+		start := i
+		if lhs, k := dottedName(toks, i); k+1 < len(toks) && toks[k].kind == tokOp && toks[k].text == "=" && toks[k+1].text != "=" {
+			_, target = splitDotted(lhs)
+			start = k + 1
+		}
+		recv, method, j := methodCall(toks, start)
+		if method == "" {
+			continue
+		}
+		_, recv = splitDotted(recv)
+		i = j - 1
+		st.tree.pos = skillmd.Pos{File: st.file, Line: toks[start].line}
+
+		switch method {
+		case "ArgumentParser":
+			if target != "" {
+				parsers[target] = ""
+			}
+			st.tree.add("")
+		case "add_subparsers":
+			if p, ok := parsers[recv]; ok && target != "" {
+				subparsers[target] = p
+			}
+		case "add_parser":
+			args, _ := parseCall(toks, j)
+			if len(args.strings) == 0 {
+				continue
+			}
+			// Subparsers handed to a helper function are assumed top level.
+			p := joinPath(subparsers[recv], args.strings[0])
+			st.tree.add(p)
+			if target != "" {
+				parsers[target] = p
+			}
+		case "add_argument_group", "add_mutually_exclusive_group":
+			if p, ok := parsers[recv]; ok && target != "" {
+				parsers[target] = p
+			}
+		case "add_argument":
+			if p, ok := parsers[recv]; ok {
+				args, _ := parseCall(toks, j)
+				st.tree.addFlag(p, args.optionStrings())
+			}
+		case "add_command":
+			args, _ := parseCall(toks, j)
+			a := pyAttach{group: recv, fn: firstIdentArg(toks, j), name: args.kwString("name")}
+			if len(args.strings) > 0 {
+				a.name = args.strings[0]
+			}
+			st.attach = append(st.attach, a)
+		}
+
+	}
+}
+
+// define records a decorated function if it is a click command or group.
+func (st *pythonState) define(fn string, decs []pyDecorator) {
+	for _, d := range decs {
+		recv, method := splitDotted(d.name)
+		if method != "command" && method != "group" {
+			continue
+		}
+
+		name := d.args.kwString("name")
+		if name == "" && len(d.args.strings) > 0 {
+			name = d.args.strings[0]
+		}
+		if name == "" {
+			// click derives names from the function, dashing underscores.
+			name = strings.ReplaceAll(fn, "_", "-")
+		}
+
+		var p string
+		switch {
+		case recv == "" || recv == "click":
+			if method == "command" {
+				p = name
+				st.detached = append(st.detached, fn)
+			}
+			// A top-level group is the program itself and keeps the root path.
+		default:
+			parent, ok := st.groups[recv]
+			if !ok {
+				parent, ok = st.known[recv]
+			}
+			if !ok {
+				continue
+			}
+			p = joinPath(parent, name)
+		}
+
+		st.funcPaths[fn] = p
+		if method == "group" {
+			st.groups[fn] = p
+		}
+		st.tree.pos = skillmd.Pos{File: st.file, Line: d.line}
+		st.tree.add(p)
+		for _, o := range decs {
+			if o.name == "click.option" || o.name == "option" {
+				st.tree.pos.Line = o.line
+				st.tree.addFlag(p, o.args.optionStrings())
+			}
+		}
+		return
+	}
+}
+
+// finish applies deferred add_command calls and promotes a lone
+// ungrouped click command to the root.
+func (st *pythonState) finish() {
+	for _, a := range st.attach {
+		gp, ok := st.groups[a.group]
+		if !ok {
+			continue
+		}
+		old, ok := st.funcPaths[a.fn]
+		if !ok {
+			continue
+		}
+		name := a.name
+		if name == "" {
+			name = lastWord(old)
+		}
+		st.tree.move(old, joinPath(gp, name))
+		st.funcPaths[a.fn] = joinPath(gp, name)
+		st.detached = removeString(st.detached, a.fn)
+	}
+
+	if len(st.groups) == 0 && len(st.detached) == 1 {
+		st.tree.move(st.funcPaths[st.detached[0]], "")
+	}
+}
+
+// move renames a command path, merging into an existing entry if needed.
+func (t *commandTree) move(from, to string) {
+	if from == to {
+		return
+	}
+	c, ok := t.cmds[from]
+	if !ok {
+		return
+	}
+	delete(t.cmds, from)
+	t.pos = c.Pos
+	t.add(to)
+	for _, f := range c.Flags {
+		t.pos = f.Pos
+		t.addFlag(to, flagAliases(f.Name))
+	}
+}
+
+// dottedName reads "a.b.c" starting at toks[i] and returns it with the
+// index of the first token after it.
+func dottedName(toks []token, i int) (string, int) {
+	parts := []string{toks[i].text}
+	j := i + 1
+	for j+1 < len(toks) && toks[j].text == "." && toks[j+1].kind == tokIdent {
+		parts = append(parts, toks[j+1].text)
+		j += 2
+	}
+	return strings.Join(parts, "."), j
+}
+
+// methodCall matches "recv.method(" or "module.Class(" at toks[i]. It
+// returns the receiver, the method name and the index of "(".
+func methodCall(toks []token, i int) (string, string, int) {
+	if i >= len(toks) || toks[i].kind != tokIdent {
+		return "", "", i
+	}
+	name, j := dottedName(toks, i)
+	if j >= len(toks) || toks[j].text != "(" {
+		return "", "", i
+	}
+	recv, method := splitDotted(name)
+	return recv, method, j
+}
+
+func splitDotted(name string) (string, string) {
+	if k := strings.LastIndex(name, "."); k >= 0 {
+		return name[:k], name[k+1:]
+	}
+	return "", name
+}
+
+// firstIdentArg returns the identifier passed as the first argument of the
+// call opening at toks[i].
+func firstIdentArg(toks []token, i int) string {
+	if i+1 < len(toks) && toks[i+1].kind == tokIdent {
+		return toks[i+1].text
+	}
+	return ""
+}
+
+func lastWord(s string) string {
+	words := strings.Fields(s)
+	if len(words) == 0 {
+		return ""
+	}
+	return words[len(words)-1]
+}
+
+func removeString(list []string, s string) []string {
+	var out []string
+	for _, v := range list {
+		if v != s {
+			out = append(out, v)
+		}
+	}
+	return out
+}
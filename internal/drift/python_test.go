@@ -0,0 +1,244 @@
+package drift
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/ppiankov/ancc/internal/skillmd"
+)
+
+func commandMap(cmds []skillmd.Command) map[string][]string {
+	out := make(map[string][]string, len(cmds))
+	for _, c := range cmds {
+		var fs []string
+		for _, f := range c.Flags {
+			fs = append(fs, f.Name)
+		}
+		out[c.Name] = fs
+	}
+	return out
+}
+
+func assertCommands(t *testing.T, got []skillmd.Command, want map[string][]string) {
+	t.Helper()
+	m := commandMap(got)
+	if len(m) != len(want) {
+		t.Errorf("got commands %v, want %v", m, want)
+	}
+	for name, wantFlags := range want {
+		gotFlags, ok := m[name]
+		if !ok {
+			t.Errorf("missing command %q (got %v)", name, m)
+			continue
+		}
+		if len(gotFlags) != len(wantFlags) {
+			t.Errorf("command %q flags = %v, want %v", name, gotFlags, wantFlags)
+			continue
+		}
+		for i := range wantFlags {
+			if gotFlags[i] != wantFlags[i] {
+				t.Errorf("command %q flag[%d] = %q, want %q", name, i, gotFlags[i], wantFlags[i])
+			}
+		}
+	}
+}
+
+func TestPythonDetect(t *testing.T) {
+	a := pythonAnalyzer{}
+	if !a.Detect(fstest.MapFS{"pyproject.toml": {}}) {
+		t.Error("expected pyproject.toml to be detected")
+	}
+	if !a.Detect(fstest.MapFS{"setup.cfg": {}}) {
+		t.Error("expected setup.cfg to be detected")
+	}
+	if a.Detect(fstest.MapFS{"go.mod": {}}) {
+		t.Error("expected go.mod not to be detected")
+	}
+	if Detect(fstest.MapFS{"pyproject.toml": {}}) == nil {
+		t.Error("expected Detect to select the python analyzer")
+	}
+}
+
+func TestPythonAnalyze_Argparse(t *testing.T) {
+	src := `import argparse
+
+def main():
+    parser = argparse.ArgumentParser(prog="mytool")
+    parser.add_argument("--verbose", "-v", action="store_true")
+    sub = parser.add_subparsers(dest="cmd")
+
+    run = sub.add_parser("run", help="run things")
+    run.add_argument(
+        "--format",
+        choices=["text", "json"],  # comment with "--fake"
+    )
+    run.add_argument("path")
+
+    cfg = sub.add_parser('config')
+    cfg_sub = cfg.add_subparsers()
+    cfg_set = cfg_sub.add_parser("set")
+    grp = cfg_set.add_mutually_exclusive_group()
+    grp.add_argument("--global", dest="glob")
+
+    doc = """
+    parser.add_argument("--not-real")
+    """
+`
+	fsys := fstest.MapFS{
+		"pyproject.toml":  {},
+		"mytool/cli.py":   {Data: []byte(src)},
+		"tests/test_x.py": {Data: []byte(`p = argparse.ArgumentParser()` + "\n" + `p.add_argument("--test-only")`)},
+	}
+
+	cmds, err := pythonAnalyzer{}.Analyze(fsys)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertCommands(t, cmds, map[string][]string{
+		"":           {"--verbose, -v"},
+		"run":        {"--format"},
+		"config":     nil,
+		"config set": {"--global"},
+	})
+}
+
+func TestPythonAnalyze_Click(t *testing.T) {
+	cli := `import click
+
+@click.group()
+@click.option("--debug/--no-debug")
+def cli():
+    pass
+
+@cli.command()
+@click.option("-f", "--format", type=click.Choice(["text", "json"]))
+@click.argument("path")
+def run_all(format, path):
+    pass
+
+@cli.group(name="config")
+def config_group():
+    pass
+`
+	// Commands in a module that sorts before the group definition.
+	cmds := `from .cli import config_group, cli
+import click
+
+@config_group.command("set")
+@click.option("--global", "is_global", is_flag=True)
+def set_value(is_global):
+    pass
+
+@click.command()
+def status():
+    pass
+
+cli.add_command(status, name="state")
+`
+	fsys := fstest.MapFS{
+		"setup.cfg":         {},
+		"pkg/a_commands.py": {Data: []byte(cmds)},
+		"pkg/cli.py":        {Data: []byte(cli)},
+	}
+
+	got, err := pythonAnalyzer{}.Analyze(fsys)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertCommands(t, got, map[string][]string{
+		"":           {"--debug, --no-debug"},
+		"run-all":    {"-f, --format"},
+		"config":     nil,
+		"config set": {"--global"},
+		"state":      nil,
+	})
+}
+
+func TestPythonAnalyze_SingleClickCommand(t *testing.T) {
+	src := `import click
+
+@click.command()
+@click.option("--count", default=1)
+def hello(count):
+    pass
+`
+	got, err := pythonAnalyzer{}.Analyze(fstest.MapFS{"pyproject.toml": {}, "hello.py": {Data: []byte(src)}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertCommands(t, got, map[string][]string{"": {"--count"}})
+}
+
+func TestTokenizePython_Strings(t *testing.T) {
+	src := "x = r'a\\b' + b\"c\" # '--no'\ny = '''multi\nline'''\nz = f\"{v}\""
+	toks := tokenizePython(src)
+
+	var strs []string
+	for _, tok := range toks {
+		if tok.kind == tokString {
+			strs = append(strs, tok.text)
+		}
+	}
+	want := []string{`a\b`, "c", "multi\nline", "{v}"}
+	if len(strs) != len(want) {
+		t.Fatalf("strings = %q, want %q", strs, want)
+	}
+	for i := range want {
+		if strs[i] != want[i] {
+			t.Errorf("strings[%d] = %q, want %q", i, strs[i], want[i])
+		}
+	}
+
+	last := toks[len(toks)-1]
+	if last.line != 4 {
+		t.Errorf("last token line = %d, want 4", last.line)
+	}
+}
+
+func TestPythonAnalyze_Positions(t *testing.T) {
+	src := `import argparse
+
+parser = argparse.ArgumentParser(prog="mytool")
+sub = parser.add_subparsers()
+run = sub.add_parser("run")
+run.add_argument("--format")
+`
+	click := `import click
+
+@click.group()
+def cli():
+    pass
+
+@cli.command()
+@click.option("--dry-run")
+def deploy(dry_run):
+    pass
+`
+	fsys := fstest.MapFS{
+		"pyproject.toml":   {},
+		"mytool/cli.py":    {Data: []byte(src)},
+		"mytool/deploy.py": {Data: []byte(click)},
+	}
+	cmds, err := pythonAnalyzer{}.Analyze(fsys)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := map[string]skillmd.Pos{}
+	for _, c := range cmds {
+		got[c.Name] = c.Pos
+		for _, f := range c.Flags {
+			got[c.Name+" "+f.Name] = f.Pos
+		}
+	}
+	want := map[string]skillmd.Pos{
+		"run":              {File: "mytool/cli.py", Line: 5},
+		"run --format":     {File: "mytool/cli.py", Line: 6},
+		"deploy":           {File: "mytool/deploy.py", Line: 7},
+		"deploy --dry-run": {File: "mytool/deploy.py", Line: 8},
+	}
+	for k, w := range want {
+		if got[k] != w {
+			t.Errorf("%s at %+v, want %+v", k, got[k], w)
+		}
+	}
+}
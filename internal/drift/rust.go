@@ -0,0 +1,772 @@
+package drift
+
+import (
+	"io/fs"
+	"path"
+	"strings"
+	"unicode"
+
+	"github.com/ppiankov/ancc/internal/skillmd"
+)
+
+// rustAnalyzer recovers clap derive-API command trees from Rust source
+// without invoking rustc or cargo.
+type rustAnalyzer struct{}
+
+func (rustAnalyzer) Name() string { return "rust (clap)" }
+
+func (rustAnalyzer) Detect(fsys fs.FS) bool {
+	return fileExists(fsys, "Cargo.toml")
+}
+
+// rustSkipDirs are never scanned: build output and non-binary sources.
+var rustSkipDirs = map[string]bool{
+	".git":     true,
+	"target":   true,
+	"tests":    true,
+	"benches":  true,
+	"examples": true,
+	"fuzz":     true,
+}
+
+// maxRustDepth bounds recursion through nested subcommand types, which
+// also protects against self-referential definitions.
+const maxRustDepth = 16
+
+func (rustAnalyzer) Analyze(fsys fs.FS) ([]skillmd.Command, error) {
+	items := map[string]*rustItem{}
+	var roots []*rustItem
+
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != "." && rustSkipDirs[d.Name()] {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		// Only crate sources: src/**/*.rs, including workspace members.
+		if path.Ext(p) != ".rs" || !strings.Contains("/"+p, "/src/") {
+			return nil
+		}
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		for _, it := range parseRustItems(tokenizeRust(string(data))) {
+			it.file = p
+			items[it.name] = it
+			if it.derives("Parser") {
+				roots = append(roots, it)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	b := &rustBuilder{tree: newCommandTree(), items: items}
+	for _, r := range roots {
+		b.tree.pos = skillmd.Pos{File: r.file, Line: r.line}
+		b.tree.add("")
+		b.addArgs(r, "", 0)
+	}
+	return b.tree.commands(), nil
+}
+
+// --- Tokenizer ---
+
+// tokenizeRust splits Rust source into identifiers, string and char
+// literals, numbers and single-character punctuation. Comments (including
+// nested block comments) and lifetimes are dropped.
+func tokenizeRust(src string) []token {
+	var toks []token
+	line := 1
+	i := 0
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "/*"):
+			depth := 0
+			for i < len(src) {
+				switch {
+				case strings.HasPrefix(src[i:], "/*"):
+					depth++
+					i += 2
+				case strings.HasPrefix(src[i:], "*/"):
+					depth--
+					i += 2
+				default:
+					if src[i] == '\n' {
+						line++
+					}
+					i++
+				}
+				if depth == 0 {
+					break
+				}
+			}
+		case c == '"':
+			val, n, lines := scanRustString(src[i+1:])
+			toks = append(toks, token{kind: tokString, text: val, line: line})
+			line += lines
+			i += 1 + n
+		case c == '\'':
+			// Char literal ('f', '\n') or lifetime ('a, 'static).
+			if n := rustCharLen(src[i:]); n > 0 {
+				toks = append(toks, token{kind: tokString, text: unescapeRustChar(src[i+1 : i+n-1]), line: line})
+				i += n
+				continue
+			}
+			i++
+			for i < len(src) && isIdentChar(src[i]) {
+				i++
+			}
+		case isIdentStart(c):
+			j := i
+			for j < len(src) && isIdentChar(src[j]) {
+				j++
+			}
+			word := src[i:j]
+			// Raw and byte strings: r"..", r#".."#, b"..", br#".."#.
+			if (word == "r" || word == "br") && j < len(src) && (src[j] == '"' || src[j] == '#') {
+				if val, n, lines, ok := scanRawString(src[j:]); ok {
+					toks = append(toks, token{kind: tokString, text: val, line: line})
+					line += lines
+					i = j + n
+					continue
+				}
+			}
+			if word == "b" && j < len(src) && src[j] == '"' {
+				val, n, lines := scanRustString(src[j+1:])
+				toks = append(toks, token{kind: tokString, text: val, line: line})
+				line += lines
+				i = j + 1 + n
+				continue
+			}
+			toks = append(toks, token{kind: tokIdent, text: word, line: line})
+			i = j
+		case c >= '0' && c <= '9':
+			j := i
+			for j < len(src) && isIdentChar(src[j]) {
+				j++
+			}
+			toks = append(toks, token{kind: tokNumber, text: src[i:j], line: line})
+			i = j
+		default:
+			toks = append(toks, token{kind: tokOp, text: string(c), line: line})
+			i++
+		}
+	}
+	return toks
+}
+
+// scanRustString reads a quoted string body (after the opening quote) and
+// returns the value, bytes consumed including the closing quote, and the
+// newlines crossed.
+func scanRustString(s string) (string, int, int) {
+	var b strings.Builder
+	lines := 0
+	i := 0
+	for i < len(s) {
+		c := s[i]
+		switch {
+		case c == '"':
+			return b.String(), i + 1, lines
+		case c == '\\' && i+1 < len(s):
+			if s[i+1] == '\n' {
+				lines++
+			}
+			b.WriteString(unescapeRustChar(s[i : i+2]))
+			i += 2
+			continue
+		case c == '\n':
+			lines++
+		}
+		b.WriteByte(c)
+		i++
+	}
+	return b.String(), i, lines
+}
+
+// scanRawString reads r#"..."# starting at the first '#' or '"'.
+func scanRawString(s string) (string, int, int, bool) {
+	hashes := 0
+	for hashes < len(s) && s[hashes] == '#' {
+		hashes++
+	}
+	if hashes >= len(s) || s[hashes] != '"' {
+		return "", 0, 0, false
+	}
+	end := "\"" + strings.Repeat("#", hashes)
+	body := s[hashes+1:]
+	k := strings.Index(body, end)
+	if k < 0 {
+		return body, len(s), strings.Count(body, "\n"), true
+	}
+	return body[:k], hashes + 1 + k + len(end), strings.Count(body[:k], "\n"), true
+}
+
+// rustCharLen returns the length of a char literal at the start of s, or 0
+// if s starts a lifetime instead.
+func rustCharLen(s string) int {
+	if len(s) < 3 {
+		return 0
+	}
+	if s[1] == '\\' {
+		if k := strings.IndexByte(s[2:], '\''); k >= 0 && k < 10 {
+			return k + 3
+		}
+		return 0
+	}
+	// Multi-byte UTF-8 chars are up to four bytes long.
+	for n := 1; n <= 4 && n+1 < len(s); n++ {
+		if s[n+1] == '\'' {
+			return n + 2
+		}
+		if s[n] < 0x80 {
+			break
+		}
+	}
+	return 0
+}
+
+func unescapeRustChar(s string) string {
+	if len(s) < 2 || s[0] != '\\' {
+		return s
+	}
+	switch s[1] {
+	case 'n':
+		return "\n"
+	case 't':
+		return "\t"
+	case 'r':
+		return "\r"
+	case '0':
+		return "\x00"
+	}
+	return s[1:]
+}
+
+// --- Item parsing ---
+
+// rustAttrArg is one entry of an attribute list: "long", `long = "out"`
+// or `name("x")`.
+type rustAttrArg struct {
+	key   string
+	value string
+}
+
+// rustAttr is a parsed #[name(args)] attribute.
+type rustAttr struct {
+	name string
+	args []rustAttrArg
+}
+
+// rustField is a named field of a struct or struct-like variant.
+type rustField struct {
+	name  string
+	typ   []string // identifiers in the type, outermost first
+	attrs []rustAttr
+	line  int
+}
+
+// rustVariant is one enum variant.
+type rustVariant struct {
+	name   string
+	line   int
+	attrs  []rustAttr
+	tuple  []string    // type identifiers of a single-field tuple variant
+	fields []rustField // struct variant fields
+}
+
+// rustItem is a struct or enum with its attributes.
+type rustItem struct {
+	name     string
+	file     string // path of the source file declaring it
+	line     int
+	attrs    []rustAttr
+	fields   []rustField
+	variants []rustVariant
+	isEnum   bool
+}
+
+func (it *rustItem) derives(trait string) bool {
+	for _, a := range it.attrs {
+		if a.name != "derive" {
+			continue
+		}
+		for _, arg := range a.args {
+			if arg.key == trait || strings.HasSuffix(arg.key, "::"+trait) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseRustItems finds struct and enum definitions with their attributes.
+func parseRustItems(toks []token) []*rustItem {
+	var items []*rustItem
+	var attrs []rustAttr
+	for i := 0; i < len(toks); i++ {
+		t := toks[i]
+		if isOp(t, "#") {
+			if a, j, ok := parseRustAttr(toks, i); ok {
+				attrs = append(attrs, a)
+				i = j - 1
+				continue
+			}
+		}
+		if t.kind != tokIdent {
+			continue
+		}
+		switch t.text {
+		case "pub", "crate", "in", "super", "self":
+			// Visibility modifiers keep pending attributes.
+		case "struct", "enum":
+			if i+1 >= len(toks) || toks[i+1].kind != tokIdent {
+				attrs = nil
+				continue
+			}
+			it := &rustItem{name: toks[i+1].text, line: toks[i+1].line, attrs: attrs, isEnum: t.text == "enum"}
+			attrs = nil
+			j := skipGenerics(toks, i+2)
+			if j < len(toks) && isOp(toks[j], "{") {
+				end := matchClose(toks, j)
+				if it.isEnum {
+					it.variants = parseRustVariants(toks[j+1 : end])
+				} else {
+					it.fields = parseRustFields(toks[j+1 : end])
+				}
+				i = end
+			}
+			items = append(items, it)
+		default:
+			attrs = nil
+		}
+	}
+	return items
+}
+
+// parseRustAttr parses an outer attribute starting at toks[i] == "#".
+func parseRustAttr(toks []token, i int) (rustAttr, int, bool) {
+	if i+1 >= len(toks) || !isOp(toks[i+1], "[") {
+		return rustAttr{}, i + 1, false
+	}
+	end := matchClose(toks, i+1)
+	body := toks[i+2 : end]
+	if len(body) == 0 || body[0].kind != tokIdent {
+		return rustAttr{}, end + 1, false
+	}
+
+	name, k := rustPath(body, 0)
+	a := rustAttr{name: name}
+	if k < len(body) && isOp(body[k], "(") {
+		a.args = parseRustAttrArgs(body[k+1 : matchClose(body, k)])
+	}
+	return a, end + 1, true
+}
+
+// parseRustAttrArgs splits attribute arguments on top-level commas.
+func parseRustAttrArgs(toks []token) []rustAttrArg {
+	var out []rustAttrArg
+	for _, part := range splitTopLevel(toks) {
+		if len(part) == 0 || part[0].kind != tokIdent {
+			continue
+		}
+		key, k := rustPath(part, 0)
+		arg := rustAttrArg{key: key}
+		rest := part[k:]
+		switch {
+		case len(rest) >= 2 && isOp(rest[0], "="):
+			arg.value = joinTokens(rest[1:])
+		case len(rest) >= 2 && isOp(rest[0], "("):
+			arg.value = joinTokens(rest[1:matchClose(rest, 0)])
+		}
+		out = append(out, arg)
+	}
+	return out
+}
+
+// parseRustFields parses "attrs name: Type," sequences.
+func parseRustFields(toks []token) []rustField {
+	var out []rustField
+	for _, part := range splitTopLevel(toks) {
+		attrs, k := leadingAttrs(part)
+		k = skipVisibility(part, k)
+		if k+1 >= len(part) || part[k].kind != tokIdent || !isOp(part[k+1], ":") {
+			continue
+		}
+		out = append(out, rustField{name: part[k].text, attrs: attrs, typ: typeIdents(part[k+2:]), line: part[k].line})
+	}
+	return out
+}
+
+// parseRustVariants parses enum variants: unit, tuple and struct forms.
+func parseRustVariants(toks []token) []rustVariant {
+	var out []rustVariant
+	for _, part := range splitTopLevel(toks) {
+		attrs, k := leadingAttrs(part)
+		if k >= len(part) || part[k].kind != tokIdent {
+			continue
+		}
+		v := rustVariant{name: part[k].text, attrs: attrs, line: part[k].line}
+		if k+1 < len(part) {
+			switch {
+			case isOp(part[k+1], "("):
+				v.tuple = typeIdents(part[k+2 : matchClose(part, k+1)])
+			case isOp(part[k+1], "{"):
+				v.fields = parseRustFields(part[k+2 : matchClose(part, k+1)])
+			}
+		}
+		out = append(out, v)
+	}
+	return out
+}
+
+// leadingAttrs consumes #[...] attributes at the start of toks.
+func leadingAttrs(toks []token) ([]rustAttr, int) {
+	var attrs []rustAttr
+	k := 0
+	for k < len(toks) && isOp(toks[k], "#") {
+		a, j, ok := parseRustAttr(toks, k)
+		if ok {
+			attrs = append(attrs, a)
+		}
+		k = j
+	}
+	return attrs, k
+}
+
+func skipVisibility(toks []token, k int) int {
+	if k < len(toks) && toks[k].kind == tokIdent && toks[k].text == "pub" {
+		k++
+		if k < len(toks) && isOp(toks[k], "(") {
+			k = matchClose(toks, k) + 1
+		}
+	}
+	return k
+}
+
+// skipGenerics skips a <...> parameter list and any where clause up to "{" or ";".
+func skipGenerics(toks []token, i int) int {
+	for i < len(toks) && !isOp(toks[i], "{") && !isOp(toks[i], ";") && !isOp(toks[i], "(") {
+		i++
+	}
+	return i
+}
+
+// splitTopLevel splits tokens on commas outside any brackets.
+func splitTopLevel(toks []token) [][]token {
+	var parts [][]token
+	depth := 0
+	start := 0
+	for i, t := range toks {
+		if t.kind != tokOp {
+			continue
+		}
+		switch t.text {
+		case "(", "[", "{", "<":
+			depth++
+		case ")", "]", "}", ">":
+			// "->" in fn types is not a closing bracket.
+			if t.text == ">" && i > 0 && isOp(toks[i-1], "-") {
+				continue
+			}
+			depth--
+		case ",":
+			if depth == 0 {
+				parts = append(parts, toks[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if start < len(toks) {
+		parts = append(parts, toks[start:])
+	}
+	return parts
+}
+
+// matchClose returns the index of the bracket closing toks[i].
+func matchClose(toks []token, i int) int {
+	depth := 0
+	for j := i; j < len(toks); j++ {
+		if toks[j].kind != tokOp {
+			continue
+		}
+		switch toks[j].text {
+		case "(", "[", "{":
+			depth++
+		case ")", "]", "}":
+			depth--
+			if depth == 0 {
+				return j
+			}
+		}
+	}
+	return len(toks)
+}
+
+// rustPath reads a path such as clap::Parser starting at toks[i].
+func rustPath(toks []token, i int) (string, int) {
+	parts := []string{toks[i].text}
+	j := i + 1
+	for j+2 < len(toks) && isOp(toks[j], ":") && isOp(toks[j+1], ":") && toks[j+2].kind == tokIdent {
+		parts = append(parts, toks[j+2].text)
+		j += 3
+	}
+	return strings.Join(parts, "::"), j
+}
+
+func typeIdents(toks []token) []string {
+	var out []string
+	for _, t := range toks {
+		if t.kind == tokIdent {
+			out = append(out, t.text)
+		}
+	}
+	return out
+}
+
+func joinTokens(toks []token) string {
+	var parts []string
+	for _, t := range toks {
+		parts = append(parts, t.text)
+	}
+	return strings.Join(parts, "")
+}
+
+func isOp(t token, s string) bool {
+	return t.kind == tokOp && t.text == s
+}
+
+// --- Command tree ---
+
+// rustBuilder turns parsed items into command paths and flags.
+type rustBuilder struct {
+	tree  *commandTree
+	items map[string]*rustItem
+}
+
+// clapAttrs are the attribute names clap's derive macros read, across
+// clap 3 and 4.
+var clapAttrs = map[string]bool{"arg": true, "command": true, "clap": true, "structopt": true}
+
+// attrValue returns the value of key in any clap attribute, and whether
+// the key is present at all.
+func attrValue(attrs []rustAttr, key string) (string, bool) {
+	for _, a := range attrs {
+		if !clapAttrs[a.name] {
+			continue
+		}
+		for _, arg := range a.args {
+			if arg.key == key {
+				return arg.value, true
+			}
+		}
+	}
+	return "", false
+}
+
+// addArgs records the fields of an Args or Parser struct at path.
+func (b *rustBuilder) addArgs(it *rustItem, p string, depth int) {
+	if depth > maxRustDepth {
+		return
+	}
+	rename := renameRule(it.attrs, "kebab-case")
+	b.addFields(it.file, it.fields, p, rename, depth)
+}
+
+// addFields records fields declared in file at path p.
+func (b *rustBuilder) addFields(file string, fields []rustField, p, rename string, depth int) {
+	for _, f := range fields {
+		if _, ok := attrValue(f.attrs, "skip"); ok {
+			continue
+		}
+		if _, ok := attrValue(f.attrs, "subcommand"); ok {
+			if sub := b.lookup(f.typ); sub != nil {
+				b.addSubcommands(sub, p, depth+1)
+			}
+			continue
+		}
+		if _, ok := attrValue(f.attrs, "flatten"); ok {
+			if inner := b.lookup(f.typ); inner != nil {
+				b.addArgs(inner, p, depth+1)
+			}
+			continue
+		}
+
+		var opts []string
+		if v, ok := attrValue(f.attrs, "short"); ok {
+			if v == "" {
+				v = string(f.name[0])
+			}
+			opts = append(opts, "-"+v)
+		}
+		if v, ok := attrValue(f.attrs, "long"); ok {
+			if v == "" {
+				v = applyRename(f.name, rename)
+			}
+			opts = append(opts, "--"+v)
+		}
+		b.tree.pos = skillmd.Pos{File: file, Line: f.line}
+		b.tree.addFlag(p, opts)
+	}
+}
+
+// addSubcommands records the variants of a Subcommand enum under parent.
+func (b *rustBuilder) addSubcommands(it *rustItem, parent string, depth int) {
+	if depth > maxRustDepth || !it.isEnum {
+		return
+	}
+	rename := renameRule(it.attrs, "kebab-case")
+	for _, v := range it.variants {
+		if _, ok := attrValue(v.attrs, "skip"); ok {
+			continue
+		}
+		if _, ok := attrValue(v.attrs, "external_subcommand"); ok {
+			continue
+		}
+		if _, ok := attrValue(v.attrs, "flatten"); ok {
+			if inner := b.lookup(v.tuple); inner != nil {
+				b.addSubcommands(inner, parent, depth+1)
+			}
+			continue
+		}
+
+		name, ok := attrValue(v.attrs, "name")
+		if !ok || name == "" {
+			name = applyRename(v.name, rename)
+		}
+		p := joinPath(parent, name)
+		b.tree.pos = skillmd.Pos{File: it.file, Line: v.line}
+		b.tree.add(p)
+
+		switch {
+		case len(v.fields) > 0:
+			b.addFields(it.file, v.fields, p, renameRule(v.attrs, "kebab-case"), depth+1)
+		case len(v.tuple) > 0:
+			inner := b.lookup(v.tuple)
+			if inner == nil {
+				continue
+			}
+			if inner.isEnum {
+				b.addSubcommands(inner, p, depth+1)
+			} else {
+				b.addArgs(inner, p, depth+1)
+			}
+		}
+	}
+}
+
+// lookup finds the innermost known item named in a type such as
+// Option<Box<Commands>>.
+func (b *rustBuilder) lookup(typ []string) *rustItem {
+	for i := len(typ) - 1; i >= 0; i-- {
+		if it, ok := b.items[typ[i]]; ok {
+			return it
+		}
+	}
+	return nil
+}
+
+// renameRule returns the rename_all case for an item, or def.
+func renameRule(attrs []rustAttr, def string) string {
+	if v, ok := attrValue(attrs, "rename_all"); ok && v != "" {
+		return v
+	}
+	return def
+}
+
+// applyRename converts a Rust identifier (snake_case field or PascalCase
+// variant) using one of clap's rename_all rules.
+func applyRename(ident, rule string) string {
+	words := splitIdent(ident)
+	switch rule {
+	case "verbatim", "Verbatim":
+		return ident
+	case "snake_case", "snake":
+		return strings.Join(lowerAll(words), "_")
+	case "SCREAMING_SNAKE_CASE", "UPPER_SNAKE_CASE", "screaming_snake":
+		return strings.ToUpper(strings.Join(words, "_"))
+	case "lower", "lowercase":
+		return strings.ToLower(strings.Join(words, ""))
+	case "UPPER", "UPPERCASE":
+		return strings.ToUpper(strings.Join(words, ""))
+	case "camelCase", "camel":
+		out := strings.ToLower(words[0])
+		for _, w := range words[1:] {
+			out += title(w)
+		}
+		return out
+	case "PascalCase", "pascal":
+		var out string
+		for _, w := range words {
+			out += title(w)
+		}
+		return out
+	case "SCREAMING-KEBAB-CASE", "screaming_kebab":
+		return strings.ToUpper(strings.Join(words, "-"))
+	}
+	return strings.Join(lowerAll(words), "-")
+}
+
+// splitIdent splits snake_case and PascalCase identifiers into words.
+func splitIdent(ident string) []string {
+	var words []string
+	var cur []rune
+	runes := []rune(ident)
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '-':
+			if len(cur) > 0 {
+				words = append(words, string(cur))
+				cur = nil
+			}
+			continue
+		case unicode.IsUpper(r) && len(cur) > 0:
+			prevLower := unicode.IsLower(cur[len(cur)-1]) || unicode.IsDigit(cur[len(cur)-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || nextLower {
+				words = append(words, string(cur))
+				cur = nil
+			}
+		}
+		cur = append(cur, r)
+	}
+	if len(cur) > 0 {
+		words = append(words, string(cur))
+	}
+	if len(words) == 0 {
+		return []string{ident}
+	}
+	return words
+}
+
+func lowerAll(words []string) []string {
+	out := make([]string, len(words))
+	for i, w := range words {
+		out[i] = strings.ToLower(w)
+	}
+	return out
+}
+
+func title(w string) string {
+	if w == "" {
+		return w
+	}
+	return strings.ToUpper(w[:1]) + strings.ToLower(w[1:])
+}
@@ -0,0 +1,183 @@
+package drift
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+const clapMain = `use clap::{Args, Parser, Subcommand};
+
+/// Top-level CLI.
+#[derive(Debug, Parser)]
+#[command(name = "mytool", version)]
+struct Cli {
+    /// Emit debug logs.
+    #[arg(short, long, global = true)]
+    verbose: bool,
+
+    #[command(flatten)]
+    output: OutputOpts,
+
+    #[command(subcommand)]
+    command: Option<Commands>,
+}
+
+#[derive(Args, Debug)]
+struct OutputOpts {
+    #[arg(long = "format", value_enum, default_value_t = Format::Text)]
+    fmt: Format,
+}
+
+#[derive(Subcommand, Debug)]
+enum Commands {
+    /// Run things.
+    RunAll(RunArgs),
+    #[command(name = "cfg")]
+    Config {
+        #[command(subcommand)]
+        action: ConfigAction,
+    },
+    Doctor,
+    #[command(skip)]
+    Hidden,
+}
+
+#[derive(Args, Debug)]
+#[command(rename_all = "snake_case")]
+pub(crate) struct RunArgs {
+    #[arg(long)]
+    dry_run: bool,
+    #[clap(short = 'n', long)]
+    max_items: Option<usize>,
+    /// Positional path, not a flag.
+    path: String,
+}
+`
+
+const clapConfig = `use clap::Subcommand;
+
+// #[derive(Subcommand)] enum Commented {}
+
+#[derive(Subcommand, Debug)]
+#[command(rename_all = "lower")]
+pub enum ConfigAction {
+    SetValue {
+        #[arg(long, help = r#"the "key""#)]
+        key_name: String,
+    },
+    ShowAll,
+}
+
+fn helper<'a>(s: &'a str) -> char {
+    let _ = '\'';
+    'x'
+}
+`
+
+func TestRustDetect(t *testing.T) {
+	if !(rustAnalyzer{}).Detect(fstest.MapFS{"Cargo.toml": {}}) {
+		t.Error("expected Cargo.toml to be detected")
+	}
+	if (rustAnalyzer{}).Detect(fstest.MapFS{"go.mod": {}}) {
+		t.Error("expected go.mod not to be detected")
+	}
+	if _, ok := Detect(fstest.MapFS{"Cargo.toml": {}}).(rustAnalyzer); !ok {
+		t.Error("expected Detect to select the rust analyzer")
+	}
+}
+
+func TestRustAnalyze_Derive(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Cargo.toml":            {},
+		"src/main.rs":           {Data: []byte(clapMain)},
+		"src/cli/config.rs":     {Data: []byte(clapConfig)},
+		"tests/integration.rs":  {Data: []byte(`#[derive(Parser)] struct T { #[arg(long)] only_in_tests: bool }`)},
+		"target/debug/build.rs": {Data: []byte(`#[derive(Parser)] struct B { #[arg(long)] built: bool }`)},
+	}
+
+	cmds, err := rustAnalyzer{}.Analyze(fsys)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertCommands(t, cmds, map[string][]string{
+		"":             {"-v, --verbose", "--format"},
+		"run-all":      {"--dry_run", "-n, --max_items"},
+		"cfg":          nil,
+		"cfg setvalue": {"--key-name"},
+		"cfg showall":  nil,
+		"doctor":       nil,
+	})
+}
+
+func TestApplyRename(t *testing.T) {
+	tests := []struct {
+		ident string
+		rule  string
+		want  string
+	}{
+		{"RunAll", "kebab-case", "run-all"},
+		{"dry_run", "kebab-case", "dry-run"},
+		{"HTTPServer", "kebab-case", "http-server"},
+		{"RunAll", "snake_case", "run_all"},
+		{"dry_run", "camelCase", "dryRun"},
+		{"dry_run", "PascalCase", "DryRun"},
+		{"RunAll", "SCREAMING_SNAKE_CASE", "RUN_ALL"},
+		{"RunAll", "lower", "runall"},
+		{"RunAll", "verbatim", "RunAll"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ident+"/"+tt.rule, func(t *testing.T) {
+			if got := applyRename(tt.ident, tt.rule); got != tt.want {
+				t.Errorf("applyRename(%q, %q) = %q, want %q", tt.ident, tt.rule, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenizeRust_Literals(t *testing.T) {
+	src := "let a = \"x\\\"y\"; /* outer /* nested */ still */ let b = r#\"raw \"q\"\"#; let c: &'static str = 'z';"
+	var strs []string
+	for _, tok := range tokenizeRust(src) {
+		if tok.kind == tokString {
+			strs = append(strs, tok.text)
+		}
+		if tok.kind == tokIdent && (tok.text == "nested" || tok.text == "static") {
+			t.Errorf("unexpected identifier %q from comment or lifetime", tok.text)
+		}
+	}
+	want := []string{`x"y`, `raw "q"`, "z"}
+	if len(strs) != len(want) {
+		t.Fatalf("strings = %q, want %q", strs, want)
+	}
+	for i := range want {
+		if strs[i] != want[i] {
+			t.Errorf("strings[%d] = %q, want %q", i, strs[i], want[i])
+		}
+	}
+}
+
+func TestRustAnalyze_Positions(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Cargo.toml":        {},
+		"src/main.rs":       {Data: []byte(clapMain)},
+		"src/cli/config.rs": {Data: []byte(clapConfig)},
+	}
+	cmds, err := rustAnalyzer{}.Analyze(fsys)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, c := range cmds {
+		file := "src/main.rs"
+		if c.Name == "cfg setvalue" || c.Name == "cfg showall" {
+			file = "src/cli/config.rs"
+		}
+		if c.Pos.File != file || c.Pos.Line == 0 {
+			t.Errorf("%q at %+v, want a line of %s", c.Name, c.Pos, file)
+		}
+		for _, f := range c.Flags {
+			if f.Pos.File == "" || f.Pos.Line == 0 {
+				t.Errorf("%q %s has no position", c.Name, f.Name)
+			}
+		}
+	}
+}
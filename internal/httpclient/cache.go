@@ -0,0 +1,137 @@
+package httpclient
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// maxCachedBody bounds the responses kept in the cache. Archives and
+// release assets are larger and not worth revalidating.
+const maxCachedBody = 4 << 20
+
+// cacheTransport revalidates cached GET responses with If-None-Match. A
+// 304, which GitHub does not count against the rate limit, is answered
+// from the cache.
+type cacheTransport struct {
+	dir  string
+	next http.RoundTripper
+}
+
+// cacheEntry is a cached response, stored as JSON.
+type cacheEntry struct {
+	URL    string      `json:"url"`
+	ETag   string      `json:"etag"`
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("If-None-Match") != "" {
+		return t.next.RoundTrip(req)
+	}
+	path := t.path(req)
+	entry := t.load(path, req.URL.String())
+	if entry != nil {
+		req = req.Clone(req.Context())
+		req.Header.Set("If-None-Match", entry.ETag)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotModified && entry != nil {
+		_ = resp.Body.Close()
+		return entry.response(req), nil
+	}
+	etag := resp.Header.Get("ETag")
+	if resp.StatusCode != http.StatusOK || etag == "" || resp.ContentLength > maxCachedBody {
+		return resp, nil
+	}
+
+	// Buffer the body to store it, giving up once it outgrows the cache.
+	head, err := io.ReadAll(io.LimitReader(resp.Body, maxCachedBody+1))
+	if err != nil {
+		_ = resp.Body.Close()
+		return nil, err
+	}
+	if len(head) > maxCachedBody {
+		resp.Body = readCloser{io.MultiReader(bytes.NewReader(head), resp.Body), resp.Body}
+		return resp, nil
+	}
+	_ = resp.Body.Close()
+	t.store(path, &cacheEntry{URL: req.URL.String(), ETag: etag, Status: resp.StatusCode, Header: resp.Header, Body: head})
+	resp.Body = io.NopCloser(bytes.NewReader(head))
+	return resp, nil
+}
+
+// path keys an entry by URL, Accept and credentials: responses differ by
+// media type, and a token may see more than an anonymous request.
+func (t *cacheTransport) path(req *http.Request) string {
+	h := sha256.New()
+	for _, part := range []string{req.URL.String(), req.Header.Get("Accept"), req.Header.Get("Authorization"), req.Header.Get("PRIVATE-TOKEN")} {
+		_, _ = io.WriteString(h, part)
+		_, _ = h.Write([]byte{0})
+	}
+	return filepath.Join(t.dir, hex.EncodeToString(h.Sum(nil))+".json")
+}
+
+func (t *cacheTransport) load(path, url string) *cacheEntry {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var e cacheEntry
+	if json.Unmarshal(data, &e) != nil || e.URL != url || e.ETag == "" {
+		return nil
+	}
+	return &e
+}
+
+// store writes e atomically. The cache is best effort: failures are
+// ignored.
+func (t *cacheTransport) store(path string, e *cacheEntry) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(t.dir, 0o700); err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(t.dir, ".tmp-*")
+	if err != nil {
+		return
+	}
+	_, werr := tmp.Write(data)
+	cerr := tmp.Close()
+	if werr != nil || cerr != nil || os.Rename(tmp.Name(), path) != nil {
+		_ = os.Remove(tmp.Name())
+	}
+}
+
+func (e *cacheEntry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.Status, http.StatusText(e.Status)),
+		StatusCode:    e.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,90 @@
+package httpclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCache_Revalidates(t *testing.T) {
+	full, notModified := 0, 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full++
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Link", `<next>; rel="next"`)
+		_, _ = fmt.Fprint(w, `[{"tag_name":"v1"}]`)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	c, _ := newTestClient(Options{CacheDir: dir})
+	for i := 0; i < 3; i++ {
+		resp, body, err := get(t, c, srv.URL+"/releases")
+		if err != nil || resp.StatusCode != http.StatusOK || body != `[{"tag_name":"v1"}]` {
+			t.Fatalf("run %d: got %v %q %v", i, resp, body, err)
+		}
+		if resp.Header.Get("Link") != `<next>; rel="next"` {
+			t.Errorf("run %d: cached headers lost: %v", i, resp.Header)
+		}
+	}
+	if full != 1 || notModified != 2 {
+		t.Errorf("full = %d, not modified = %d; want 1 and 2", full, notModified)
+	}
+}
+
+func TestCache_KeyedByToken(t *testing.T) {
+	var conditional []bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conditional = append(conditional, r.Header.Get("If-None-Match") != "")
+		w.Header().Set("ETag", `"`+r.Header.Get("Authorization")+`"`)
+		_, _ = fmt.Fprint(w, r.Header.Get("Authorization"))
+	}))
+	defer srv.Close()
+
+	c, _ := newTestClient(Options{CacheDir: t.TempDir()})
+	for _, token := range []string{"Bearer a", "Bearer b", "Bearer a"} {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+		req.Header.Set("Authorization", token)
+		resp, err := c.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+	}
+	if fmt.Sprint(conditional) != "[false false true]" {
+		t.Errorf("conditional requests = %v; each token needs its own entry", conditional)
+	}
+}
+
+func TestCache_SkipsLargeAndUntagged(t *testing.T) {
+	large := strings.Repeat("x", maxCachedBody+1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/large" {
+			w.Header().Set("ETag", `"big"`)
+			_, _ = fmt.Fprint(w, large)
+			return
+		}
+		_, _ = fmt.Fprint(w, "no etag")
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	c, _ := newTestClient(Options{CacheDir: dir})
+	if _, body, err := get(t, c, srv.URL+"/large"); err != nil || len(body) != len(large) {
+		t.Fatalf("large body: %d bytes, %v", len(body), err)
+	}
+	if _, body, err := get(t, c, srv.URL+"/plain"); err != nil || body != "no etag" {
+		t.Fatalf("plain body: %q, %v", body, err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("cache holds %d entries, want none", len(entries))
+	}
+}
@@ -0,0 +1,268 @@
+package httpclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// A cassette is a directory of recorded HTTP interactions, one JSON file
+// per exchange, numbered in the order they happened: 0001.json, 0002.json.
+// Credentials are scrubbed before anything is written, so cassettes can be
+// committed as test fixtures.
+
+// Interaction is one recorded request and its response.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest identifies a request: replay matches on method, URL and
+// Accept header.
+type RecordedRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Accept string `json:"accept,omitempty"`
+}
+
+// RecordedResponse is a response as recorded. Body holds UTF-8 text;
+// binary bodies are stored in BodyBase64 instead.
+type RecordedResponse struct {
+	Status     int         `json:"status"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+	BodyBase64 []byte      `json:"body_base64,omitempty"`
+}
+
+var reInteraction = regexp.MustCompile(`^\d{4,}\.json$`)
+
+// redacted replaces scrubbed credentials.
+const redacted = "REDACTED"
+
+// secretParams are query parameters that carry credentials, such as the
+// short-lived tokens in archive redirect URLs and presigned storage URLs.
+var secretParams = []string{"token", "access_token", "private_token", "jwt", "x-amz-signature", "x-amz-credential", "x-amz-security-token", "sig", "signature"}
+
+// secretHeaders are response headers not worth recording.
+var secretHeaders = []string{"Set-Cookie", "Authorization", "Private-Token"}
+
+// scrubURL redacts credentials in the userinfo and query of u.
+func scrubURL(u string) string {
+	parsed, err := neturl.Parse(u)
+	if err != nil {
+		return u
+	}
+	if parsed.User != nil {
+		parsed.User = neturl.User(redacted)
+	}
+	if parsed.RawQuery != "" {
+		q := parsed.Query()
+		changed := false
+		for key := range q {
+			for _, secret := range secretParams {
+				if strings.EqualFold(key, secret) {
+					q.Set(key, redacted)
+					changed = true
+				}
+			}
+		}
+		if changed {
+			parsed.RawQuery = q.Encode()
+		}
+	}
+	return parsed.String()
+}
+
+// requestSecrets returns the credentials req carries, so a response echoing
+// them can be scrubbed.
+func requestSecrets(req *http.Request) []string {
+	var out []string
+	for _, h := range []string{"Authorization", "Private-Token"} {
+		v := req.Header.Get(h)
+		if _, token, ok := strings.Cut(v, " "); ok && h == "Authorization" {
+			v = token
+		}
+		if len(v) >= 8 {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func interactionKey(r RecordedRequest) string {
+	return r.Method + " " + r.URL + " " + r.Accept
+}
+
+func recordedRequest(req *http.Request) RecordedRequest {
+	return RecordedRequest{Method: req.Method, URL: scrubURL(req.URL.String()), Accept: req.Header.Get("Accept")}
+}
+
+// recorder writes every exchange that passes through it to a cassette.
+type recorder struct {
+	dir  string
+	next http.RoundTripper
+
+	mu sync.Mutex
+	n  int
+}
+
+// newRecorder starts a cassette in dir, which must not hold recordings yet.
+func newRecorder(dir string, next http.RoundTripper) (*recorder, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	for _, e := range entries {
+		if reInteraction.MatchString(e.Name()) {
+			return nil, fmt.Errorf("cassette %s already has recordings; remove them first", dir)
+		}
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &recorder{dir: dir, next: next}, nil
+}
+
+func (r *recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	header := resp.Header.Clone()
+	for _, h := range secretHeaders {
+		header.Del(h)
+	}
+	if loc := header.Get("Location"); loc != "" {
+		header.Set("Location", scrubURL(loc))
+	}
+	rec := RecordedResponse{Status: resp.StatusCode, Header: header}
+	if resp.StatusCode >= 300 && resp.StatusCode < 400 {
+		// Redirect bodies repeat the Location, token included.
+		body = nil
+	}
+	for _, secret := range requestSecrets(req) {
+		body = bytes.ReplaceAll(body, []byte(secret), []byte(redacted))
+	}
+	if utf8.Valid(body) {
+		rec.Body = string(body)
+	} else {
+		rec.BodyBase64 = body
+	}
+	if err := r.write(Interaction{Request: recordedRequest(req), Response: rec}); err != nil {
+		return nil, fmt.Errorf("recording %s: %w", req.URL, err)
+	}
+	// The live redirect keeps its token. On replay the client follows the
+	// scrubbed Location, which matches the next recorded request.
+	return resp, nil
+}
+
+func (r *recorder) write(in Interaction) error {
+	data, err := json.MarshalIndent(in, "", "  ")
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.n++
+	return os.WriteFile(filepath.Join(r.dir, fmt.Sprintf("%04d.json", r.n)), append(data, '\n'), 0o644)
+}
+
+// replayer answers requests from a cassette without touching the network.
+// Identical requests get their recorded responses in order; once those run
+// out, the last one repeats.
+type replayer struct {
+	dir string
+
+	mu        sync.Mutex
+	responses map[string][]RecordedResponse
+	served    map[string]int
+}
+
+// Replay returns a transport serving the cassette in dir.
+func Replay(dir string) (http.RoundTripper, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading cassette: %w", err)
+	}
+	var names []string
+	for _, e := range entries {
+		if reInteraction.MatchString(e.Name()) {
+			names = append(names, e.Name())
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("cassette %s has no recordings", dir)
+	}
+	sort.Strings(names)
+
+	r := &replayer{dir: dir, responses: make(map[string][]RecordedResponse), served: make(map[string]int)}
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		var in Interaction
+		if err := json.Unmarshal(data, &in); err != nil {
+			return nil, fmt.Errorf("parsing cassette %s: %w", filepath.Join(dir, name), err)
+		}
+		key := interactionKey(in.Request)
+		r.responses[key] = append(r.responses[key], in.Response)
+	}
+	return r, nil
+}
+
+func (r *replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+	key := interactionKey(recordedRequest(req))
+	r.mu.Lock()
+	recorded := r.responses[key]
+	i := r.served[key]
+	if i < len(recorded)-1 {
+		r.served[key]++
+	}
+	r.mu.Unlock()
+	if len(recorded) == 0 {
+		return nil, fmt.Errorf("cassette %s has no response for %s %s", r.dir, req.Method, scrubURL(req.URL.String()))
+	}
+
+	rec := recorded[i]
+	body := rec.BodyBase64
+	if body == nil {
+		body = []byte(rec.Body)
+	}
+	header := rec.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.Status, http.StatusText(rec.Status)),
+		StatusCode:    rec.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,127 @@
+package httpclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// recordServer serves a JSON list, a redirect whose Location carries a
+// token, as archive downloads do, and a binary file.
+func recordServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	calls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/releases", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Set-Cookie", "session=secret-cookie")
+		_, _ = fmt.Fprintf(w, `[{"tag_name":"v%d"}]`, calls)
+	})
+	mux.HandleFunc("/zipball", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/codeload?token=secret-redirect", http.StatusFound)
+	})
+	mux.HandleFunc("/codeload", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("token") != "secret-redirect" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte{0x50, 0x4b, 0xff, 0xfe})
+	})
+	return httptest.NewServer(mux)
+}
+
+func fetch(t *testing.T, c *http.Client, url string) string {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	req.Header.Set("Authorization", "Bearer secret-token")
+	resp, err := c.Do(req)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	var b strings.Builder
+	_, _ = fmt.Fprintf(&b, "%d ", resp.StatusCode)
+	buf := make([]byte, 64)
+	n, _ := resp.Body.Read(buf)
+	b.Write(buf[:n])
+	return b.String()
+}
+
+func TestCassette_RecordReplay(t *testing.T) {
+	srv := recordServer(t)
+	dir := filepath.Join(t.TempDir(), "cassette")
+
+	rec, err := New(Options{RecordDir: dir, CacheDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var live []string
+	for _, path := range []string{"/releases", "/releases", "/zipball"} {
+		live = append(live, fetch(t, rec, srv.URL+path))
+	}
+	srv.Close()
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 4 {
+		t.Fatalf("recorded %d interactions, want 4", len(entries))
+	}
+	for _, e := range entries {
+		data, _ := os.ReadFile(filepath.Join(dir, e.Name()))
+		for _, secret := range []string{"secret-token", "secret-redirect", "secret-cookie"} {
+			if strings.Contains(string(data), secret) {
+				t.Errorf("%s leaks %s:\n%s", e.Name(), secret, data)
+			}
+		}
+	}
+
+	play, err := New(Options{ReplayDir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Repeated requests replay in order, then the last response repeats.
+	for i, path := range []string{"/releases", "/releases", "/zipball", "/releases"} {
+		want := live[min(i, len(live)-1)]
+		if i == 3 {
+			want = live[1]
+		}
+		if got := fetch(t, play, srv.URL+path); got != want {
+			t.Errorf("replay %s = %q, want %q", path, got, want)
+		}
+	}
+	if _, err := play.Get(srv.URL + "/unknown"); err == nil || !strings.Contains(err.Error(), "has no response for GET") {
+		t.Errorf("expected missing interaction error, got %v", err)
+	}
+}
+
+func TestCassette_Errors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := New(Options{ReplayDir: dir}); err == nil || !strings.Contains(err.Error(), "no recordings") {
+		t.Errorf("empty cassette: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "0001.json"), []byte("{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(Options{RecordDir: dir}); err == nil || !strings.Contains(err.Error(), "already has recordings") {
+		t.Errorf("recording over a cassette: %v", err)
+	}
+	if _, err := New(Options{RecordDir: dir, ReplayDir: dir}); err == nil {
+		t.Error("expected error for record and replay at once")
+	}
+}
+
+func TestScrubURL(t *testing.T) {
+	tests := map[string]string{
+		"https://codeload.github.com/o/r/zip/abc?token=AAA":    "https://codeload.github.com/o/r/zip/abc?token=REDACTED",
+		"https://user:pw@example.com/x?X-Amz-Signature=s&a=b":  "https://REDACTED@example.com/x?X-Amz-Signature=REDACTED&a=b",
+		"https://api.github.com/repos/o/r/releases?per_page=1": "https://api.github.com/repos/o/r/releases?per_page=1",
+	}
+	for in, want := range tests {
+		if got := scrubURL(in); got != want {
+			t.Errorf("scrubURL(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,284 @@
+// Package httpclient builds the HTTP client forge APIs are called through:
+// per-attempt timeouts, bounded retries with exponential backoff, rate limit
+// handling and an on-disk cache of ETag-validated responses.
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// Defaults for the zero Options.
+const (
+	DefaultTimeout    = 60 * time.Second
+	DefaultMaxRetries = 3
+	DefaultMaxWait    = 2 * time.Minute
+)
+
+// Backoff bounds between retries of failed requests.
+const (
+	backoffBase = 500 * time.Millisecond
+	backoffMax  = 8 * time.Second
+)
+
+// Options configures New. The zero value retries with the defaults, does
+// not wait on Retry-After and caches nothing.
+type Options struct {
+	// Timeout bounds each attempt, including reading the response body.
+	Timeout time.Duration
+	// MaxRetries bounds the retries after a failed attempt.
+	MaxRetries int
+	// WaitRetryAfter waits out rate limits that say when to retry, up to
+	// MaxWait, instead of failing at once.
+	WaitRetryAfter bool
+	// MaxWait is the longest rate limit wait WaitRetryAfter accepts.
+	MaxWait time.Duration
+	// CacheDir holds responses for conditional requests. Empty disables the
+	// cache.
+	CacheDir string
+	// Transport sends the requests. Nil means http.DefaultTransport.
+	Transport http.RoundTripper
+	// RecordDir records every exchange to a cassette in this directory.
+	// The cache is bypassed so full responses are recorded.
+	RecordDir string
+	// ReplayDir serves responses from the cassette in this directory and
+	// never touches the network.
+	ReplayDir string
+}
+
+// New returns a client for forge APIs. It fails only when a cassette cannot
+// be opened.
+func New(o Options) (*http.Client, error) {
+	if o.Timeout <= 0 {
+		o.Timeout = DefaultTimeout
+	}
+	if o.MaxRetries < 0 {
+		o.MaxRetries = 0
+	} else if o.MaxRetries == 0 {
+		o.MaxRetries = DefaultMaxRetries
+	}
+	if o.MaxWait <= 0 {
+		o.MaxWait = DefaultMaxWait
+	}
+	if o.Transport == nil {
+		o.Transport = http.DefaultTransport
+	}
+
+	if o.RecordDir != "" && o.ReplayDir != "" {
+		return nil, errors.New("cannot record and replay at once")
+	}
+	if o.ReplayDir != "" {
+		// Replayed responses are final: no retries, no cache.
+		rt, err := Replay(o.ReplayDir)
+		if err != nil {
+			return nil, err
+		}
+		return &http.Client{Transport: rt}, nil
+	}
+
+	var rt http.RoundTripper = &retryTransport{opts: o, next: o.Transport, sleep: sleep}
+	switch {
+	case o.RecordDir != "":
+		rec, err := newRecorder(o.RecordDir, rt)
+		if err != nil {
+			return nil, err
+		}
+		rt = rec
+	case o.CacheDir != "":
+		rt = &cacheTransport{dir: o.CacheDir, next: rt}
+	}
+	return &http.Client{Transport: rt}, nil
+}
+
+// MustNew is New for options without a cassette, which cannot fail.
+func MustNew(o Options) *http.Client {
+	c, err := New(o)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// DefaultCacheDir returns the cache directory under the user cache dir.
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "ancc", "http"), nil
+}
+
+// RateLimitError reports a request refused by a rate limit.
+type RateLimitError struct {
+	Host string
+	// Reset is when a primary rate limit resets; zero for secondary limits.
+	Reset time.Time
+	// RetryAfter is how long a secondary rate limit asks to wait, if it
+	// says.
+	RetryAfter time.Duration
+	// Authenticated reports whether the request carried a token.
+	Authenticated bool
+}
+
+func (e *RateLimitError) Error() string {
+	var msg string
+	if !e.Reset.IsZero() {
+		msg = fmt.Sprintf("%s rate limit exceeded; resets at %s (in %s)",
+			e.Host, e.Reset.UTC().Format(time.RFC3339), time.Until(e.Reset).Round(time.Second))
+	} else {
+		msg = fmt.Sprintf("%s secondary rate limit exceeded", e.Host)
+		if e.RetryAfter > 0 {
+			msg += fmt.Sprintf("; retry after %s", e.RetryAfter)
+		}
+	}
+	if !e.Authenticated {
+		msg += "; set a token for a higher limit"
+	}
+	return msg
+}
+
+// retryTransport retries failed attempts with exponential backoff and
+// turns rate limit responses into RateLimitError.
+type retryTransport struct {
+	opts  Options
+	next  http.RoundTripper
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	for attempt := 0; ; attempt++ {
+		resp, err := t.attempt(req)
+		wait := backoff(attempt)
+		switch {
+		case err != nil:
+			if ctx.Err() != nil || attempt >= t.opts.MaxRetries {
+				return nil, err
+			}
+		case resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests:
+			rl := rateLimit(req, resp)
+			if rl == nil {
+				return resp, nil
+			}
+			_ = resp.Body.Close()
+			switch d := rl.wait(); {
+			case d > 0 && t.opts.WaitRetryAfter && d <= t.opts.MaxWait && attempt < t.opts.MaxRetries:
+				wait = d
+			case d == 0 && rl.Reset.IsZero() && attempt < t.opts.MaxRetries:
+				// A secondary limit without a Retry-After: back off.
+			default:
+				return nil, rl
+			}
+		case resp.StatusCode >= 500:
+			if attempt >= t.opts.MaxRetries {
+				return resp, nil
+			}
+			_ = resp.Body.Close()
+		default:
+			return resp, nil
+		}
+		if err := t.sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// attempt sends req once under the per-attempt timeout, which keeps running
+// while the body is read.
+func (t *retryTransport) attempt(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), t.opts.Timeout)
+	resp, err := t.next.RoundTrip(req.Clone(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// rateLimit returns the rate limit a 403 or 429 reports, or nil when the
+// response is an ordinary refusal. X-RateLimit-Remaining: 0 marks a primary
+// limit; a 429 or a Retry-After header marks a secondary one.
+func rateLimit(req *http.Request, resp *http.Response) *RateLimitError {
+	rl := &RateLimitError{Host: req.URL.Host, Authenticated: req.Header.Get("Authorization") != "" || req.Header.Get("PRIVATE-TOKEN") != ""}
+	retryAfter, hasRetryAfter := parseRetryAfter(resp.Header.Get("Retry-After"))
+	switch {
+	case resp.Header.Get("X-RateLimit-Remaining") == "0":
+		if secs, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			rl.Reset = time.Unix(secs, 0)
+		} else {
+			rl.RetryAfter = retryAfter
+		}
+		if rl.Reset.IsZero() && rl.RetryAfter == 0 {
+			rl.RetryAfter = time.Minute
+		}
+	case resp.StatusCode == http.StatusTooManyRequests || hasRetryAfter:
+		rl.RetryAfter = retryAfter
+	default:
+		return nil
+	}
+	return rl
+}
+
+// wait is how long to wait before retrying, or 0 when unknown.
+func (e *RateLimitError) wait() time.Duration {
+	if !e.Reset.IsZero() {
+		if d := time.Until(e.Reset); d > 0 {
+			return d + time.Second
+		}
+		return time.Second
+	}
+	return e.RetryAfter
+}
+
+// parseRetryAfter reads a Retry-After header in seconds or as an HTTP date.
+func parseRetryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
+
+// backoff returns the wait before retry attempt+1.
+func backoff(attempt int) time.Duration {
+	d := backoffBase << attempt
+	if d <= 0 || d > backoffMax {
+		return backoffMax
+	}
+	return d
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,198 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestClient returns a client whose backoff waits are recorded instead
+// of slept.
+func newTestClient(o Options) (*http.Client, *[]time.Duration) {
+	c := MustNew(o)
+	var waits []time.Duration
+	rt := c.Transport
+	if ct, ok := rt.(*cacheTransport); ok {
+		rt = ct.next
+	}
+	rt.(*retryTransport).sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+	return c, &waits
+}
+
+func get(t *testing.T, c *http.Client, url string) (*http.Response, string, error) {
+	t.Helper()
+	resp, err := c.Get(url)
+	if err != nil {
+		return nil, "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	return resp, string(body), err
+}
+
+func TestRetry_ServerErrors(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = fmt.Fprint(w, "ok")
+	}))
+	defer srv.Close()
+
+	c, waits := newTestClient(Options{})
+	resp, body, err := get(t, c, srv.URL)
+	if err != nil || resp.StatusCode != http.StatusOK || body != "ok" {
+		t.Fatalf("got %v %q %v", resp, body, err)
+	}
+	if want := []time.Duration{500 * time.Millisecond, time.Second}; fmt.Sprint(*waits) != fmt.Sprint(want) {
+		t.Errorf("waits = %v, want %v", *waits, want)
+	}
+}
+
+func TestRetry_GivesUp(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	c, _ := newTestClient(Options{MaxRetries: 2})
+	resp, _, err := get(t, c, srv.URL)
+	if err != nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("got %v %v, want the last 503", resp, err)
+	}
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3", calls)
+	}
+}
+
+func TestRetry_PrimaryRateLimit(t *testing.T) {
+	reset := time.Now().Add(10 * time.Minute)
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	c, _ := newTestClient(Options{WaitRetryAfter: true})
+	_, _, err := get(t, c, srv.URL)
+	var rl *RateLimitError
+	if !errors.As(err, &rl) {
+		t.Fatalf("expected RateLimitError, got %v", err)
+	}
+	msg := rl.Error()
+	if !strings.Contains(msg, "rate limit exceeded; resets at "+reset.UTC().Format(time.RFC3339)) || !strings.Contains(msg, "set a token") {
+		t.Errorf("message = %q", msg)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d; a reset beyond MaxWait must not be waited for", calls)
+	}
+}
+
+func TestRetry_SecondaryRateLimit(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = fmt.Fprint(w, "ok")
+	}))
+	defer srv.Close()
+
+	// Waiting is opt-in.
+	c, _ := newTestClient(Options{})
+	if _, _, err := get(t, c, srv.URL); err == nil || !strings.Contains(err.Error(), "secondary rate limit exceeded; retry after 30s") {
+		t.Fatalf("expected secondary rate limit error, got %v", err)
+	}
+
+	calls = 0
+	c, waits := newTestClient(Options{WaitRetryAfter: true})
+	if _, body, err := get(t, c, srv.URL); err != nil || body != "ok" {
+		t.Fatalf("got %q %v", body, err)
+	}
+	if len(*waits) != 1 || (*waits)[0] != 30*time.Second {
+		t.Errorf("waits = %v, want [30s]", *waits)
+	}
+}
+
+func TestRetry_SecondaryWithoutRetryAfter(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = fmt.Fprint(w, "ok")
+	}))
+	defer srv.Close()
+
+	c, waits := newTestClient(Options{})
+	if _, body, err := get(t, c, srv.URL); err != nil || body != "ok" {
+		t.Fatalf("got %q %v", body, err)
+	}
+	if len(*waits) != 1 || (*waits)[0] != backoffBase {
+		t.Errorf("waits = %v, want one backoff", *waits)
+	}
+}
+
+func TestRetry_PlainForbidden(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	c, waits := newTestClient(Options{})
+	resp, _, err := get(t, c, srv.URL)
+	if err != nil || resp.StatusCode != http.StatusForbidden || len(*waits) != 0 {
+		t.Errorf("got %v %v after %v; want the 403 at once", resp, err, *waits)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	c, _ := newTestClient(Options{Timeout: 20 * time.Millisecond, MaxRetries: -1})
+	if _, _, err := get(t, c, srv.URL); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	for attempt, want := range []time.Duration{500 * time.Millisecond, time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 8 * time.Second} {
+		if got := backoff(attempt); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", attempt, got, want)
+		}
+	}
+	if got := backoff(100); got != backoffMax {
+		t.Errorf("backoff(100) = %v", got)
+	}
+}
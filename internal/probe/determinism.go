@@ -0,0 +1,143 @@
+package probe
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ppiankov/ancc/internal/validator"
+)
+
+// maxReportedPaths caps how many nondeterministic fields a message lists.
+const maxReportedPaths = 10
+
+// checkBounded verifies every run finished within the timeout and budget
+// and left no processes behind.
+func checkBounded(t target, runs []execResult, opts Options) validator.CheckResult {
+	var slowest time.Duration
+	for _, res := range runs {
+		switch {
+		case res.timedOut:
+			return failf(CheckBounded, "%s: timed out after %s", t.name, opts.Timeout)
+		case res.err != nil:
+			return failf(CheckBounded, "%s: could not run: %v", t.name, res.err)
+		case res.orphans:
+			return failf(CheckBounded, "%s: left child processes running after exit", t.name)
+		}
+		slowest = max(slowest, res.elapsed)
+	}
+	slowest = slowest.Round(time.Millisecond)
+	if opts.Budget > 0 {
+		if slowest > opts.Budget {
+			return failf(CheckBounded, "%s: took %s, over the %s budget", t.name, slowest, opts.Budget)
+		}
+		return passf(CheckBounded, "%s: finished in %s of %s budget, no processes left behind", t.name, slowest, opts.Budget)
+	}
+	return passf(CheckBounded, "%s: finished in %s, no processes left behind", t.name, slowest)
+}
+
+// checkDeterminism compares the JSON output of repeated runs and reports
+// fields that changed, ignoring those SKILL.md marks as volatile.
+func checkDeterminism(t target, runs []execResult) validator.CheckResult {
+	outputs := make([]any, len(runs))
+	for i, res := range runs {
+		if res.err != nil || res.timedOut {
+			return warnf(CheckDeterminism, "%s: skipped, run %d did not complete", t.name, i+1)
+		}
+		v, err := decodeSingleJSON(res.stdout)
+		if err != nil {
+			return warnf(CheckDeterminism, "%s: skipped, run %d: %v", t.name, i+1, err)
+		}
+		outputs[i] = v
+	}
+
+	changed := make(map[string]bool)
+	for _, out := range outputs[1:] {
+		diffJSON(outputs[0], out, "$", t.cmd.Volatile, changed)
+	}
+	if len(changed) == 0 {
+		return passf(CheckDeterminism, "%s: identical JSON across %d runs", t.name, len(runs))
+	}
+
+	paths := make([]string, 0, len(changed))
+	for p := range changed {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	more := ""
+	if len(paths) > maxReportedPaths {
+		more = fmt.Sprintf(" and %d more", len(paths)-maxReportedPaths)
+		paths = paths[:maxReportedPaths]
+	}
+	return failf(CheckDeterminism, "%s: nondeterministic fields across %d runs: %s%s (mark intended ones with **Volatile fields:**)",
+		t.name, len(runs), strings.Join(paths, ", "), more)
+}
+
+// diffJSON records in changed the JSON path of every leaf that differs
+// between a and b. Objects and arrays are descended; a type or length
+// mismatch is reported at the containing path.
+func diffJSON(a, b any, path string, volatile []string, changed map[string]bool) {
+	if isVolatile(path, volatile) {
+		return
+	}
+	switch av := a.(type) {
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		if !ok {
+			changed[path] = true
+			return
+		}
+		for k, v := range av {
+			diffJSON(v, bv[k], path+"."+k, volatile, changed)
+		}
+		for k, v := range bv {
+			if _, ok := av[k]; !ok {
+				diffJSON(nil, v, path+"."+k, volatile, changed)
+			}
+		}
+	case []any:
+		bv, ok := b.([]any)
+		if !ok || len(av) != len(bv) {
+			changed[path] = true
+			return
+		}
+		for i := range av {
+			diffJSON(av[i], bv[i], fmt.Sprintf("%s[%d]", path, i), volatile, changed)
+		}
+	default:
+		if !reflect.DeepEqual(a, b) {
+			changed[path] = true
+		}
+	}
+}
+
+var reIndex = regexp.MustCompile(`\[\d+\]`)
+
+// isVolatile reports whether path falls under a volatile marker. A marker
+// starting with "$" is a JSON path where [*] matches any index, such as
+// "$.checks[*].duration_ms"; anything else is a key name matched at any
+// depth. Both cover everything nested below the match.
+func isVolatile(path string, volatile []string) bool {
+	if len(volatile) == 0 {
+		return false
+	}
+	generic := reIndex.ReplaceAllString(path, "[*]")
+	for _, v := range volatile {
+		if strings.HasPrefix(v, "$") {
+			v = reIndex.ReplaceAllString(v, "[*]")
+			if generic == v || strings.HasPrefix(generic, v+".") || strings.HasPrefix(generic, v+"[") {
+				return true
+			}
+			continue
+		}
+		for _, seg := range strings.Split(generic, ".")[1:] {
+			if key, _, _ := strings.Cut(seg, "["); key == v {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package probe
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ppiankov/ancc/internal/skillmd"
+	"github.com/ppiankov/ancc/internal/validator"
+)
+
+func TestRun_Determinism(t *testing.T) {
+	volatileClock := jsonCmd("mytool clock", "", true)
+	volatileClock.Volatile = []string{"$.meta.generated_at", "nonce"}
+	tests := []struct {
+		name     string
+		cmd      skillmd.Command
+		want     string
+		contains string
+	}{
+		{"stable", jsonCmd("mytool ok", "", true), validator.StatusPass, "identical JSON across 3 runs"},
+		{"unmarked", jsonCmd("mytool clock", "", true), validator.StatusFail, "$.items[0].nonce, $.meta.generated_at"},
+		{"volatile", volatileClock, validator.StatusPass, "identical"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sf := &skillmd.SkillFile{Name: "mytool", Commands: []skillmd.Command{tt.cmd}}
+			opts := fakeOptions(t)
+			opts.Runs = 3
+
+			results := resultsNamed(Run(sf, opts), CheckDeterminism)
+			if len(results) != 1 {
+				t.Fatalf("got %d results, want 1: %+v", len(results), results)
+			}
+			if results[0].Status != tt.want || !strings.Contains(results[0].Message, tt.contains) {
+				t.Errorf("got %s %q, want %s containing %q", results[0].Status, results[0].Message, tt.want, tt.contains)
+			}
+		})
+	}
+}
+
+func TestRun_SingleRunSkipsDeterminism(t *testing.T) {
+	sf := &skillmd.SkillFile{Name: "mytool", Commands: []skillmd.Command{jsonCmd("mytool ok", "", true)}}
+	if got := resultsNamed(Run(sf, fakeOptions(t)), CheckDeterminism); len(got) != 0 {
+		t.Errorf("expected no determinism check for a single run, got %+v", got)
+	}
+}
+
+func TestRun_Bounded(t *testing.T) {
+	tests := []struct {
+		name     string
+		command  string
+		budget   time.Duration
+		want     string
+		contains string
+		unixOnly bool
+	}{
+		{"within budget", "mytool ok", time.Minute, validator.StatusPass, "budget", false},
+		{"over budget", "mytool ok", time.Nanosecond, validator.StatusFail, "over the 1ns budget", false},
+		{"leftover child", "mytool spawn", 0, validator.StatusFail, "left child processes", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.unixOnly && runtime.GOOS == "windows" {
+				t.Skip("process groups are unix-only")
+			}
+			sf := &skillmd.SkillFile{Name: "mytool", Commands: []skillmd.Command{jsonCmd(tt.command, "", true)}}
+			opts := fakeOptions(t)
+			opts.Budget = tt.budget
+
+			results := resultsNamed(Run(sf, opts), CheckBounded)
+			if len(results) != 1 {
+				t.Fatalf("got %d results, want 1: %+v", len(results), results)
+			}
+			if results[0].Status != tt.want || !strings.Contains(results[0].Message, tt.contains) {
+				t.Errorf("got %s %q, want %s containing %q", results[0].Status, results[0].Message, tt.want, tt.contains)
+			}
+		})
+	}
+}
+
+func TestIsVolatile(t *testing.T) {
+	tests := []struct {
+		path     string
+		volatile []string
+		want     bool
+	}{
+		{"$.generated_at", []string{"$.generated_at"}, true},
+		{"$.checks[3].duration_ms", []string{"$.checks[*].duration_ms"}, true},
+		{"$.checks[3].duration_ms", []string{"duration_ms"}, true},
+		{"$.timing.total", []string{"timing"}, true},
+		{"$.timing.total", []string{"$.timing"}, true},
+		{"$.timings", []string{"$.timing"}, false},
+		{"$.status", []string{"duration_ms"}, false},
+		{"$.status", nil, false},
+	}
+	for _, tt := range tests {
+		if got := isVolatile(tt.path, tt.volatile); got != tt.want {
+			t.Errorf("isVolatile(%q, %q) = %v, want %v", tt.path, tt.volatile, got, tt.want)
+		}
+	}
+}
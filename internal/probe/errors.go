@@ -0,0 +1,153 @@
+package probe
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ppiankov/ancc/internal/skillmd"
+	"github.com/ppiankov/ancc/internal/validator"
+)
+
+// Generated bad input. The names are deliberately implausible so no real
+// tool accepts them.
+const (
+	unknownFlag   = "--ancc-probe-unknown-flag"
+	invalidFormat = "ancc-invalid-format"
+)
+
+// negativeCase is a generated invocation that the tool must reject.
+type negativeCase struct {
+	label    string
+	args     []string
+	jsonMode bool // --format json is set, so stdout may only carry JSON
+}
+
+// negativeCases builds the error-path invocations for a target.
+func negativeCases(t target) []negativeCase {
+	cases := []negativeCase{
+		{label: "unknown flag", args: withJSONFormat(append(t.argv(), unknownFlag)), jsonMode: true},
+		{label: "invalid --format", args: append(t.argv(), "--format", invalidFormat)},
+	}
+	if t.required != "" {
+		cases = append(cases, negativeCase{
+			label:    "missing " + t.required,
+			args:     withJSONFormat(t.words),
+			jsonMode: true,
+		})
+	}
+	return cases
+}
+
+// probeErrors runs the generated negative cases for one target.
+func probeErrors(sf *skillmd.SkillFile, t target, opts Options) []validator.CheckResult {
+	wantCode, documented := usageExitCode(sf, t.cmd)
+
+	var errDoc any
+	if t.cmd.ErrorJSON != "" {
+		if err := json.Unmarshal([]byte(t.cmd.ErrorJSON), &errDoc); err != nil {
+			errDoc = nil
+		}
+	}
+
+	var results []validator.CheckResult
+	for _, nc := range negativeCases(t) {
+		name := fmt.Sprintf("%s (%s)", t.name, nc.label)
+		res := execute(opts, nc.args)
+		if r, failed := runFailure(CheckErrorPath, name, res, opts.Timeout); failed {
+			results = append(results, r)
+			continue
+		}
+
+		var problems []string
+		switch {
+		case res.exitCode == 0:
+			problems = append(problems, "exited 0 on invalid input")
+		case documented && res.exitCode != wantCode:
+			problems = append(problems, fmt.Sprintf("exit code %d, documented usage error is %d", res.exitCode, wantCode))
+		}
+
+		if len(bytes.TrimSpace(res.stderr)) == 0 {
+			problems = append(problems, "no diagnostic on stderr")
+		}
+
+		var out any
+		hasJSON := false
+		if len(bytes.TrimSpace(res.stdout)) > 0 {
+			v, err := decodeSingleJSON(res.stdout)
+			if err != nil && nc.jsonMode {
+				problems = append(problems, err.Error())
+			}
+			out, hasJSON = v, err == nil
+		}
+
+		if errDoc != nil && nc.jsonMode {
+			if !hasJSON {
+				out, hasJSON = lastJSONLine(res.stderr)
+			}
+			switch {
+			case !hasJSON:
+				problems = append(problems, "documented error object not emitted")
+			default:
+				if msg := Conforms(errDoc, out); msg != "" {
+					problems = append(problems, "error object does not match documented shape: "+msg)
+				}
+			}
+		}
+
+		if len(problems) > 0 {
+			results = append(results, failf(CheckErrorPath, "%s: %s", name, strings.Join(problems, "; ")))
+			continue
+		}
+		if !documented {
+			results = append(results, warnf(CheckErrorPath,
+				"%s: rejected with exit code %d, but no usage error exit code is documented", name, res.exitCode))
+			continue
+		}
+		results = append(results, passf(CheckErrorPath, "%s: exit %d with diagnostics on stderr", name, res.exitCode))
+	}
+	return results
+}
+
+// usageKeywords identify the exit code documented for bad invocations,
+// most specific first.
+var usageKeywords = []string{"usage", "invalid", "argument", "flag", "error"}
+
+// usageExitCode finds the documented exit code for usage errors. Each
+// keyword is looked up on the command first and then across the file,
+// since many tools document their exit codes once.
+func usageExitCode(sf *skillmd.SkillFile, cmd skillmd.Command) (int, bool) {
+	for _, kw := range usageKeywords {
+		if code, ok := exitCodeMatching(cmd.ExitCodes, kw); ok {
+			return code, true
+		}
+		for _, c := range sf.Commands {
+			if code, ok := exitCodeMatching(c.ExitCodes, kw); ok {
+				return code, true
+			}
+		}
+	}
+	return 0, false
+}
+
+func exitCodeMatching(codes []skillmd.ExitCode, keyword string) (int, bool) {
+	for _, ec := range codes {
+		if ec.Code != 0 && strings.Contains(strings.ToLower(ec.Desc), keyword) {
+			return ec.Code, true
+		}
+	}
+	return 0, false
+}
+
+// lastJSONLine finds a JSON value on the last non-empty line of stderr,
+// where some tools write structured errors.
+func lastJSONLine(stderr []byte) (any, bool) {
+	lines := strings.Split(strings.TrimSpace(string(stderr)), "\n")
+	last := strings.TrimSpace(lines[len(lines)-1])
+	var v any
+	if last == "" || json.Unmarshal([]byte(last), &v) != nil {
+		return nil, false
+	}
+	return v, true
+}
@@ -0,0 +1,97 @@
+package probe
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ppiankov/ancc/internal/skillmd"
+	"github.com/ppiankov/ancc/internal/validator"
+)
+
+func errorCmd(name string) skillmd.Command {
+	c := jsonCmd(name, `{"status": "ok"}`, true)
+	c.ExitCodes = []skillmd.ExitCode{{Code: 0, Desc: "success"}, {Code: 1, Desc: "failure"}, {Code: 2, Desc: "usage error"}}
+	return c
+}
+
+func TestProbeErrors_WellBehaved(t *testing.T) {
+	c := errorCmd("mytool needarg <path>")
+	c.ErrorJSON = `{"error": "message", "code": 1}`
+	sf := &skillmd.SkillFile{Name: "mytool", Commands: []skillmd.Command{c}}
+	opts := fakeOptions(t)
+	opts.Args = map[string][]string{"needarg": {"."}}
+
+	results := resultsNamed(Run(sf, opts), CheckErrorPath)
+	if len(results) != 3 {
+		t.Fatalf("got %d error-path results, want 3: %+v", len(results), results)
+	}
+	for _, r := range results {
+		if r.Status != validator.StatusPass {
+			t.Errorf("status = %q, want pass: %s", r.Status, r.Message)
+		}
+	}
+}
+
+func TestProbeErrors_Sloppy(t *testing.T) {
+	sf := &skillmd.SkillFile{Name: "mytool", Commands: []skillmd.Command{errorCmd("mytool sloppy-ok")}}
+
+	results := resultsNamed(Run(sf, fakeOptions(t)), CheckErrorPath)
+	if len(results) != 2 {
+		t.Fatalf("got %d error-path results, want 2: %+v", len(results), results)
+	}
+	for _, r := range results {
+		if r.Status != validator.StatusFail {
+			t.Errorf("status = %q, want fail: %s", r.Status, r.Message)
+		}
+	}
+	msg := results[0].Message
+	for _, want := range []string{"exited 0", "no diagnostic on stderr", "stdout is not JSON"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("message %q should mention %q", msg, want)
+		}
+	}
+}
+
+func TestProbeErrors_WrongCodeAndShape(t *testing.T) {
+	c := errorCmd("mytool ok")
+	c.ExitCodes = []skillmd.ExitCode{{Code: 64, Desc: "invalid usage"}}
+	c.ErrorJSON = `{"error": {"message": "x"}}`
+	sf := &skillmd.SkillFile{Name: "mytool", Commands: []skillmd.Command{c}}
+
+	results := resultsNamed(Run(sf, fakeOptions(t)), CheckErrorPath)
+	if len(results) == 0 {
+		t.Fatal("expected error-path results")
+	}
+	msg := results[0].Message
+	if !strings.Contains(msg, "exit code 2, documented usage error is 64") {
+		t.Errorf("message %q should report the exit code mismatch", msg)
+	}
+	if !strings.Contains(msg, "$.error: expected object, got string") {
+		t.Errorf("message %q should report the error shape mismatch", msg)
+	}
+}
+
+func TestProbeErrors_Undocumented(t *testing.T) {
+	c := jsonCmd("mytool ok", "", true)
+	sf := &skillmd.SkillFile{Name: "mytool", Commands: []skillmd.Command{c}}
+
+	for _, r := range resultsNamed(Run(sf, fakeOptions(t)), CheckErrorPath) {
+		if r.Status != validator.StatusWarn {
+			t.Errorf("status = %q, want warn without a documented usage code: %s", r.Status, r.Message)
+		}
+	}
+}
+
+func TestUsageExitCode(t *testing.T) {
+	sf := &skillmd.SkillFile{Commands: []skillmd.Command{
+		{Name: "a", ExitCodes: []skillmd.ExitCode{{Code: 1, Desc: "error"}}},
+		{Name: "b", ExitCodes: []skillmd.ExitCode{{Code: 1, Desc: "runtime error"}, {Code: 2, Desc: "Usage error"}}},
+	}}
+
+	if code, ok := usageExitCode(sf, sf.Commands[0]); !ok || code != 2 {
+		t.Errorf("usageExitCode = %d, %v; want 2, true (usage beats error)", code, ok)
+	}
+	if _, ok := usageExitCode(&skillmd.SkillFile{}, skillmd.Command{}); ok {
+		t.Error("expected no usage code")
+	}
+}
@@ -0,0 +1,400 @@
+// Package probe runs a built tool binary and checks its runtime behavior
+// against what SKILL.md documents. It is opt-in: validation stays static.
+package probe
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ppiankov/ancc/internal/skillmd"
+	"github.com/ppiankov/ancc/internal/validator"
+)
+
+// Check names.
+const (
+	CheckJSONOutput  = "probe-json-output"
+	CheckErrorPath   = "probe-error-path"
+	CheckBounded     = "probe-bounded"
+	CheckDeterminism = "probe-determinism"
+)
+
+// maxOutput caps captured stdout and stderr per run so a runaway tool
+// cannot exhaust memory.
+const maxOutput = 4 << 20
+
+// waitDelay bounds how long Wait blocks on pipes held open by grandchildren
+// after the probed process exits or is killed.
+const waitDelay = 2 * time.Second
+
+// Options controls how commands are selected and executed.
+type Options struct {
+	// Binary is the path to the built tool.
+	Binary string
+	// Timeout bounds each probed command.
+	Timeout time.Duration
+	// Allow lists extra commands that are safe to execute.
+	Allow []string
+	// Args supplies positional arguments per command.
+	Args map[string][]string
+	// Env lists NAME=value pairs added to the scrubbed environment.
+	Env []string
+	// Runs is how many times each command is executed on the happy path.
+	// Two or more enable the determinism check.
+	Runs int
+	// Budget is the wall-clock time each run should finish within; zero
+	// disables the budget but still checks for leftover processes.
+	Budget time.Duration
+}
+
+// target is a documented command selected for execution.
+type target struct {
+	name     string   // heading as written in SKILL.md
+	words    []string // subcommand path passed as argv
+	args     []string // configured positional arguments
+	required string   // first <required> placeholder in the heading
+	cmd      skillmd.Command
+}
+
+// argv returns the subcommand words followed by the configured arguments.
+func (t target) argv() []string {
+	out := make([]string, 0, len(t.words)+len(t.args))
+	out = append(out, t.words...)
+	return append(out, t.args...)
+}
+
+// runnable reports whether the happy path can be executed: every required
+// argument must be supplied through configuration.
+func (t target) runnable() bool {
+	return t.required == "" || t.args != nil
+}
+
+// Run probes every safe, JSON-capable command documented in sf: Runs times
+// on the happy path and once per generated error case.
+func Run(sf *skillmd.SkillFile, opts Options) []validator.CheckResult {
+	targets := selectTargets(sf, opts)
+	if len(targets) == 0 {
+		return locate([]validator.CheckResult{warnf(CheckJSONOutput,
+			"no command is both marked **Read-only:** yes (or allow-listed) and documents --format json")}, sf.Line)
+	}
+
+	var results []validator.CheckResult
+	for _, t := range targets {
+		// Results of a command point at its heading in SKILL.md.
+		first := len(results)
+		if !t.runnable() {
+			results = append(results, warnf(CheckJSONOutput,
+				"%s: requires %s; set probe.args in .ancc.yml to run it", t.name, t.required))
+		} else {
+			runs := runRepeated(t, opts)
+			results = append(results, probeJSON(t, runs[0], opts))
+			results = append(results, checkBounded(t, runs, opts))
+			if len(runs) > 1 {
+				results = append(results, checkDeterminism(t, runs))
+			}
+		}
+		results = append(results, probeErrors(sf, t, opts)...)
+		locate(results[first:], t.cmd.Pos.Line)
+	}
+	return results
+}
+
+// locate points results at a line of SKILL.md.
+func locate(results []validator.CheckResult, line int) []validator.CheckResult {
+	for i := range results {
+		results[i].Locations = []validator.Location{{Path: "SKILL.md", Line: line}}
+	}
+	return results
+}
+
+// selectTargets picks the safe, JSON-capable commands to execute.
+func selectTargets(sf *skillmd.SkillFile, opts Options) []target {
+	program := filepath.Base(opts.Binary)
+	allowed := make(map[string]bool)
+	for _, a := range opts.Allow {
+		allowed[commandKey(a, sf.Name, program)] = true
+	}
+	extraArgs := make(map[string][]string)
+	for k, v := range opts.Args {
+		extraArgs[commandKey(k, sf.Name, program)] = v
+	}
+
+	var targets []target
+	for _, c := range sf.Commands {
+		key := commandKey(c.Name, sf.Name, program)
+		if !c.ReadOnly && !allowed[key] {
+			continue
+		}
+		if !documentsJSON(c) {
+			continue
+		}
+		targets = append(targets, target{
+			name:     c.Name,
+			words:    strings.Fields(key),
+			args:     extraArgs[key],
+			required: requiredPlaceholder(c.Name),
+			cmd:      c,
+		})
+	}
+	return targets
+}
+
+// commandKey reduces a documented heading such as "mytool run <path>" to the
+// subcommand words ("run") used both as argv and as the config lookup key.
+func commandKey(name string, programs ...string) string {
+	var words []string
+	for _, w := range strings.Fields(name) {
+		if strings.HasPrefix(w, "-") {
+			break
+		}
+		if strings.ContainsAny(w[:1], "<[{") {
+			continue
+		}
+		words = append(words, w)
+	}
+	if len(words) > 0 {
+		for _, p := range programs {
+			if p != "" && words[0] == p {
+				return strings.Join(words[1:], " ")
+			}
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// requiredPlaceholder returns the first <required> argument in a heading.
+func requiredPlaceholder(name string) string {
+	for _, w := range strings.Fields(name) {
+		if strings.HasPrefix(w, "<") {
+			return w
+		}
+	}
+	return ""
+}
+
+func documentsJSON(c skillmd.Command) bool {
+	for _, f := range c.Flags {
+		if strings.Contains(f.Name, "--format json") {
+			return true
+		}
+	}
+	return false
+}
+
+// runRepeated executes a target's happy path opts.Runs times (at least
+// once). Every run sees the same working directory path and environment,
+// emptied in between, so differences in output come from the tool itself.
+func runRepeated(t target, opts Options) []execResult {
+	n := max(opts.Runs, 1)
+	dir, err := os.MkdirTemp("", "ancc-probe-*")
+	if err != nil {
+		return []execResult{{err: fmt.Errorf("creating temp dir: %w", err)}}
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	args := withJSONFormat(t.argv())
+	runs := make([]execResult, 0, n)
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			if err := resetDir(dir); err != nil {
+				runs = append(runs, execResult{err: err})
+				break
+			}
+		}
+		runs = append(runs, executeIn(opts, dir, args))
+	}
+	return runs
+}
+
+// resetDir empties dir while keeping its path.
+func resetDir(dir string) error {
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("resetting temp dir: %w", err)
+	}
+	if err := os.Mkdir(dir, 0o700); err != nil {
+		return fmt.Errorf("resetting temp dir: %w", err)
+	}
+	return nil
+}
+
+// probeJSON checks the stdout of one --format json run.
+func probeJSON(t target, res execResult, opts Options) validator.CheckResult {
+	if r, failed := runFailure(CheckJSONOutput, t.name, res, opts.Timeout); failed {
+		return r
+	}
+
+	out, err := decodeSingleJSON(res.stdout)
+	if err != nil {
+		return failf(CheckJSONOutput, "%s: %v", t.name, err)
+	}
+
+	if t.cmd.JSONOutput == "" {
+		return passf(CheckJSONOutput, "%s: stdout is valid JSON (no documented shape)", t.name)
+	}
+	var doc any
+	if err := json.Unmarshal([]byte(t.cmd.JSONOutput), &doc); err != nil {
+		return warnf(CheckJSONOutput, "%s: stdout is valid JSON, but the documented example does not parse: %v", t.name, err)
+	}
+	if msg := Conforms(doc, out); msg != "" {
+		return failf(CheckJSONOutput, "%s: output does not match documented JSON: %s", t.name, msg)
+	}
+	return passf(CheckJSONOutput, "%s: stdout is JSON matching the documented shape", t.name)
+}
+
+// withJSONFormat returns a copy of args with --format json appended.
+func withJSONFormat(args []string) []string {
+	out := make([]string, 0, len(args)+2)
+	out = append(out, args...)
+	return append(out, "--format", "json")
+}
+
+// runFailure converts execution errors into a failing check.
+func runFailure(check, name string, res execResult, timeout time.Duration) (validator.CheckResult, bool) {
+	switch {
+	case res.timedOut:
+		return failf(check, "%s: timed out after %s", name, timeout), true
+	case res.err != nil:
+		return failf(check, "%s: could not run: %v", name, res.err), true
+	case res.truncated:
+		return failf(check, "%s: output exceeds %d bytes", name, maxOutput), true
+	}
+	return validator.CheckResult{}, false
+}
+
+// decodeSingleJSON requires stdout to hold exactly one JSON document and
+// nothing else but whitespace.
+func decodeSingleJSON(stdout []byte) (any, error) {
+	if len(bytes.TrimSpace(stdout)) == 0 {
+		return nil, errors.New("no output on stdout")
+	}
+	dec := json.NewDecoder(bytes.NewReader(stdout))
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("stdout is not JSON: %v (starts with %q)", err, preview(stdout))
+	}
+	if extra := bytes.TrimSpace(stdout[dec.InputOffset():]); len(extra) > 0 {
+		return nil, fmt.Errorf("stdout has non-JSON content after the document: %q", preview(extra))
+	}
+	return v, nil
+}
+
+func preview(b []byte) string {
+	const n = 60
+	s := strings.TrimSpace(string(b))
+	if len(s) > n {
+		return s[:n] + "..."
+	}
+	return s
+}
+
+// execResult is the captured outcome of one run.
+type execResult struct {
+	stdout    []byte
+	stderr    []byte
+	exitCode  int
+	timedOut  bool
+	truncated bool
+	elapsed   time.Duration
+	orphans   bool  // processes it started were still running after it exited
+	err       error // failure to start or wait, not a non-zero exit
+}
+
+// execute runs the binary in a fresh temp dir with a scrubbed environment.
+func execute(opts Options, args []string) execResult {
+	dir, err := os.MkdirTemp("", "ancc-probe-*")
+	if err != nil {
+		return execResult{err: fmt.Errorf("creating temp dir: %w", err)}
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	return executeIn(opts, dir, args)
+}
+
+// executeIn runs the binary in dir with a scrubbed environment.
+func executeIn(opts Options, dir string, args []string) execResult {
+	ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+	defer cancel()
+
+	stdout := &limitedBuffer{max: maxOutput}
+	stderr := &limitedBuffer{max: maxOutput}
+	cmd := exec.CommandContext(ctx, opts.Binary, args...)
+	cmd.Dir = dir
+	cmd.Env = scrubbedEnv(dir, opts.Env)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.WaitDelay = waitDelay
+	isolate(cmd)
+
+	start := time.Now()
+	err := cmd.Run()
+	res := execResult{
+		stdout:    stdout.buf.Bytes(),
+		stderr:    stderr.buf.Bytes(),
+		truncated: stdout.overflow || stderr.overflow,
+		elapsed:   time.Since(start),
+		orphans:   reapGroup(cmd),
+	}
+	if cmd.ProcessState != nil {
+		res.exitCode = cmd.ProcessState.ExitCode()
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		res.timedOut = true
+		return res
+	}
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		res.err = err
+	}
+	return res
+}
+
+// scrubbedEnv returns a minimal environment: PATH so the tool can find its
+// helpers, a throwaway HOME, and settings that disable color and paging.
+func scrubbedEnv(dir string, extra []string) []string {
+	env := []string{
+		"PATH=" + os.Getenv("PATH"),
+		"HOME=" + dir,
+		"TMPDIR=" + dir,
+		"LANG=C",
+		"LC_ALL=C",
+		"NO_COLOR=1",
+		"TERM=dumb",
+	}
+	return append(env, extra...)
+}
+
+// limitedBuffer keeps the first max bytes written and drops the rest.
+type limitedBuffer struct {
+	buf      bytes.Buffer
+	max      int
+	overflow bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.buf.Len(); len(p) > room {
+		b.overflow = true
+		if room > 0 {
+			b.buf.Write(p[:room])
+		}
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+func passf(name, format string, args ...any) validator.CheckResult {
+	return validator.CheckResult{Name: name, Status: validator.StatusPass, Message: fmt.Sprintf(format, args...)}
+}
+
+func failf(name, format string, args ...any) validator.CheckResult {
+	return validator.CheckResult{Name: name, Status: validator.StatusFail, Message: fmt.Sprintf(format, args...)}
+}
+
+func warnf(name, format string, args ...any) validator.CheckResult {
+	return validator.CheckResult{Name: name, Status: validator.StatusWarn, Message: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,277 @@
+package probe
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ppiankov/ancc/internal/skillmd"
+	"github.com/ppiankov/ancc/internal/validator"
+)
+
+// fakeToolEnv switches the test binary into a fake CLI tool, so probes can
+// execute a real process without building anything.
+const fakeToolEnv = "ANCC_PROBE_FAKE_TOOL"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(fakeToolEnv) != "" {
+		os.Exit(fakeTool(os.Args[1:]))
+	}
+	os.Exit(m.Run())
+}
+
+// fakeTool behaves according to its first argument. Like a well-behaved
+// CLI it rejects unknown flags and formats with exit code 2, a message on
+// stderr and, in JSON mode, an error object on stdout. Commands prefixed
+// "sloppy-" get error handling wrong.
+func fakeTool(args []string) int {
+	if len(args) == 0 {
+		return 2
+	}
+	cmd, flags := args[0], args[1:]
+	sloppy := strings.HasPrefix(cmd, "sloppy-")
+	cmd = strings.TrimPrefix(cmd, "sloppy-")
+
+	format := "text"
+	var usageErr string
+	var positional []string
+	for i := 0; i < len(flags); i++ {
+		switch {
+		case flags[i] == "--format" && i+1 < len(flags):
+			format = flags[i+1]
+			i++
+		case strings.HasPrefix(flags[i], "-"):
+			usageErr = "unknown flag " + flags[i]
+		default:
+			positional = append(positional, flags[i])
+		}
+	}
+	if format != "text" && format != "json" {
+		usageErr = "invalid format " + format
+	}
+	if cmd == "needarg" && len(positional) == 0 {
+		usageErr = "missing path"
+	}
+	if usageErr != "" {
+		if sloppy {
+			fmt.Println("Usage: fake [flags]")
+			return 0
+		}
+		fmt.Fprintln(os.Stderr, "error: "+usageErr)
+		if format == "json" {
+			fmt.Printf("{\"error\": %q, \"code\": 2}\n", usageErr)
+		}
+		return 2
+	}
+
+	switch cmd {
+	case "ok", "needarg":
+		fmt.Println(`{"status": "ok", "items": [{"id": 1}]}`)
+	case "noisy":
+		fmt.Println("loading config...")
+		fmt.Println(`{"status": "ok"}`)
+	case "trailing":
+		fmt.Println(`{"status": "ok"}`)
+		fmt.Println("done")
+	case "wrong":
+		fmt.Println(`{"status": 1}`)
+	case "slow":
+		time.Sleep(5 * time.Second)
+	case "clock":
+		now := time.Now()
+		_ = json.NewEncoder(os.Stdout).Encode(map[string]any{
+			"status": "ok",
+			"meta":   map[string]any{"generated_at": now.Format(time.RFC3339Nano)},
+			"items":  []any{map[string]any{"id": 1, "nonce": now.UnixNano()}},
+		})
+	case "spawn":
+		child := exec.Command(os.Args[0], "slow")
+		child.Env = os.Environ()
+		if err := child.Start(); err != nil {
+			return 1
+		}
+		fmt.Println(`{"status": "ok"}`)
+	case "env":
+		_ = json.NewEncoder(os.Stdout).Encode(map[string]any{
+			"home":   os.Getenv("HOME"),
+			"secret": os.Getenv("ANCC_TEST_SECRET"),
+			"args":   args,
+		})
+	default:
+		fmt.Fprintln(os.Stderr, "unknown command")
+		return 2
+	}
+	return 0
+}
+
+func fakeOptions(t *testing.T) Options {
+	t.Helper()
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatalf("locating test binary: %v", err)
+	}
+	return Options{
+		Binary:  exe,
+		Timeout: 5 * time.Second,
+		Env:     []string{fakeToolEnv + "=1"},
+	}
+}
+
+func resultsNamed(results []validator.CheckResult, name string) []validator.CheckResult {
+	var out []validator.CheckResult
+	for _, r := range results {
+		if r.Name == name {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func jsonCmd(name, output string, readOnly bool) skillmd.Command {
+	return skillmd.Command{
+		Name:       name,
+		Flags:      []skillmd.Flag{{Name: "--format json"}},
+		JSONOutput: output,
+		ReadOnly:   readOnly,
+	}
+}
+
+func TestRun_StatusPerCommand(t *testing.T) {
+	sf := &skillmd.SkillFile{
+		Name: "mytool",
+		Commands: []skillmd.Command{
+			jsonCmd("mytool ok", `{"status": "ok", "items": [{"id": 0}]}`, true),
+			jsonCmd("mytool noisy", `{"status": "ok"}`, true),
+			jsonCmd("mytool trailing", `{"status": "ok"}`, true),
+			jsonCmd("mytool wrong", `{"status": "ok"}`, true),
+			jsonCmd("mytool mutate", `{"status": "ok"}`, false),
+		},
+	}
+
+	results := resultsNamed(Run(sf, fakeOptions(t)), CheckJSONOutput)
+	want := map[string]string{
+		"mytool ok":       validator.StatusPass,
+		"mytool noisy":    validator.StatusFail,
+		"mytool trailing": validator.StatusFail,
+		"mytool wrong":    validator.StatusFail,
+	}
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d: %+v", len(results), len(want), results)
+	}
+	for _, r := range results {
+		cmd := strings.SplitN(r.Message, ":", 2)[0]
+		if r.Status != want[cmd] {
+			t.Errorf("%s: status = %q, want %q (%s)", cmd, r.Status, want[cmd], r.Message)
+		}
+	}
+}
+
+func TestRun_AllowListAndArgs(t *testing.T) {
+	sf := &skillmd.SkillFile{
+		Name:     "mytool",
+		Commands: []skillmd.Command{jsonCmd("mytool env <path>", `{"home": ""}`, false)},
+	}
+	opts := fakeOptions(t)
+	opts.Allow = []string{"mytool env"}
+
+	results := resultsNamed(Run(sf, opts), CheckJSONOutput)
+	if len(results) != 1 || results[0].Status != validator.StatusWarn {
+		t.Fatalf("expected a warning for the missing required argument, got %+v", results)
+	}
+
+	opts.Args = map[string][]string{"env": {"some/path"}}
+	results = resultsNamed(Run(sf, opts), CheckJSONOutput)
+	if len(results) != 1 || results[0].Status != validator.StatusPass {
+		t.Fatalf("expected pass with configured args, got %+v", results)
+	}
+}
+
+func TestRun_NothingSafe(t *testing.T) {
+	sf := &skillmd.SkillFile{Name: "mytool", Commands: []skillmd.Command{jsonCmd("mytool ok", "", false)}}
+	results := Run(sf, fakeOptions(t))
+	if len(results) != 1 || results[0].Status != validator.StatusWarn {
+		t.Fatalf("expected a single warning, got %+v", results)
+	}
+}
+
+func TestRun_Timeout(t *testing.T) {
+	sf := &skillmd.SkillFile{Name: "mytool", Commands: []skillmd.Command{jsonCmd("mytool slow", "", true)}}
+	opts := fakeOptions(t)
+	opts.Timeout = 200 * time.Millisecond
+
+	results := resultsNamed(Run(sf, opts), CheckJSONOutput)
+	if len(results) != 1 || results[0].Status != validator.StatusFail {
+		t.Fatalf("expected a failure, got %+v", results)
+	}
+	if !strings.Contains(results[0].Message, "timed out") {
+		t.Errorf("message = %q, want timeout", results[0].Message)
+	}
+}
+
+func TestExecute_ScrubbedEnvironment(t *testing.T) {
+	t.Setenv("ANCC_TEST_SECRET", "hunter2")
+	opts := fakeOptions(t)
+
+	res := execute(opts, []string{"env", "--format", "json"})
+	if res.err != nil {
+		t.Fatalf("unexpected error: %v", res.err)
+	}
+	var out struct {
+		Home   string   `json:"home"`
+		Secret string   `json:"secret"`
+		Args   []string `json:"args"`
+	}
+	if err := json.Unmarshal(res.stdout, &out); err != nil {
+		t.Fatalf("invalid JSON %q: %v", res.stdout, err)
+	}
+	if out.Secret != "" {
+		t.Error("host environment leaked into the probed process")
+	}
+	if home, _ := os.UserHomeDir(); out.Home == "" || out.Home == home {
+		t.Errorf("HOME = %q, want a temp dir", out.Home)
+	}
+	if strings.Join(out.Args, " ") != "env --format json" {
+		t.Errorf("args = %v", out.Args)
+	}
+}
+
+func TestDecodeSingleJSON(t *testing.T) {
+	tests := []struct {
+		in      string
+		wantErr bool
+	}{
+		{`{"a": 1}`, false},
+		{"  [1, 2]\n\n", false},
+		{"", true},
+		{"log line\n{}", true},
+		{`{"a": 1}{"b": 2}`, true},
+		{"{\"a\": 1}\ndone", true},
+	}
+	for _, tt := range tests {
+		_, err := decodeSingleJSON([]byte(tt.in))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("decodeSingleJSON(%q) err = %v, wantErr %v", tt.in, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCommandKey(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"mytool run", "run"},
+		{"mytool config show [name]", "config show"},
+		{"run <path>", "run"},
+		{"tool-bin status --format json", "status"},
+	}
+	for _, tt := range tests {
+		if got := commandKey(tt.name, "mytool", "tool-bin"); got != tt.want {
+			t.Errorf("commandKey(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package probe
+
+import "os/exec"
+
+// isolate is a no-op where process groups are unavailable.
+func isolate(*exec.Cmd) {}
+
+// reapGroup cannot detect leftover processes on this platform.
+func reapGroup(*exec.Cmd) bool { return false }
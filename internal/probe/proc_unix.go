@@ -0,0 +1,32 @@
+//go:build unix
+
+package probe
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// isolate starts the command in its own process group so a timeout kills
+// everything it spawned, and leftovers can be found after it exits.
+func isolate(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
+
+// reapGroup reports whether any process in the command's group outlived
+// it, and kills them. Processes that start a new session escape the group
+// and are not seen.
+func reapGroup(cmd *exec.Cmd) bool {
+	if cmd.Process == nil {
+		return false
+	}
+	pgid := cmd.Process.Pid
+	if syscall.Kill(-pgid, 0) != nil {
+		return false
+	}
+	_ = syscall.Kill(-pgid, syscall.SIGKILL)
+	return true
+}
@@ -0,0 +1,193 @@
+package probe
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Conforms checks actual against a documented JSON block and returns a
+// description of the first mismatch, or "" if it conforms. The block is
+// treated as a JSON Schema when it looks like one, otherwise as an example
+// whose keys and value types the output must reproduce.
+func Conforms(doc, actual any) string {
+	if isSchema(doc) {
+		return matchSchema(doc.(map[string]any), actual, "$")
+	}
+	return matchShape(doc, actual, "$")
+}
+
+// isSchema reports whether a documented block is a JSON Schema rather than
+// an example document.
+func isSchema(doc any) bool {
+	m, ok := doc.(map[string]any)
+	if !ok {
+		return false
+	}
+	if _, ok := m["$schema"]; ok {
+		return true
+	}
+	if _, ok := m["type"].(string); !ok {
+		return false
+	}
+	_, hasProps := m["properties"]
+	_, hasItems := m["items"]
+	return hasProps || hasItems
+}
+
+// matchShape compares actual with an example: every documented key must be
+// present with the same JSON type. Extra keys are allowed, and null is
+// accepted anywhere since examples usually show populated values.
+func matchShape(doc, actual any, path string) string {
+	if doc == nil || actual == nil {
+		return ""
+	}
+	switch d := doc.(type) {
+	case map[string]any:
+		a, ok := actual.(map[string]any)
+		if !ok {
+			return mismatch(path, "object", actual)
+		}
+		for _, k := range sortedKeys(d) {
+			v, ok := a[k]
+			if !ok {
+				return fmt.Sprintf("%s.%s: missing", path, k)
+			}
+			if msg := matchShape(d[k], v, path+"."+k); msg != "" {
+				return msg
+			}
+		}
+	case []any:
+		a, ok := actual.([]any)
+		if !ok {
+			return mismatch(path, "array", actual)
+		}
+		if len(d) == 0 {
+			return ""
+		}
+		for i, v := range a {
+			if msg := matchShape(d[0], v, fmt.Sprintf("%s[%d]", path, i)); msg != "" {
+				return msg
+			}
+		}
+	default:
+		if jsonType(doc) != jsonType(actual) {
+			return mismatch(path, jsonType(doc), actual)
+		}
+	}
+	return ""
+}
+
+// matchSchema validates actual against the commonly used subset of JSON
+// Schema: type, properties, required, items and enum.
+func matchSchema(schema map[string]any, actual any, path string) string {
+	if t, ok := schema["type"]; ok && !typeAllowed(t, actual) {
+		return mismatch(path, fmt.Sprint(t), actual)
+	}
+
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, e := range enum {
+			if reflect.DeepEqual(e, actual) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Sprintf("%s: %v is not one of %v", path, actual, enum)
+		}
+	}
+
+	if obj, ok := actual.(map[string]any); ok {
+		if req, ok := schema["required"].([]any); ok {
+			for _, r := range req {
+				if k, ok := r.(string); ok {
+					if _, present := obj[k]; !present {
+						return fmt.Sprintf("%s.%s: missing", path, k)
+					}
+				}
+			}
+		}
+		if props, ok := schema["properties"].(map[string]any); ok {
+			for _, k := range sortedKeys(props) {
+				sub, ok := props[k].(map[string]any)
+				v, present := obj[k]
+				if !ok || !present {
+					continue
+				}
+				if msg := matchSchema(sub, v, path+"."+k); msg != "" {
+					return msg
+				}
+			}
+		}
+	}
+
+	if arr, ok := actual.([]any); ok {
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, v := range arr {
+				if msg := matchSchema(items, v, fmt.Sprintf("%s[%d]", path, i)); msg != "" {
+					return msg
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// typeAllowed checks a schema "type" (a string or list of strings).
+func typeAllowed(t, actual any) bool {
+	var types []any
+	switch x := t.(type) {
+	case string:
+		types = []any{x}
+	case []any:
+		types = x
+	default:
+		return true
+	}
+	got := jsonType(actual)
+	for _, v := range types {
+		want, _ := v.(string)
+		if want == got || (want == "integer" && got == "number" && isInteger(actual)) {
+			return true
+		}
+	}
+	return false
+}
+
+func isInteger(v any) bool {
+	f, ok := v.(float64)
+	return ok && f == float64(int64(f))
+}
+
+// jsonType names the JSON type of a decoded value.
+func jsonType(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func mismatch(path, want string, actual any) string {
+	return fmt.Sprintf("%s: expected %s, got %s", path, want, jsonType(actual))
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,81 @@
+package probe
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func decode(t *testing.T, s string) any {
+	t.Helper()
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("invalid JSON %q: %v", s, err)
+	}
+	return v
+}
+
+func TestConforms_Example(t *testing.T) {
+	doc := `{"status": "ok", "count": 3, "items": [{"name": "x", "tags": ["a"]}], "meta": null}`
+	tests := []struct {
+		actual string
+		want   string // substring of the mismatch, "" for conforming
+	}{
+		{`{"status": "fail", "count": 0, "items": [], "meta": {"x": 1}, "extra": true}`, ""},
+		{`{"status": "ok", "count": 1, "items": [{"name": "a", "tags": []}, {"name": "b", "tags": null}], "meta": null}`, ""},
+		{`{"status": "ok", "items": []}`, "$.count: missing"},
+		{`{"status": 1, "count": 1, "items": [], "meta": null}`, "$.status: expected string, got number"},
+		{`{"status": "ok", "count": 1, "items": [{"name": 5}], "meta": null}`, "$.items[0].name: expected string"},
+		{`[]`, "$: expected object, got array"},
+	}
+	for _, tt := range tests {
+		got := Conforms(decode(t, doc), decode(t, tt.actual))
+		if tt.want == "" && got != "" {
+			t.Errorf("Conforms(%s) = %q, want conforming", tt.actual, got)
+		}
+		if tt.want != "" && !strings.Contains(got, tt.want) {
+			t.Errorf("Conforms(%s) = %q, want %q", tt.actual, got, tt.want)
+		}
+	}
+}
+
+func TestConforms_Schema(t *testing.T) {
+	schema := `{
+	  "$schema": "https://json-schema.org/draft/2020-12/schema",
+	  "type": "object",
+	  "required": ["status", "checks"],
+	  "properties": {
+	    "status": {"type": "string", "enum": ["pass", "fail", "partial"]},
+	    "checks": {"type": "array", "items": {"type": "object", "required": ["name"]}},
+	    "total": {"type": "integer"}
+	  }
+	}`
+	tests := []struct {
+		actual string
+		want   string
+	}{
+		{`{"status": "pass", "checks": [{"name": "a"}], "total": 1}`, ""},
+		{`{"status": "ok", "checks": []}`, "$.status: ok is not one of"},
+		{`{"status": "pass"}`, "$.checks: missing"},
+		{`{"status": "pass", "checks": [{}]}`, "$.checks[0].name: missing"},
+		{`{"status": "pass", "checks": [], "total": 1.5}`, "$.total: expected integer"},
+	}
+	for _, tt := range tests {
+		got := Conforms(decode(t, schema), decode(t, tt.actual))
+		if tt.want == "" && got != "" {
+			t.Errorf("Conforms(%s) = %q, want conforming", tt.actual, got)
+		}
+		if tt.want != "" && !strings.Contains(got, tt.want) {
+			t.Errorf("Conforms(%s) = %q, want %q", tt.actual, got, tt.want)
+		}
+	}
+}
+
+func TestIsSchema(t *testing.T) {
+	if isSchema(decode(t, `{"type": "ok", "count": 1}`)) {
+		t.Error("an example with a type field is not a schema")
+	}
+	if !isSchema(decode(t, `{"type": "object", "properties": {}}`)) {
+		t.Error("expected type+properties to be a schema")
+	}
+}
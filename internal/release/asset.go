@@ -0,0 +1,169 @@
+package release
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// DefaultPlatforms is the minimum matrix a release must cover unless
+// configured otherwise.
+var DefaultPlatforms = []Target{
+	{OS: "darwin", Arch: "amd64"},
+	{OS: "darwin", Arch: "arm64"},
+	{OS: "linux", Arch: "amd64"},
+	{OS: "linux", Arch: "arm64"},
+}
+
+// Asset is a release asset name parsed into the platforms it serves and
+// its packaging format. A macOS universal binary serves two targets.
+type Asset struct {
+	Name    string
+	Targets []Target
+	Format  string // tar.gz, zip, deb, ... or "binary" for a bare executable
+}
+
+// assetFormats maps name suffixes to formats, longest first where one is a
+// suffix of another.
+var assetFormats = []struct{ suffix, format string }{
+	{".tar.gz", "tar.gz"},
+	{".tgz", "tar.gz"},
+	{".tar.xz", "tar.xz"},
+	{".txz", "tar.xz"},
+	{".tar.bz2", "tar.bz2"},
+	{".tar.zst", "tar.zst"},
+	{".zip", "zip"},
+	{".deb", "deb"},
+	{".rpm", "rpm"},
+	{".apk", "apk"},
+	{".msi", "msi"},
+	{".dmg", "dmg"},
+	{".pkg", "pkg"},
+	{".appimage", "appimage"},
+	{".exe", "binary"},
+}
+
+// Checksum, signature, SBOM and provenance files are told apart by suffix.
+// Per-asset ones add the suffix to the name of the asset they cover.
+var (
+	ChecksumSuffixes   = []string{".sha256", ".sha256sum", ".sha512", ".sha512sum"}
+	SignatureSuffixes  = []string{".sigstore.json", ".sigstore", ".bundle", ".sig", ".asc", ".minisig", ".pem"}
+	SBOMSuffixes       = []string{".spdx.json", ".spdx", ".cdx.json", ".cdx.xml", ".cdx", ".cyclonedx.json", ".bom.json", ".sbom.json", ".sbom"}
+	ProvenanceSuffixes = []string{".intoto.jsonl", ".intoto.json", ".provenance.json", ".provenance"}
+)
+
+// nonBinarySuffixes mark checksums, signatures, SBOMs, attestations, keys
+// and metadata.
+var nonBinarySuffixes = slices.Concat(ChecksumSuffixes, SignatureSuffixes, SBOMSuffixes, ProvenanceSuffixes,
+	[]string{".md5", ".crt", ".pub", ".json", ".jsonl", ".txt", ".md", ".yml", ".yaml"})
+
+var reAssetToken = regexp.MustCompile(`[-_.\s]+`)
+
+// ParseAsset reads the OS, architecture and format from an asset name such
+// as "mytool_1.2.0_linux_arm64.tar.gz" or
+// "mytool-x86_64-unknown-linux-musl.tar.xz". It reports false for
+// checksums, signatures, source archives and names without an OS.
+func ParseAsset(name string) (Asset, bool) {
+	if IsAuxiliary(name) {
+		return Asset{}, false
+	}
+	lower := strings.ToLower(name)
+
+	a := Asset{Name: name, Format: "binary"}
+	base := lower
+	for _, f := range assetFormats {
+		if strings.HasSuffix(lower, f.suffix) {
+			a.Format = f.format
+			base = strings.TrimSuffix(lower, f.suffix)
+			break
+		}
+	}
+
+	// Keep architecture names that contain separators in one token.
+	base = strings.NewReplacer("x86_64", "amd64", "x86-64", "amd64").Replace(base)
+
+	// The last OS and architecture tokens win: the platform follows the
+	// project name, which may itself read like one (win-ctl, arm-lint).
+	var osName, arch string
+	universal := false
+	for _, tok := range reAssetToken.Split(base, -1) {
+		switch {
+		case tok == "universal" || tok == "universal2":
+			universal = true
+		case NormalizeOS(tok) != "":
+			osName = NormalizeOS(tok)
+		case NormalizeArch(tok) != "":
+			arch = NormalizeArch(tok)
+		}
+	}
+	if osName == "" && strings.HasSuffix(lower, ".exe") {
+		osName = "windows"
+	}
+	if osName == "" {
+		return Asset{}, false
+	}
+
+	switch {
+	case universal && osName == "darwin":
+		a.Targets = []Target{{OS: "darwin", Arch: "amd64"}, {OS: "darwin", Arch: "arm64"}}
+	default:
+		a.Targets = []Target{{OS: osName, Arch: arch}}
+	}
+	return a, true
+}
+
+// IsAuxiliary reports whether an asset is a checksum, signature,
+// attestation, metadata file or source archive rather than a binary.
+func IsAuxiliary(name string) bool {
+	lower := strings.ToLower(name)
+	for _, s := range nonBinarySuffixes {
+		if strings.HasSuffix(lower, s) {
+			return true
+		}
+	}
+	for _, tok := range reAssetToken.Split(lower, -1) {
+		if tok == "source" || tok == "src" || tok == "sources" {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTargets reads "os/arch" strings such as "linux/amd64".
+func ParseTargets(specs []string) ([]Target, error) {
+	out := make([]Target, 0, len(specs))
+	for _, s := range specs {
+		o, a, ok := strings.Cut(strings.TrimSpace(s), "/")
+		t := Target{OS: NormalizeOS(o), Arch: NormalizeArch(a)}
+		if !ok || t.OS == "" || t.Arch == "" {
+			return nil, fmt.Errorf("invalid platform %q: want os/arch, e.g. linux/amd64", s)
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+// Missing returns the required targets not present in have, in order.
+func Missing(required, have []Target) []Target {
+	got := make(map[Target]bool, len(have))
+	for _, t := range have {
+		got[t] = true
+	}
+	var out []Target
+	for _, t := range required {
+		if !got[t] {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// JoinTargets renders targets as a comma-separated list.
+func JoinTargets(ts []Target) string {
+	names := make([]string, len(ts))
+	for i, t := range ts {
+		names[i] = t.String()
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,72 @@
+package release
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAsset(t *testing.T) {
+	tests := []struct {
+		name    string
+		targets []Target
+		format  string
+		ok      bool
+	}{
+		{"mytool_1.2.0_linux_arm64.tar.gz", []Target{{"linux", "arm64"}}, "tar.gz", true},
+		{"mytool-v1.2.0-Darwin-x86_64.tar.gz", []Target{{"darwin", "amd64"}}, "tar.gz", true},
+		{"mytool-x86_64-unknown-linux-musl.tar.xz", []Target{{"linux", "amd64"}}, "tar.xz", true},
+		{"mytool-aarch64-apple-darwin.zip", []Target{{"darwin", "arm64"}}, "zip", true},
+		{"mytool-macos-universal.tar.gz", []Target{{"darwin", "amd64"}, {"darwin", "arm64"}}, "tar.gz", true},
+		{"mytool_windows_amd64.exe", []Target{{"windows", "amd64"}}, "binary", true},
+		{"mytool-linux-amd64", []Target{{"linux", "amd64"}}, "binary", true},
+		{"win-ctl_1.0_linux_amd64.tar.gz", []Target{{"linux", "amd64"}}, "tar.gz", true},
+		{"mac-notify_1.0_linux_amd64.tar.gz", []Target{{"linux", "amd64"}}, "tar.gz", true},
+		{"arm-lint_1.0_linux_amd64.tar.gz", []Target{{"linux", "amd64"}}, "tar.gz", true},
+		{"mytool_1.2.0_amd64.deb", nil, "", false},
+		{"mytool-1.2.0-source.zip", nil, "", false},
+		{"mytool_1.2.0_linux_amd64.tar.gz.sha256", nil, "", false},
+		{"mytool_linux_amd64.tar.gz.sha512sum", nil, "", false},
+		{"mytool_linux_amd64.tar.gz.minisig", nil, "", false},
+		{"mytool_linux_amd64.tar.gz.sigstore", nil, "", false},
+		{"mytool_linux_amd64.cdx.xml", nil, "", false},
+		{"checksums.txt", nil, "", false},
+		{"mytool.zip", nil, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, ok := ParseAsset(tt.name)
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, want %v (%+v)", ok, tt.ok, a)
+			}
+			if !ok {
+				return
+			}
+			if !reflect.DeepEqual(a.Targets, tt.targets) || a.Format != tt.format {
+				t.Errorf("got %v as %q, want %v as %q", a.Targets, a.Format, tt.targets, tt.format)
+			}
+		})
+	}
+}
+
+func TestParseTargets(t *testing.T) {
+	got, err := ParseTargets([]string{"linux/amd64", "macos/aarch64"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []Target{{"linux", "amd64"}, {"darwin", "arm64"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if _, err := ParseTargets([]string{"linux"}); err == nil {
+		t.Error("expected error for a target without arch")
+	}
+}
+
+func TestMissing(t *testing.T) {
+	have := []Target{{"linux", "amd64"}, {"darwin", ""}}
+	got := Missing(DefaultPlatforms, have)
+	want := []Target{{"darwin", "amd64"}, {"darwin", "arm64"}, {"linux", "arm64"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Missing = %v, want %v", got, want)
+	}
+}
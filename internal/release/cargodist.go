@@ -0,0 +1,98 @@
+package release
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"strings"
+)
+
+// cargo-dist keeps its settings in a TOML table. Only the keys needed here
+// are read, so a line scanner stands in for a TOML parser.
+var cargoDistFiles = []struct {
+	name  string
+	table string
+}{
+	{"dist-workspace.toml", "dist"},
+	{"dist.toml", "dist"},
+	{"Cargo.toml", "workspace.metadata.dist"},
+	{"Cargo.toml", "package.metadata.dist"},
+}
+
+var (
+	reTOMLTable  = regexp.MustCompile(`^\s*\[\s*([\w.-]+)\s*\]\s*(#.*)?$`)
+	reTOMLKey    = regexp.MustCompile(`^\s*([\w-]+)\s*=\s*(.*)$`)
+	reTOMLString = regexp.MustCompile(`"([^"]*)"`)
+)
+
+// detectCargoDist reads the cargo-dist table from its config file.
+func detectCargoDist(fsys fs.FS) (*Pipeline, error) {
+	for _, f := range cargoDistFiles {
+		data, err := fs.ReadFile(fsys, f.name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", f.name, err)
+		}
+		keys, ok := tomlTable(string(data), f.table)
+		if !ok {
+			continue
+		}
+
+		p := &Pipeline{Tool: "cargo-dist", Source: f.name}
+		windows := false
+		for _, triple := range reTOMLString.FindAllStringSubmatch(keys["targets"], -1) {
+			if t, ok := ParseTriple(triple[1]); ok {
+				p.Targets = append(p.Targets, t)
+				windows = windows || t.OS == "windows"
+			}
+		}
+		p.Formats = []string{archiveValue(keys["unix-archive"], "tar.xz")}
+		if windows {
+			p.Formats = append(p.Formats, archiveValue(keys["windows-archive"], "zip"))
+		}
+		return p, nil
+	}
+	return nil, nil
+}
+
+// tomlTable returns the raw values of the keys in one table. Multi-line
+// arrays are joined onto a single value.
+func tomlTable(src, table string) (map[string]string, bool) {
+	keys := make(map[string]string)
+	found, inTable := false, false
+	var pending string
+	for _, line := range strings.Split(src, "\n") {
+		if pending != "" {
+			keys[pending] += " " + line
+			if strings.Contains(line, "]") {
+				pending = ""
+			}
+			continue
+		}
+		if m := reTOMLTable.FindStringSubmatch(line); m != nil {
+			inTable = m[1] == table
+			found = found || inTable
+			continue
+		}
+		if !inTable {
+			continue
+		}
+		if m := reTOMLKey.FindStringSubmatch(line); m != nil {
+			keys[m[1]] = m[2]
+			if strings.HasPrefix(strings.TrimSpace(m[2]), "[") && !strings.Contains(m[2], "]") {
+				pending = m[1]
+			}
+		}
+	}
+	return keys, found
+}
+
+func archiveValue(raw, def string) string {
+	if m := reTOMLString.FindStringSubmatch(raw); m != nil {
+		return strings.TrimPrefix(m[1], ".")
+	}
+	return def
+}
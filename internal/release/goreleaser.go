@@ -0,0 +1,319 @@
+package release
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+var goreleaserFiles = []string{
+	".goreleaser.yml",
+	".goreleaser.yaml",
+	"goreleaser.yml",
+	"goreleaser.yaml",
+}
+
+// GoReleaser's defaults when a build omits goos or goarch. darwin/386 is
+// not a supported Go port and is always skipped.
+var (
+	goreleaserDefaultOS   = []string{"darwin", "linux", "windows"}
+	goreleaserDefaultArch = []string{"386", "amd64", "arm64"}
+)
+
+type goreleaserConfig struct {
+	ProjectName string              `yaml:"project_name"`
+	Builds      []goreleaserBuild   `yaml:"builds"`
+	Archives    []goreleaserArchive `yaml:"archives"`
+	Checksum    struct {
+		NameTemplate string `yaml:"name_template"`
+		Disable      any    `yaml:"disable"`
+	} `yaml:"checksum"`
+}
+
+type goreleaserBuild struct {
+	Skip    any      `yaml:"skip"`
+	GOOS    []string `yaml:"goos"`
+	GOARCH  []string `yaml:"goarch"`
+	Targets []string `yaml:"targets"`
+	Ignore  []struct {
+		GOOS   string `yaml:"goos"`
+		GOARCH string `yaml:"goarch"`
+	} `yaml:"ignore"`
+}
+
+type goreleaserArchive struct {
+	NameTemplate    string   `yaml:"name_template"`
+	Format          string   `yaml:"format"`
+	Formats         []string `yaml:"formats"`
+	FormatOverrides []struct {
+		GOOS    string   `yaml:"goos"`
+		Format  string   `yaml:"format"`
+		Formats []string `yaml:"formats"`
+	} `yaml:"format_overrides"`
+}
+
+// loadGoreleaser reads the first GoReleaser config present. It returns a
+// nil config when there is none.
+func loadGoreleaser(fsys fs.FS) (*goreleaserConfig, string, error) {
+	for _, name := range goreleaserFiles {
+		data, err := fs.ReadFile(fsys, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("reading %s: %w", name, err)
+		}
+		var cfg goreleaserConfig
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return nil, "", fmt.Errorf("parsing %s: %w", name, err)
+		}
+		return &cfg, name, nil
+	}
+	return nil, "", nil
+}
+
+// detectGoreleaser reads a GoReleaser config. A config without builds
+// still releases the main package with default settings.
+func detectGoreleaser(fsys fs.FS) (*Pipeline, error) {
+	cfg, name, err := loadGoreleaser(fsys)
+	if err != nil || cfg == nil {
+		return nil, err
+	}
+	return &Pipeline{
+		Tool:    "goreleaser",
+		Source:  name,
+		Targets: cfg.targets(),
+		Formats: cfg.formats(),
+	}, nil
+}
+
+func (c goreleaserConfig) targets() []Target {
+	builds := c.Builds
+	if len(builds) == 0 {
+		builds = []goreleaserBuild{{}}
+	}
+	var out []Target
+	for _, b := range builds {
+		if skip, _ := b.Skip.(bool); skip {
+			continue
+		}
+		out = append(out, b.targets()...)
+	}
+	return out
+}
+
+func (b goreleaserBuild) targets() []Target {
+	var out []Target
+	if explicit := explicitTargets(b.Targets); explicit != nil {
+		return explicit
+	}
+	goos, goarch := b.GOOS, b.GOARCH
+	if len(goos) == 0 {
+		goos = goreleaserDefaultOS
+	}
+	if len(goarch) == 0 {
+		goarch = goreleaserDefaultArch
+	}
+	for _, o := range goos {
+		for _, a := range goarch {
+			if (o == "darwin" && a == "386") || b.ignored(o, a) {
+				continue
+			}
+			out = append(out, Target{OS: o, Arch: a})
+		}
+	}
+	return out
+}
+
+// explicitTargets parses "linux_amd64" style entries. Presets such as
+// "go_first_class" fall back to goos and goarch.
+func explicitTargets(targets []string) []Target {
+	var out []Target
+	for _, t := range targets {
+		parts := strings.Split(t, "_")
+		if len(parts) < 2 || parts[0] == "go" {
+			return nil
+		}
+		out = append(out, Target{OS: parts[0], Arch: parts[1]})
+	}
+	return out
+}
+
+func (b goreleaserBuild) ignored(goos, goarch string) bool {
+	for _, ig := range b.Ignore {
+		if (ig.GOOS == "" || ig.GOOS == goos) && (ig.GOARCH == "" || ig.GOARCH == goarch) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c goreleaserConfig) formats() []string {
+	if len(c.Archives) == 0 {
+		return []string{"tar.gz"}
+	}
+	var out []string
+	for _, a := range c.Archives {
+		switch {
+		case len(a.Formats) > 0:
+			out = append(out, a.Formats...)
+		case a.Format != "":
+			out = append(out, a.Format)
+		default:
+			out = append(out, "tar.gz")
+		}
+		for _, o := range a.FormatOverrides {
+			out = append(out, o.Formats...)
+			out = append(out, o.Format)
+		}
+	}
+	return out
+}
+
+// Default GoReleaser name templates. The archive extension is appended
+// after rendering.
+const (
+	goreleaserArchiveTemplate  = `{{ .ProjectName }}_{{ .Version }}_{{ .Os }}_{{ .Arch }}{{ with .Arm }}v{{ . }}{{ end }}`
+	goreleaserChecksumTemplate = `{{ .ProjectName }}_{{ .Version }}_checksums.txt`
+)
+
+var goreleaserFuncs = template.FuncMap{
+	"title":      func(s string) string { return strings.ToUpper(s[:min(1, len(s))]) + s[min(1, len(s)):] },
+	"tolower":    strings.ToLower,
+	"toupper":    strings.ToUpper,
+	"replace":    strings.ReplaceAll,
+	"trimprefix": strings.TrimPrefix,
+	"trimsuffix": strings.TrimSuffix,
+}
+
+// ArchiveNames renders the asset names a GoReleaser config would publish
+// for version: one archive per target and format, plus the checksum file.
+// The project name defaults to the go.mod module's last element, then to
+// fallback. It reports false when there is no GoReleaser config.
+func ArchiveNames(fsys fs.FS, fallback, version string) ([]string, bool, error) {
+	cfg, name, err := loadGoreleaser(fsys)
+	if err != nil || cfg == nil {
+		return nil, false, err
+	}
+	project := cfg.ProjectName
+	if project == "" {
+		project = modulePackage(fsys, fallback)
+	}
+
+	archives := cfg.Archives
+	if len(archives) == 0 {
+		archives = []goreleaserArchive{{}}
+	}
+	var out []string
+	for _, a := range archives {
+		tmpl := a.NameTemplate
+		if tmpl == "" {
+			tmpl = goreleaserArchiveTemplate
+		}
+		for _, t := range cfg.targets() {
+			base, err := renderName(tmpl, project, version, t)
+			if err != nil {
+				return nil, true, fmt.Errorf("%s: archive name_template: %w", name, err)
+			}
+			for _, f := range a.formatsFor(t.OS) {
+				out = append(out, archiveFile(base, f, t.OS))
+			}
+		}
+	}
+
+	if disabled, _ := cfg.Checksum.Disable.(bool); !disabled {
+		tmpl := cfg.Checksum.NameTemplate
+		if tmpl == "" {
+			tmpl = goreleaserChecksumTemplate
+		}
+		sum, err := renderName(tmpl, project, version, Target{})
+		if err != nil {
+			return nil, true, fmt.Errorf("%s: checksum name_template: %w", name, err)
+		}
+		out = append(out, sum)
+	}
+	return out, true, nil
+}
+
+// formatsFor returns the archive formats used for goos, honouring
+// format_overrides.
+func (a goreleaserArchive) formatsFor(goos string) []string {
+	for _, o := range a.FormatOverrides {
+		if o.GOOS != goos {
+			continue
+		}
+		if len(o.Formats) > 0 {
+			return o.Formats
+		}
+		return []string{o.Format}
+	}
+	switch {
+	case len(a.Formats) > 0:
+		return a.Formats
+	case a.Format != "":
+		return []string{a.Format}
+	}
+	return []string{"tar.gz"}
+}
+
+// archiveFile appends the extension GoReleaser gives format. Bare binaries
+// keep their name, plus .exe on Windows.
+func archiveFile(base, format, goos string) string {
+	switch format {
+	case "binary":
+		if goos == "windows" {
+			return base + ".exe"
+		}
+		return base
+	case "none":
+		return base
+	}
+	return base + "." + format
+}
+
+func renderName(tmpl, project, version string, t Target) (string, error) {
+	parsed, err := template.New("name").Funcs(goreleaserFuncs).Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+	arch, arm := t.Arch, ""
+	if strings.HasPrefix(arch, "armv") {
+		arch, arm = "arm", strings.TrimPrefix(arch, "armv")
+	}
+	data := map[string]any{
+		"ProjectName": project,
+		"Version":     version,
+		"Tag":         "v" + version,
+		"Os":          t.OS,
+		"Arch":        arch,
+		"Arm":         arm,
+		"Amd64":       "v1",
+		"Env":         map[string]string{},
+	}
+	var b strings.Builder
+	if err := parsed.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// modulePackage returns the last element of the go.mod module path, or
+// fallback when there is no go.mod.
+func modulePackage(fsys fs.FS, fallback string) string {
+	data, err := fs.ReadFile(fsys, "go.mod")
+	if err != nil {
+		return fallback
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if mod, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+			mod = strings.Trim(strings.TrimSpace(mod), `"`)
+			return path.Base(mod)
+		}
+	}
+	return fallback
+}
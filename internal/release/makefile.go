@@ -0,0 +1,131 @@
+package release
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"strings"
+)
+
+var makefileNames = []string{"GNUmakefile", "makefile", "Makefile"}
+
+var (
+	reMakeRule     = regexp.MustCompile(`^([A-Za-z0-9_.-]+(?:\s+[A-Za-z0-9_.-]+)*)\s*:([^=].*|)$`)
+	reMakeVar      = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\s*[:?+]?=\s*(.*)$`)
+	reGOOS         = regexp.MustCompile(`\bGOOS=([A-Za-z0-9]+)`)
+	reGOARCH       = regexp.MustCompile(`\bGOARCH=([A-Za-z0-9]+)`)
+	reMakeRelease  = regexp.MustCompile(`(^|[-_])(release|dist|cross)([-_]|$)`)
+	reCrossCommand = regexp.MustCompile(`\b(go build|cargo build|cross build|goreleaser|gh release)\b`)
+)
+
+type makeRule struct {
+	prereqs []string
+	recipe  []string
+}
+
+// detectMakefile looks for a release-style Makefile target (release, dist,
+// cross-*) that cross-compiles or publishes. Platforms come from GOOS and
+// GOARCH assignments in the recipes it reaches and from *PLATFORMS
+// variables listing os/arch pairs.
+func detectMakefile(fsys fs.FS) (*Pipeline, error) {
+	for _, name := range makefileNames {
+		data, err := fs.ReadFile(fsys, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", name, err)
+		}
+		if p := makefilePipeline(name, string(data)); p != nil {
+			return p, nil
+		}
+		return nil, nil
+	}
+	return nil, nil
+}
+
+func makefilePipeline(name, src string) *Pipeline {
+	rules := make(map[string]*makeRule)
+	var order []string
+	var platforms []Target
+	var current []*makeRule
+	for _, line := range strings.Split(src, "\n") {
+		if strings.HasPrefix(line, "\t") {
+			for _, r := range current {
+				r.recipe = append(r.recipe, line)
+			}
+			continue
+		}
+		current = nil
+		if m := reMakeVar.FindStringSubmatch(line); m != nil {
+			if strings.Contains(strings.ToUpper(m[1]), "PLATFORMS") {
+				for _, f := range strings.Fields(m[2]) {
+					if t, ok := parsePair(f); ok {
+						platforms = append(platforms, t)
+					}
+				}
+			}
+			continue
+		}
+		m := reMakeRule.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		prereqs, _, _ := strings.Cut(m[2], ";")
+		for _, target := range strings.Fields(m[1]) {
+			r, ok := rules[target]
+			if !ok {
+				r = &makeRule{}
+				rules[target] = r
+				order = append(order, target)
+			}
+			r.prereqs = append(r.prereqs, strings.Fields(prereqs)...)
+			current = append(current, r)
+		}
+	}
+
+	for _, target := range order {
+		if !reMakeRelease.MatchString(target) {
+			continue
+		}
+		recipe := expandRecipe(rules, target, make(map[string]bool))
+		p := &Pipeline{Tool: "make", Source: name + ":" + target}
+		builds := false
+		for _, line := range recipe {
+			builds = builds || reCrossCommand.MatchString(line)
+			p.Formats = append(p.Formats, scriptFormats(strings.TrimSpace(line))...)
+			goos, goarch := reGOOS.FindStringSubmatch(line), reGOARCH.FindStringSubmatch(line)
+			if goos == nil {
+				continue
+			}
+			t := Target{OS: NormalizeOS(goos[1])}
+			if goarch != nil {
+				t.Arch = NormalizeArch(goarch[1])
+			}
+			p.Targets = append(p.Targets, t)
+		}
+		if !builds {
+			continue
+		}
+		if len(p.Targets) == 0 {
+			p.Targets = platforms
+		}
+		return p
+	}
+	return nil
+}
+
+// expandRecipe collects the recipe lines of target and its prerequisites.
+func expandRecipe(rules map[string]*makeRule, target string, seen map[string]bool) []string {
+	r, ok := rules[target]
+	if !ok || seen[target] {
+		return nil
+	}
+	seen[target] = true
+	var out []string
+	for _, p := range r.prereqs {
+		out = append(out, expandRecipe(rules, p, seen)...)
+	}
+	return append(out, r.recipe...)
+}
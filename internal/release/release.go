@@ -0,0 +1,165 @@
+// Package release finds the binary release pipeline a repo defines and
+// works out which platforms and archive formats it would publish.
+package release
+
+import (
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+)
+
+// Target is an OS/arch pair such as linux/amd64. Arch is empty when a
+// pipeline names the OS but the architecture cannot be determined.
+type Target struct {
+	OS   string
+	Arch string
+}
+
+func (t Target) String() string {
+	if t.Arch == "" {
+		return t.OS
+	}
+	return t.OS + "/" + t.Arch
+}
+
+// Pipeline describes a binary release configuration found in a repo.
+type Pipeline struct {
+	// Tool names the release mechanism: goreleaser, cargo-dist, workflow or make.
+	Tool string
+	// Source is the file that defines the pipeline, relative to the repo root.
+	Source string
+	// Targets are the platforms that would be published, sorted. Empty when
+	// the pipeline publishes binaries but its platforms cannot be determined.
+	Targets []Target
+	// Formats are the archive formats, such as tar.gz or zip, sorted.
+	Formats []string
+}
+
+// Summary renders the pipeline for a check message.
+func (p *Pipeline) Summary() string {
+	s := fmt.Sprintf("%s (%s)", p.Tool, p.Source)
+	if len(p.Targets) == 0 {
+		s += ": platforms not determined"
+	} else {
+		s += ": " + JoinTargets(p.Targets)
+	}
+	if len(p.Formats) > 0 {
+		s += " as " + strings.Join(p.Formats, ", ")
+	}
+	return s
+}
+
+// detectors are consulted in order. Dedicated release tools come first
+// since workflows and Makefiles usually just invoke them.
+var detectors = []func(fs.FS) (*Pipeline, error){
+	detectGoreleaser,
+	detectCargoDist,
+	detectWorkflow,
+	detectMakefile,
+}
+
+// Detect returns the first release pipeline found in fsys, or nil.
+func Detect(fsys fs.FS) (*Pipeline, error) {
+	for _, d := range detectors {
+		p, err := d(fsys)
+		if err != nil {
+			return nil, err
+		}
+		if p != nil {
+			p.Targets = SortTargets(p.Targets)
+			p.Formats = sortStrings(p.Formats)
+			return p, nil
+		}
+	}
+	return nil, nil
+}
+
+// NormalizeOS maps the spellings used by build tools, runners and asset
+// names to GOOS values. Unknown names yield "".
+func NormalizeOS(s string) string {
+	switch strings.ToLower(s) {
+	case "linux", "ubuntu", "debian", "alpine":
+		return "linux"
+	case "darwin", "macos", "mac", "osx", "apple", "macosx":
+		return "darwin"
+	case "windows", "win", "win32", "win64":
+		return "windows"
+	case "freebsd", "openbsd", "netbsd", "android", "illumos", "solaris":
+		return strings.ToLower(s)
+	}
+	return ""
+}
+
+// NormalizeArch maps architecture spellings to GOARCH values. Unknown
+// names yield "".
+func NormalizeArch(s string) string {
+	switch strings.ToLower(s) {
+	case "amd64", "x86_64", "x86-64", "x64", "64bit":
+		return "amd64"
+	case "arm64", "aarch64", "armv8":
+		return "arm64"
+	case "386", "i386", "i686", "x86", "32bit":
+		return "386"
+	case "arm", "armv6", "armv7", "armv7l", "armhf":
+		return "arm"
+	case "riscv64", "ppc64le", "s390x", "mips64":
+		return strings.ToLower(s)
+	}
+	return ""
+}
+
+// ParseTriple converts a Rust target triple such as
+// "aarch64-apple-darwin" or "x86_64-pc-windows-msvc" into a Target.
+func ParseTriple(triple string) (Target, bool) {
+	parts := strings.Split(triple, "-")
+	if len(parts) < 3 {
+		return Target{}, false
+	}
+	arch := NormalizeArch(parts[0])
+	var osName string
+	for _, p := range parts[1:] {
+		if osName = NormalizeOS(p); osName != "" {
+			break
+		}
+	}
+	if arch == "" || osName == "" {
+		return Target{}, false
+	}
+	return Target{OS: osName, Arch: arch}, true
+}
+
+// SortTargets returns ts sorted by OS and arch without duplicates or
+// entries lacking an OS.
+func SortTargets(ts []Target) []Target {
+	seen := make(map[Target]bool)
+	var out []Target
+	for _, t := range ts {
+		if t.OS == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		out = append(out, t)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].OS != out[j].OS {
+			return out[i].OS < out[j].OS
+		}
+		return out[i].Arch < out[j].Arch
+	})
+	return out
+}
+
+func sortStrings(ss []string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, s := range ss {
+		if s == "" || seen[s] {
+			continue
+		}
+		seen[s] = true
+		out = append(out, s)
+	}
+	sort.Strings(out)
+	return out
+}
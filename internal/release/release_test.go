@@ -0,0 +1,293 @@
+package release
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func targetNames(p *Pipeline) []string {
+	var out []string
+	for _, t := range p.Targets {
+		out = append(out, t.String())
+	}
+	return out
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name        string
+		files       map[string]string
+		wantTool    string
+		wantTargets []string
+		wantFormats []string
+	}{
+		{
+			name: "goreleaser",
+			files: map[string]string{".goreleaser.yml": `builds:
+  - goos: [linux, darwin, windows]
+    goarch: [amd64, arm64]
+    ignore:
+      - goos: windows
+        goarch: arm64
+archives:
+  - format: tar.gz
+    format_overrides:
+      - goos: windows
+        format: zip
+`},
+			wantTool:    "goreleaser",
+			wantTargets: []string{"darwin/amd64", "darwin/arm64", "linux/amd64", "linux/arm64", "windows/amd64"},
+			wantFormats: []string{"tar.gz", "zip"},
+		},
+		{
+			name:        "goreleaser defaults",
+			files:       map[string]string{".goreleaser.yaml": "version: 2\n"},
+			wantTool:    "goreleaser",
+			wantTargets: []string{"darwin/amd64", "darwin/arm64", "linux/386", "linux/amd64", "linux/arm64", "windows/386", "windows/amd64", "windows/arm64"},
+			wantFormats: []string{"tar.gz"},
+		},
+		{
+			name: "cargo-dist in Cargo.toml",
+			files: map[string]string{"Cargo.toml": `[package]
+name = "mytool"
+
+[workspace.metadata.dist]
+cargo-dist-version = "0.22.1"
+targets = [
+    "aarch64-apple-darwin",
+    "x86_64-unknown-linux-gnu",
+    "x86_64-pc-windows-msvc",
+]
+unix-archive = ".tar.gz"
+`},
+			wantTool:    "cargo-dist",
+			wantTargets: []string{"darwin/arm64", "linux/amd64", "windows/amd64"},
+			wantFormats: []string{"tar.gz", "zip"},
+		},
+		{
+			name: "workflow matrix",
+			files: map[string]string{".github/workflows/release.yml": `on:
+  push:
+    tags: ["v*"]
+jobs:
+  build:
+    strategy:
+      matrix:
+        include:
+          - os: ubuntu-latest
+            target: x86_64-unknown-linux-musl
+          - os: macos-14
+          - os: windows-latest
+    steps:
+      - run: tar czf mytool.tar.gz mytool
+      - uses: softprops/action-gh-release@v2
+`},
+			wantTool:    "workflow",
+			wantTargets: []string{"darwin/arm64", "linux/amd64", "windows/amd64"},
+			wantFormats: []string{"tar.gz"},
+		},
+		{
+			name: "workflow goos goarch",
+			files: map[string]string{".github/workflows/release.yaml": `on: release
+jobs:
+  build:
+    strategy:
+      matrix:
+        goos: [linux, darwin]
+        goarch: [amd64, arm64]
+    steps:
+      - run: gh release upload "$TAG" dist/*
+`},
+			wantTool:    "workflow",
+			wantTargets: []string{"darwin/amd64", "darwin/arm64", "linux/amd64", "linux/arm64"},
+		},
+		{
+			name: "makefile",
+			files: map[string]string{"Makefile": `VERSION ?= dev
+
+build:
+	go build -o bin/mytool .
+
+release: release-linux release-darwin
+	gh release create $(VERSION) dist/*
+
+release-linux:
+	GOOS=linux GOARCH=amd64 go build -o dist/mytool-linux-amd64 .
+
+release-darwin:
+	GOOS=darwin GOARCH=arm64 go build -o dist/mytool-darwin-arm64 .
+`},
+			wantTool:    "make",
+			wantTargets: []string{"darwin/arm64", "linux/amd64"},
+		},
+		{
+			name: "makefile platforms variable",
+			files: map[string]string{"Makefile": `PLATFORMS := linux/amd64 darwin/arm64
+
+dist:
+	./scripts/cross.sh $(PLATFORMS) && go build ./...
+`},
+			wantTool:    "make",
+			wantTargets: []string{"darwin/arm64", "linux/amd64"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := fstest.MapFS{}
+			for name, data := range tt.files {
+				fsys[name] = &fstest.MapFile{Data: []byte(data)}
+			}
+			p, err := Detect(fsys)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if p == nil {
+				t.Fatal("no pipeline detected")
+			}
+			if p.Tool != tt.wantTool {
+				t.Errorf("tool = %q, want %q", p.Tool, tt.wantTool)
+			}
+			if got := targetNames(p); !reflect.DeepEqual(got, tt.wantTargets) {
+				t.Errorf("targets = %v, want %v", got, tt.wantTargets)
+			}
+			if !reflect.DeepEqual(p.Formats, tt.wantFormats) {
+				t.Errorf("formats = %v, want %v", p.Formats, tt.wantFormats)
+			}
+		})
+	}
+}
+
+func TestDetect_NoPipeline(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Makefile": {Data: []byte("build:\n\tgo build ./...\n")},
+		".github/workflows/ci.yml": {Data: []byte(`on: [push, pull_request]
+jobs:
+  test:
+    steps:
+      - run: go test ./...
+`)},
+		".github/workflows/tag.yml": {Data: []byte(`on:
+  push:
+    tags: ["v*"]
+jobs:
+  notify:
+    steps:
+      - run: echo tagged
+`)},
+	}
+	p, err := Detect(fsys)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p != nil {
+		t.Errorf("expected no pipeline, got %+v", p)
+	}
+}
+
+func TestDetect_InvalidConfig(t *testing.T) {
+	fsys := fstest.MapFS{".goreleaser.yml": {Data: []byte("builds: [unclosed")}}
+	if _, err := Detect(fsys); err == nil {
+		t.Error("expected error for invalid YAML")
+	}
+}
+
+func TestParseTriple(t *testing.T) {
+	tests := []struct {
+		triple string
+		want   Target
+		ok     bool
+	}{
+		{"x86_64-unknown-linux-gnu", Target{"linux", "amd64"}, true},
+		{"aarch64-apple-darwin", Target{"darwin", "arm64"}, true},
+		{"x86_64-pc-windows-msvc", Target{"windows", "amd64"}, true},
+		{"armv7-unknown-linux-gnueabihf", Target{"linux", "arm"}, true},
+		{"wasm32-wasi", Target{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseTriple(tt.triple)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ParseTriple(%q) = %v, %v; want %v, %v", tt.triple, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestPipelineSummary(t *testing.T) {
+	p := &Pipeline{
+		Tool:    "goreleaser",
+		Source:  ".goreleaser.yml",
+		Targets: []Target{{"darwin", "arm64"}, {"linux", "amd64"}},
+		Formats: []string{"tar.gz"},
+	}
+	want := "goreleaser (.goreleaser.yml): darwin/arm64, linux/amd64 as tar.gz"
+	if got := p.Summary(); got != want {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+}
+
+func TestArchiveNames(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []string
+	}{
+		{
+			name: "custom templates",
+			files: map[string]string{".goreleaser.yml": `project_name: tool
+builds:
+  - goos: [linux, windows]
+    goarch: [amd64]
+archives:
+  - name_template: "{{ .ProjectName }}-{{ .Version }}-{{ .Os }}-{{ if eq .Arch \"amd64\" }}x86_64{{ else }}{{ .Arch }}{{ end }}"
+    format_overrides:
+      - goos: windows
+        format: zip
+checksum:
+  name_template: checksums.txt
+`},
+			want: []string{"tool-1.0.0-linux-x86_64.tar.gz", "tool-1.0.0-windows-x86_64.zip", "checksums.txt"},
+		},
+		{
+			name: "defaults use go.mod module name",
+			files: map[string]string{
+				".goreleaser.yaml": "builds:\n  - goos: [darwin]\n    goarch: [arm64]\n",
+				"go.mod":           "module github.com/acme/widget\n\ngo 1.24\n",
+			},
+			want: []string{"widget_1.0.0_darwin_arm64.tar.gz", "widget_1.0.0_checksums.txt"},
+		},
+		{
+			name:  "project falls back to directory name",
+			files: map[string]string{".goreleaser.yml": "builds:\n  - goos: [linux]\n    goarch: [arm64]\nchecksum:\n  disable: true\n"},
+			want:  []string{"dir_1.0.0_linux_arm64.tar.gz"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := fstest.MapFS{}
+			for name, data := range tt.files {
+				fsys[name] = &fstest.MapFile{Data: []byte(data)}
+			}
+			got, ok, err := ArchiveNames(fsys, "dir", "1.0.0")
+			if err != nil || !ok {
+				t.Fatalf("ArchiveNames: %v, %v", ok, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestArchiveNames_NoConfig(t *testing.T) {
+	names, ok, err := ArchiveNames(fstest.MapFS{}, "dir", "1.0.0")
+	if names != nil || ok || err != nil {
+		t.Errorf("got %v, %v, %v; want nil, false, nil", names, ok, err)
+	}
+}
+
+func TestArchiveNames_BadTemplate(t *testing.T) {
+	fsys := fstest.MapFS{".goreleaser.yml": {Data: []byte("archives:\n  - name_template: \"{{ .Os \"\n")}}
+	if _, _, err := ArchiveNames(fsys, "dir", "1.0.0"); err == nil {
+		t.Error("expected an error for an unparsable name_template")
+	}
+}
@@ -0,0 +1,283 @@
+package release
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const workflowDir = ".github/workflows"
+
+// publishActions upload release assets when used in a step.
+var publishActions = []string{
+	"goreleaser/goreleaser-action",
+	"softprops/action-gh-release",
+	"ncipollo/release-action",
+	"svenstaro/upload-release-action",
+	"actions/upload-release-asset",
+	"taiki-e/upload-rust-binary-action",
+}
+
+// publishCommands upload release assets when they appear in a run step.
+var publishCommands = []string{
+	"gh release create",
+	"gh release upload",
+	"goreleaser release",
+	"dist host",
+	"cargo dist",
+}
+
+// matrixKeys are the matrix variables that can carry platform information.
+var matrixKeys = []string{"os", "goos", "goarch", "arch", "target", "platform"}
+
+type workflowFile struct {
+	On   any                    `yaml:"on"`
+	Jobs map[string]workflowJob `yaml:"jobs"`
+}
+
+type workflowJob struct {
+	Strategy struct {
+		Matrix map[string]any `yaml:"matrix"`
+	} `yaml:"strategy"`
+	Steps []struct {
+		Uses string `yaml:"uses"`
+		Run  string `yaml:"run"`
+	} `yaml:"steps"`
+}
+
+// detectWorkflow finds a GitHub Actions workflow that runs on tags or
+// releases and uploads assets. Platforms come from the build matrix.
+func detectWorkflow(fsys fs.FS) (*Pipeline, error) {
+	entries, err := fs.ReadDir(fsys, workflowDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", workflowDir, err)
+	}
+
+	for _, e := range entries {
+		ext := path.Ext(e.Name())
+		if e.IsDir() || (ext != ".yml" && ext != ".yaml") {
+			continue
+		}
+		name := path.Join(workflowDir, e.Name())
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", name, err)
+		}
+		var wf workflowFile
+		if err := yaml.Unmarshal(data, &wf); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", name, err)
+		}
+		if !releaseTriggered(wf.On) {
+			continue
+		}
+		if p := wf.pipeline(name); p != nil {
+			return p, nil
+		}
+	}
+	return nil, nil
+}
+
+// releaseTriggered reports whether the workflow runs for a published
+// release or a pushed tag.
+func releaseTriggered(on any) bool {
+	switch v := on.(type) {
+	case string:
+		return v == "release"
+	case []any:
+		for _, e := range v {
+			if e == "release" {
+				return true
+			}
+		}
+	case map[string]any:
+		if _, ok := v["release"]; ok {
+			return true
+		}
+		if push, ok := v["push"].(map[string]any); ok {
+			_, tags := push["tags"]
+			return tags
+		}
+	}
+	return false
+}
+
+func (wf workflowFile) pipeline(name string) *Pipeline {
+	names := make([]string, 0, len(wf.Jobs))
+	for n := range wf.Jobs {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	publishes, viaGoreleaser := false, false
+	p := &Pipeline{Tool: "workflow", Source: name}
+	for _, n := range names {
+		job := wf.Jobs[n]
+		p.Targets = append(p.Targets, matrixTargets(job.Strategy.Matrix)...)
+		for _, s := range job.Steps {
+			for _, a := range publishActions {
+				if strings.HasPrefix(s.Uses, a+"@") || s.Uses == a {
+					publishes = true
+					viaGoreleaser = viaGoreleaser || a == "goreleaser/goreleaser-action"
+				}
+			}
+			for _, c := range publishCommands {
+				if strings.Contains(s.Run, c) {
+					publishes = true
+					viaGoreleaser = viaGoreleaser || c == "goreleaser release"
+				}
+			}
+			p.Formats = append(p.Formats, scriptFormats(s.Run)...)
+		}
+	}
+	if !publishes {
+		return nil
+	}
+	if viaGoreleaser && len(p.Targets) == 0 {
+		// GoReleaser without a config file builds its default matrix.
+		cfg := goreleaserConfig{}
+		p.Targets, p.Formats = cfg.targets(), cfg.formats()
+	}
+	return p
+}
+
+// matrixTargets expands the platform-related variables of a build matrix,
+// plus its include entries, into targets.
+func matrixTargets(matrix map[string]any) []Target {
+	if len(matrix) == 0 {
+		return nil
+	}
+	combos := []map[string]string{{}}
+	for _, k := range matrixKeys {
+		values, ok := matrix[k].([]any)
+		if !ok {
+			continue
+		}
+		var next []map[string]string
+		for _, c := range combos {
+			for _, v := range values {
+				s, ok := v.(string)
+				if !ok {
+					continue
+				}
+				n := map[string]string{k: s}
+				for ck, cv := range c {
+					n[ck] = cv
+				}
+				next = append(next, n)
+			}
+		}
+		combos = next
+	}
+	if include, ok := matrix["include"].([]any); ok {
+		for _, e := range include {
+			m, ok := e.(map[string]any)
+			if !ok {
+				continue
+			}
+			c := make(map[string]string)
+			for _, k := range matrixKeys {
+				if s, ok := m[k].(string); ok {
+					c[k] = s
+				}
+			}
+			combos = append(combos, c)
+		}
+	}
+
+	var out []Target
+	for _, c := range combos {
+		if t, ok := comboTarget(c); ok {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// comboTarget derives a target from one matrix combination. Explicit
+// targets win over GOOS/GOARCH, which win over the runner image.
+func comboTarget(c map[string]string) (Target, bool) {
+	for _, k := range []string{"target", "platform"} {
+		if v := c[k]; v != "" {
+			if t, ok := ParseTriple(v); ok {
+				return t, true
+			}
+			if t, ok := parsePair(v); ok {
+				return t, true
+			}
+		}
+	}
+
+	var t Target
+	if c["os"] != "" {
+		t = runnerTarget(c["os"])
+	}
+	if o := NormalizeOS(c["goos"]); o != "" {
+		t.OS = o
+	}
+	for _, k := range []string{"goarch", "arch"} {
+		if a := NormalizeArch(c[k]); a != "" {
+			t.Arch = a
+		}
+	}
+	return t, t.OS != ""
+}
+
+// parsePair reads "linux/amd64", "linux-arm64" or "darwin_x86_64".
+func parsePair(s string) (Target, bool) {
+	for _, sep := range []string{"/", "-", "_"} {
+		o, a, ok := strings.Cut(s, sep)
+		if !ok {
+			continue
+		}
+		t := Target{OS: NormalizeOS(o), Arch: NormalizeArch(a)}
+		if t.OS != "" && t.Arch != "" {
+			return t, true
+		}
+	}
+	return Target{}, false
+}
+
+// runnerTarget maps a GitHub-hosted runner label to the platform it builds
+// on. macOS runners are arm64 from macos-14 on.
+func runnerTarget(label string) Target {
+	label = strings.ToLower(label)
+	name, version, _ := strings.Cut(label, "-")
+	t := Target{OS: NormalizeOS(name), Arch: "amd64"}
+	switch {
+	case strings.HasSuffix(label, "-arm") || strings.HasSuffix(label, "-arm64"):
+		t.Arch = "arm64"
+	case t.OS == "darwin":
+		if version != "12" && version != "13" && !strings.Contains(version, "intel") && !strings.Contains(version, "large") {
+			t.Arch = "arm64"
+		}
+	}
+	return t
+}
+
+// scriptFormats guesses the archive formats a shell script produces.
+func scriptFormats(script string) []string {
+	var out []string
+	for _, line := range strings.Split(script, "\n") {
+		f := strings.Fields(line)
+		if len(f) == 0 {
+			continue
+		}
+		switch {
+		case f[0] == "tar" && len(f) > 1 && strings.Contains(f[1], "J"):
+			out = append(out, "tar.xz")
+		case f[0] == "tar" && len(f) > 1 && strings.Contains(f[1], "z"):
+			out = append(out, "tar.gz")
+		case f[0] == "zip" || f[0] == "7z" || f[0] == "Compress-Archive":
+			out = append(out, "zip")
+		}
+	}
+	return out
+}
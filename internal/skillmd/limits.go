@@ -0,0 +1,143 @@
+package skillmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limits bounds the SKILL.md input Parse accepts, so an untrusted file
+// parses in bounded time and memory. Zero fields take DefaultLimits.
+type Limits struct {
+	// MaxBytes is the largest file read.
+	MaxBytes int64
+	// MaxLines is the most lines parsed.
+	MaxLines int
+	// MaxItems is the most commands, and the most flags or exit codes per
+	// command.
+	MaxItems int
+	// MaxBlockLines is the most lines in one fenced code block.
+	MaxBlockLines int
+}
+
+// DefaultLimits are generous for any real SKILL.md.
+var DefaultLimits = Limits{
+	MaxBytes:      1 << 20,
+	MaxLines:      20_000,
+	MaxItems:      500,
+	MaxBlockLines: 2_000,
+}
+
+func (l Limits) withDefaults() Limits {
+	if l.MaxBytes <= 0 {
+		l.MaxBytes = DefaultLimits.MaxBytes
+	}
+	if l.MaxLines <= 0 {
+		l.MaxLines = DefaultLimits.MaxLines
+	}
+	if l.MaxItems <= 0 {
+		l.MaxItems = DefaultLimits.MaxItems
+	}
+	if l.MaxBlockLines <= 0 {
+		l.MaxBlockLines = DefaultLimits.MaxBlockLines
+	}
+	return l
+}
+
+// ErrBinary reports content with NUL bytes, which no markdown file has.
+var ErrBinary = errors.New("binary content, not markdown")
+
+// InputError reports input Parse rejects: too large, binary or not UTF-8.
+type InputError struct {
+	Msg string
+	Err error
+}
+
+func (e *InputError) Error() string { return e.Msg }
+func (e *InputError) Unwrap() error { return e.Err }
+
+// LimitError reports input over one of the Limits.
+type LimitError struct {
+	What  string
+	Limit int64
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s exceeds the limit of %d", e.What, e.Limit)
+}
+
+// checkInput rejects content Parse should not attempt.
+func checkInput(content string, l Limits) error {
+	if int64(len(content)) > l.MaxBytes {
+		return &LimitError{What: fmt.Sprintf("size of %d bytes", len(content)), Limit: l.MaxBytes}
+	}
+	if i := strings.IndexByte(content, 0); i >= 0 {
+		return &InputError{Msg: fmt.Sprintf("%v (NUL byte at offset %d)", ErrBinary, i), Err: ErrBinary}
+	}
+	if !utf8.ValidString(content) {
+		return &InputError{Msg: fmt.Sprintf("invalid UTF-8 at byte %d", invalidUTF8Offset(content))}
+	}
+	if n := strings.Count(content, "\n") + 1; n > l.MaxLines {
+		return &LimitError{What: fmt.Sprintf("%d lines", n), Limit: int64(l.MaxLines)}
+	}
+	return nil
+}
+
+func invalidUTF8Offset(s string) int {
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size <= 1 {
+			return i
+		}
+		i += size
+	}
+	return -1
+}
+
+// ParseFS reads and parses the SKILL.md at name in fsys, reading at most
+// l.MaxBytes.
+func ParseFS(fsys fs.FS, name string, l Limits) (*SkillFile, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	data, err := readLimited(f, l.withDefaults().MaxBytes)
+	if err != nil {
+		return nil, err
+	}
+	return ParseWithLimits(string(data), l)
+}
+
+// ParseFileWithLimits reads a SKILL.md file from disk and parses it within l.
+func ParseFileWithLimits(path string, l Limits) (*SkillFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading skill file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	data, err := readLimited(f, l.withDefaults().MaxBytes)
+	if err != nil {
+		return nil, fmt.Errorf("reading skill file: %w", err)
+	}
+	return ParseWithLimits(string(data), l)
+}
+
+// readLimited reads r, failing once it passes max bytes rather than
+// holding an arbitrarily large file.
+func readLimited(r io.Reader, max int64) ([]byte, error) {
+	var buf bytes.Buffer
+	n, err := io.Copy(&buf, io.LimitReader(r, max+1))
+	if err != nil {
+		return nil, err
+	}
+	if n > max {
+		return nil, &LimitError{What: "size", Limit: max}
+	}
+	return buf.Bytes(), nil
+}
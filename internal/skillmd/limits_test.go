@@ -0,0 +1,177 @@
+package skillmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+const commandsHeader = "# tool\n\n## Commands\n\n### tool run\n\n"
+
+func TestParse_RejectsBinary(t *testing.T) {
+	_, err := Parse("# tool\n\x00\x01\x02")
+	if !errors.Is(err, ErrBinary) {
+		t.Fatalf("err = %v, want ErrBinary", err)
+	}
+	if !strings.Contains(err.Error(), "offset 7") {
+		t.Errorf("err = %q, want the NUL offset", err)
+	}
+}
+
+func TestParse_RejectsInvalidUTF8(t *testing.T) {
+	_, err := Parse("# tool\n\nabc\xff\xfe")
+	var ie *InputError
+	if !errors.As(err, &ie) {
+		t.Fatalf("err = %v, want *InputError", err)
+	}
+	if err.Error() != "invalid UTF-8 at byte 11" {
+		t.Errorf("err = %q", err)
+	}
+}
+
+func TestParseWithLimits_Size(t *testing.T) {
+	_, err := ParseWithLimits(strings.Repeat("a", 101), Limits{MaxBytes: 100})
+	var le *LimitError
+	if !errors.As(err, &le) || le.Limit != 100 {
+		t.Fatalf("err = %v, want a LimitError of 100", err)
+	}
+}
+
+// Pathological inputs must be rejected or parsed quickly, not exhaust
+// time or memory.
+func TestParse_Pathological(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		limits  Limits
+		wantErr string
+	}{
+		{
+			name:    "million-line list",
+			content: commandsHeader + "**Flags:**\n" + strings.Repeat("- `--x` — flag\n", 1_000_000),
+			limits:  Limits{MaxBytes: 64 << 20},
+			wantErr: "lines exceeds the limit of 20000",
+		},
+		{
+			name:    "flags over the item limit",
+			content: commandsHeader + "**Flags:**\n" + strings.Repeat("- `--x` — flag\n", 600),
+			wantErr: "number of flags exceeds the limit of 500",
+		},
+		{
+			name:    "exit codes over the item limit",
+			content: commandsHeader + "**Exit codes:**\n" + strings.Repeat("- 1: failure\n", 600),
+			wantErr: "number of exit codes exceeds the limit of 500",
+		},
+		{
+			name:    "commands over the item limit",
+			content: "## Commands\n\n" + strings.Repeat("### tool x\n", 600),
+			wantErr: "number of commands exceeds the limit of 500",
+		},
+		{
+			name:    "tools over the item limit",
+			content: "# tool\n\n" + strings.Repeat("## Tool: x\n", 600),
+			wantErr: "number of tools exceeds the limit of 500",
+		},
+		{
+			name:    "tool commands over the item limit",
+			content: "## Tool: x\n\n### Commands\n\n" + strings.Repeat("#### x y\n", 600),
+			wantErr: `tool "x": number of commands exceeds the limit of 500`,
+		},
+		{
+			name:    "huge code fence",
+			content: commandsHeader + "**JSON output:**\n```json\n" + strings.Repeat("{}\n", 5_000) + "```\n",
+			wantErr: "code block exceeds the limit of 2000",
+		},
+		{
+			name:    "huge unclosed fence",
+			content: commandsHeader + "**JSON output:**\n```json\n" + strings.Repeat("{}\n", 100_000),
+			limits:  Limits{MaxLines: 200_000, MaxBlockLines: 200_000},
+		},
+		{
+			name:    "many fences without labels",
+			content: commandsHeader + strings.Repeat("```\n", 15_000),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			_, err := ParseWithLimits(tt.content, tt.limits)
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Errorf("took %v", elapsed)
+			}
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseFileWithLimits_Oversized(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "SKILL.md")
+	if err := os.WriteFile(path, []byte(strings.Repeat("a", 2048)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := ParseFileWithLimits(path, Limits{MaxBytes: 1024})
+	var le *LimitError
+	if !errors.As(err, &le) {
+		t.Fatalf("err = %v, want *LimitError", err)
+	}
+}
+
+func TestParseFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"SKILL.md": {Data: []byte("# mytool\n\nDoes things.\n")},
+		"big.md":   {Data: []byte(strings.Repeat("x", 64))},
+	}
+	sf, err := ParseFS(fsys, "SKILL.md", Limits{})
+	if err != nil || sf.Name != "mytool" {
+		t.Fatalf("ParseFS = %+v, %v", sf, err)
+	}
+	if _, err := ParseFS(fsys, "big.md", Limits{MaxBytes: 32}); err == nil {
+		t.Error("want a size error")
+	}
+}
+
+// FuzzParse checks that Parse never panics and that what it accepts is
+// within its limits. Regressions live in testdata/fuzz/FuzzParse.
+func FuzzParse(f *testing.F) {
+	for _, name := range []string{"valid-skill.md", "minimal-skill.md", "malformed-skill.md", "missing-sections.md", "multi-tool-skill.md"} {
+		data, err := os.ReadFile(testdataPath(name))
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(string(data))
+	}
+	f.Fuzz(func(t *testing.T, content string) {
+		l := Limits{MaxItems: 8, MaxBlockLines: 8}
+		sf, err := ParseWithLimits(content, l)
+		if err != nil {
+			return
+		}
+		if len(sf.Commands) > l.MaxItems || len(sf.Tools) > l.MaxItems {
+			t.Fatalf("%d commands, %d tools over the limit", len(sf.Commands), len(sf.Tools))
+		}
+		commands := sf.Commands
+		for _, tool := range sf.Tools {
+			if len(tool.Commands) > l.MaxItems {
+				t.Fatalf("tool %q: %d commands over the limit", tool.Name, len(tool.Commands))
+			}
+			commands = append(commands, tool.Commands...)
+		}
+		for _, c := range commands {
+			if len(c.Flags) > l.MaxItems || len(c.ExitCodes) > l.MaxItems {
+				t.Fatalf("command %q over the item limit", c.Name)
+			}
+			if strings.Count(c.JSONOutput, "\n") >= l.MaxBlockLines {
+				t.Fatalf("command %q JSON output over the block limit", c.Name)
+			}
+		}
+	})
+}
@@ -2,7 +2,6 @@ package skillmd
 
 import (
 	"fmt"
-	"os"
 	"regexp"
 	"strconv"
 	"strings"
@@ -15,33 +14,48 @@ var (
 	reExitCode  = regexp.MustCompile(`^-\s+(\d+):\s*(.+)$`)
 )
 
-// ParseFile reads a SKILL.md file from disk and parses it.
+// ParseFile reads a SKILL.md file from disk and parses it within
+// DefaultLimits.
 func ParseFile(path string) (*SkillFile, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("reading skill file: %w", err)
-	}
-	return Parse(string(data))
+	return ParseFileWithLimits(path, DefaultLimits)
 }
 
-// Parse parses SKILL.md content into a structured representation.
+// Parse parses SKILL.md content into a structured representation. Content
+// over DefaultLimits, binary or not UTF-8 is rejected.
 func Parse(content string) (*SkillFile, error) {
-	lines := strings.Split(content, "\n")
-	sf := &SkillFile{
-		Sections: make(map[string]*Section),
+	return ParseWithLimits(content, DefaultLimits)
+}
+
+// ParseWithLimits is Parse within l.
+func ParseWithLimits(content string, l Limits) (*SkillFile, error) {
+	l = l.withDefaults()
+	if err := checkInput(content, l); err != nil {
+		return nil, err
 	}
+	lines := strings.Split(content, "\n")
+	sf := &SkillFile{}
 
 	// Extract H1 name and description.
 	i := parseHeader(lines, sf)
 
 	// Split remaining lines into H2 sections.
-	parseSections(lines[i:], sf)
+	sf.Sections = parseSections(lines[i:], i+1, 2)
 
 	// Extract commands from the Commands section.
 	if cmdSection, ok := sf.Sections[SectionCommands]; ok {
-		sf.Commands = parseCommands(cmdSection.Content)
+		cmds, err := parseCommands(cmdSection, 3, l)
+		if err != nil {
+			return nil, err
+		}
+		sf.Commands = cmds
 	}
 
+	tools, err := parseTools(lines[i:], i+1, l)
+	if err != nil {
+		return nil, err
+	}
+	sf.Tools = tools
+
 	return sf, nil
 }
 
@@ -59,17 +73,24 @@ func parseHeader(lines []string, sf *SkillFile) int {
 	if i < len(lines) {
 		if m := reHeading.FindStringSubmatch(lines[i]); m != nil && len(m[1]) == 1 {
 			sf.Name = strings.TrimSpace(m[2])
+			sf.Line = i + 1
 			i++
 		}
 	}
 
-	// Skip blank lines after H1.
+	sf.Description, i = parseParagraph(lines, i)
+	return i
+}
+
+// parseParagraph skips blank lines from i and joins the lines of the
+// paragraph that follows, up to a blank line or heading. Returns the line
+// index after it.
+func parseParagraph(lines []string, i int) (string, int) {
 	for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
 		i++
 	}
 
-	// Collect description paragraph (lines until blank line or next heading).
-	var desc []string
+	var para []string
 	for i < len(lines) {
 		line := lines[i]
 		if strings.TrimSpace(line) == "" {
@@ -78,46 +99,43 @@ func parseHeader(lines []string, sf *SkillFile) int {
 		if reHeading.MatchString(line) {
 			break
 		}
-		desc = append(desc, line)
+		para = append(para, line)
 		i++
 	}
-	sf.Description = strings.Join(desc, " ")
-
-	return i
+	return strings.Join(para, " "), i
 }
 
-// parseSections splits lines into H2 sections and adds them to sf.
-func parseSections(lines []string, sf *SkillFile) {
-	var currentHeading string
-	var currentLines []string
+// parseSections splits lines into sections at headings of the given level.
+// first is the line number of lines[0].
+func parseSections(lines []string, first, level int) map[string]*Section {
+	sections := make(map[string]*Section)
+	var current *Section
 
-	flush := func() {
-		if currentHeading != "" {
-			sf.Sections[currentHeading] = &Section{
-				Heading: currentHeading,
-				Level:   2,
-				Content: strings.TrimSpace(strings.Join(currentLines, "\n")),
-			}
+	flush := func(end int) {
+		if current != nil {
+			current.body = lines[current.Line-first+1 : end]
+			current.Content = strings.TrimSpace(strings.Join(current.body, "\n"))
+			sections[current.Heading] = current
 		}
 	}
 
-	for _, line := range lines {
-		if m := reHeading.FindStringSubmatch(line); m != nil && len(m[1]) == 2 {
-			flush()
-			currentHeading = strings.TrimSpace(m[2])
-			currentLines = nil
-			continue
+	for i, line := range lines {
+		if m := reHeading.FindStringSubmatch(line); m != nil && len(m[1]) == level {
+			flush(i)
+			current = &Section{Heading: strings.TrimSpace(m[2]), Level: level, Line: first + i}
 		}
-		currentLines = append(currentLines, line)
 	}
-	flush()
+	flush(len(lines))
+	return sections
 }
 
-// parseCommands extracts Command definitions from H3 headings within the Commands section.
-func parseCommands(content string) []Command {
-	lines := strings.Split(content, "\n")
+// parseCommands extracts Command definitions from the headings of the given
+// level within a Commands section: H3, or H4 within a tool.
+func parseCommands(sec *Section, level int, l Limits) ([]Command, error) {
+	lines, first := sec.body, sec.Line+1
 	var commands []Command
 	var current *Command
+	var err error
 
 	flush := func() {
 		if current != nil {
@@ -126,9 +144,12 @@ func parseCommands(content string) []Command {
 	}
 
 	for i := 0; i < len(lines); i++ {
-		if m := reHeading.FindStringSubmatch(lines[i]); m != nil && len(m[1]) == 3 {
+		if m := reHeading.FindStringSubmatch(lines[i]); m != nil && len(m[1]) == level {
 			flush()
-			current = &Command{Name: strings.TrimSpace(m[2])}
+			if len(commands) >= l.MaxItems {
+				return nil, &LimitError{What: "number of commands", Limit: int64(l.MaxItems)}
+			}
+			current = &Command{Name: strings.TrimSpace(m[2]), Pos: Pos{Line: first + i}}
 			continue
 		}
 
@@ -149,73 +170,113 @@ func parseCommands(content string) []Command {
 			label := strings.TrimRight(bm[1], ":")
 			switch label {
 			case SubsectionFlags:
-				i = parseFlags(lines, i+1, current)
+				i, err = parseFlags(lines, i+1, first, current, l.MaxItems)
 			case SubsectionJSONOutput:
-				i = parseJSONOutput(lines, i+1, current)
+				i, err = parseCodeBlock(lines, i+1, &current.JSONOutput, l.MaxBlockLines)
+			case SubsectionErrorOutput:
+				i, err = parseCodeBlock(lines, i+1, &current.ErrorJSON, l.MaxBlockLines)
 			case SubsectionExitCodes:
-				i = parseExitCodes(lines, i+1, current)
+				i, err = parseExitCodes(lines, i+1, current, l.MaxItems)
+			case SubsectionReadOnly:
+				current.ReadOnly = isYes(line[len(bm[0]):])
+			case SubsectionVolatile:
+				current.Volatile = splitInlineList(line[len(bm[0]):])
+			}
+			if err != nil {
+				return nil, fmt.Errorf("command %q: %w", current.Name, err)
 			}
 		}
 	}
 	flush()
 
-	return commands
+	return commands, nil
 }
 
 // parseFlags extracts flag definitions from list items starting at line i.
-func parseFlags(lines []string, i int, cmd *Command) int {
+// first is the line number of lines[0].
+func parseFlags(lines []string, i, first int, cmd *Command, max int) (int, error) {
 	for i < len(lines) {
 		line := strings.TrimSpace(lines[i])
 		if line == "" {
-			return i
+			return i, nil
 		}
 		if fm := reFlag.FindStringSubmatch(line); fm != nil {
-			cmd.Flags = append(cmd.Flags, Flag{Name: fm[1], Desc: fm[2]})
+			if len(cmd.Flags) >= max {
+				return i, &LimitError{What: "number of flags", Limit: int64(max)}
+			}
+			cmd.Flags = append(cmd.Flags, Flag{Name: fm[1], Desc: fm[2], Pos: Pos{Line: first + i}})
 		} else if !strings.HasPrefix(line, "-") {
-			return i - 1
+			return i - 1, nil
 		}
 		i++
 	}
-	return i - 1
+	return i - 1, nil
 }
 
-// parseJSONOutput extracts the JSON code block.
-func parseJSONOutput(lines []string, i int, cmd *Command) int {
+// parseCodeBlock extracts the next fenced code block into dst.
+func parseCodeBlock(lines []string, i int, dst *string, max int) (int, error) {
 	// Find opening code fence.
 	for i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```") {
 		i++
 	}
 	if i >= len(lines) {
-		return i - 1
+		return i - 1, nil
 	}
 	i++ // skip opening fence
 
-	var block []string
+	start := i
 	for i < len(lines) {
 		if strings.HasPrefix(strings.TrimSpace(lines[i]), "```") {
-			cmd.JSONOutput = strings.Join(block, "\n")
-			return i
+			*dst = strings.Join(lines[start:i], "\n")
+			return i, nil
+		}
+		if i-start >= max {
+			return i, &LimitError{What: "code block", Limit: int64(max)}
 		}
-		block = append(block, lines[i])
 		i++
 	}
-	return i - 1
+	return i - 1, nil
 }
 
 // parseExitCodes extracts exit code definitions from list items.
-func parseExitCodes(lines []string, i int, cmd *Command) int {
+func parseExitCodes(lines []string, i int, cmd *Command, max int) (int, error) {
 	for i < len(lines) {
 		line := strings.TrimSpace(lines[i])
 		if line == "" {
-			return i
+			return i, nil
 		}
 		if em := reExitCode.FindStringSubmatch(line); em != nil {
+			if len(cmd.ExitCodes) >= max {
+				return i, &LimitError{What: "number of exit codes", Limit: int64(max)}
+			}
 			code, _ := strconv.Atoi(em[1])
 			cmd.ExitCodes = append(cmd.ExitCodes, ExitCode{Code: code, Desc: em[2]})
 		} else if !strings.HasPrefix(line, "-") {
-			return i - 1
+			return i - 1, nil
 		}
 		i++
 	}
-	return i - 1
+	return i - 1, nil
+}
+
+// isYes reports whether an inline label value such as "yes" or "true" is affirmative.
+func isYes(s string) bool {
+	switch strings.ToLower(strings.Trim(strings.TrimSpace(s), "`*_.")) {
+	case "yes", "true", "y":
+		return true
+	}
+	return false
+}
+
+// splitInlineList splits a comma-separated label value such as
+// "`$.generated_at`, `duration_ms`" into its trimmed items.
+func splitInlineList(s string) []string {
+	var out []string
+	for _, item := range strings.Split(s, ",") {
+		item = strings.Trim(strings.TrimSpace(item), "`")
+		if item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
 }
@@ -3,6 +3,7 @@ package skillmd
 import (
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 )
 
@@ -267,3 +268,207 @@ Third command.
 		}
 	}
 }
+
+func TestParse_ReadOnly(t *testing.T) {
+	input := `# tool
+
+Desc.
+
+## Commands
+
+### tool status
+
+Shows status.
+
+**Read-only:** yes
+
+### tool apply
+
+Changes things.
+
+**Read-only:** no
+
+### tool plain
+
+No marker.
+`
+	sf, err := Parse(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sf.Commands) != 3 {
+		t.Fatalf("got %d commands, want 3", len(sf.Commands))
+	}
+	want := []bool{true, false, false}
+	for i, c := range sf.Commands {
+		if c.ReadOnly != want[i] {
+			t.Errorf("%s ReadOnly = %v, want %v", c.Name, c.ReadOnly, want[i])
+		}
+	}
+}
+
+func TestParse_ErrorOutput(t *testing.T) {
+	input := `# tool
+
+Desc.
+
+## Commands
+
+### tool run
+
+Runs.
+
+**JSON output:**
+` + "```json" + `
+{"ok": true}
+` + "```" + `
+
+**Error output:**
+` + "```json" + `
+{"error": "message", "code": 2}
+` + "```" + `
+`
+	sf, err := Parse(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cmd := sf.Commands[0]
+	if cmd.JSONOutput != `{"ok": true}` {
+		t.Errorf("JSONOutput = %q", cmd.JSONOutput)
+	}
+	if cmd.ErrorJSON != `{"error": "message", "code": 2}` {
+		t.Errorf("ErrorJSON = %q", cmd.ErrorJSON)
+	}
+}
+
+func TestParse_VolatileFields(t *testing.T) {
+	input := `# tool
+
+Desc.
+
+## Commands
+
+### tool run
+
+Runs.
+
+**Volatile fields:** ` + "`$.generated_at`, `duration_ms`" + `
+`
+	sf, err := Parse(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := sf.Commands[0].Volatile
+	if len(got) != 2 || got[0] != "$.generated_at" || got[1] != "duration_ms" {
+		t.Errorf("Volatile = %q, want [$.generated_at duration_ms]", got)
+	}
+}
+
+func TestParseFile_MultiTool(t *testing.T) {
+	sf, err := ParseFile(testdataPath("multi-tool-skill.md"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sf.Name != "foo" || len(sf.Commands) != 0 {
+		t.Errorf("Name = %q with %d commands, want foo with none", sf.Name, len(sf.Commands))
+	}
+	if len(sf.Tools) != 3 {
+		t.Fatalf("got %d tools, want 3", len(sf.Tools))
+	}
+
+	foo := sf.Tools[0]
+	if foo.Name != "foo" || foo.Description != "The foo command line client." {
+		t.Errorf("Tools[0] = %q: %q", foo.Name, foo.Description)
+	}
+	if len(foo.Commands) != 3 || foo.Commands[0].Name != "foo status" {
+		t.Fatalf("foo commands = %+v", foo.Commands)
+	}
+	if len(foo.Commands[0].Flags) != 1 || len(foo.Commands[0].ExitCodes) != 2 || foo.Commands[0].JSONOutput == "" {
+		t.Errorf("foo status = %+v", foo.Commands[0])
+	}
+	if sec := foo.Sections[SectionWhatNotDo]; sec == nil || sec.Level != 3 {
+		t.Errorf("foo not-do section = %+v, want an H3", sec)
+	}
+	if names := []string{sf.Tools[1].Name, sf.Tools[2].Name}; names[0] != "foo-agent" || names[1] != "foo-migrate" {
+		t.Errorf("tools = %v", names)
+	}
+	if len(sf.Tools[2].Commands) != 1 {
+		t.Errorf("foo-migrate has %d commands, want 1", len(sf.Tools[2].Commands))
+	}
+}
+
+func TestSkillFile_ToolFiles(t *testing.T) {
+	sf, err := ParseFile(testdataPath("multi-tool-skill.md"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	files := sf.ToolFiles()
+	if len(files) != 3 {
+		t.Fatalf("got %d tool files, want 3", len(files))
+	}
+
+	// The shared Install and Parsing examples apply unless a tool has its own.
+	foo, agent, migrate := files[0], files[1], files[2]
+	if sec := foo.Sections[SectionInstall]; sec == nil || sec.Level != 2 {
+		t.Errorf("foo install = %+v, want the shared H2", sec)
+	}
+	if sec := agent.Sections[SectionInstall]; sec == nil || !strings.Contains(sec.Content, "foo-agent.sh") {
+		t.Errorf("foo-agent install = %+v, want its own", sec)
+	}
+	if migrate.Sections[SectionParsingExamples] == nil {
+		t.Error("foo-migrate should share the Parsing examples section")
+	}
+	if migrate.Sections[SectionWhatNotDo] != nil {
+		t.Error("foo-migrate documents no not-do items")
+	}
+	for heading := range migrate.Sections {
+		if strings.HasPrefix(heading, ToolHeadingPrefix) {
+			t.Errorf("tool file holds the section %q", heading)
+		}
+	}
+	if migrate.Name != "foo-migrate" || len(migrate.Commands) != 1 {
+		t.Errorf("foo-migrate = %q with %d commands", migrate.Name, len(migrate.Commands))
+	}
+
+	if sf.Tool("foo-agent") == nil || sf.Tool("bar") != nil {
+		t.Error("Tool should find foo-agent and only it")
+	}
+
+	single, err := ParseFile(testdataPath("valid-skill.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if single.Tools != nil || single.ToolFiles() != nil {
+		t.Error("a single-tool SKILL.md has no tools")
+	}
+}
+
+func TestParseFile_Lines(t *testing.T) {
+	sf, err := ParseFile(testdataPath("valid-skill.md"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sf.Line != 1 || sf.Sections[SectionInstall].Line != 5 || sf.Sections[SectionCommands].Line != 11 {
+		t.Errorf("lines: H1 %d, Install %d, Commands %d; want 1, 5, 11",
+			sf.Line, sf.Sections[SectionInstall].Line, sf.Sections[SectionCommands].Line)
+	}
+	if got := sf.Commands[1].Pos; got != (Pos{Line: 33}) {
+		t.Errorf("mytool check at %+v, want line 33", got)
+	}
+	if got := sf.Commands[0].Flags[0].Pos; got != (Pos{Line: 18}) {
+		t.Errorf("--format json at %+v, want line 18", got)
+	}
+
+	multi, err := ParseFile(testdataPath("multi-tool-skill.md"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	agent := multi.Tools[1]
+	if agent.Line != 51 || agent.Sections[SectionInstall].Line != 55 || agent.Commands[0].Pos.Line != 63 {
+		t.Errorf("foo-agent lines: heading %d, Install %d, run %d; want 51, 55, 63",
+			agent.Line, agent.Sections[SectionInstall].Line, agent.Commands[0].Pos.Line)
+	}
+	if f := multi.Tool("foo-agent"); f.Line != 51 {
+		t.Errorf("tool file line = %d, want 51", f.Line)
+	}
+}
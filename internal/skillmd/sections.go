@@ -8,16 +8,37 @@ const (
 	SectionParsingExamples = "Parsing examples"
 )
 
+// ToolHeadingPrefix starts the H2 heading of each tool in a SKILL.md that
+// documents several binaries, as in "## Tool: mytool-agent".
+const ToolHeadingPrefix = "Tool: "
+
 // Per-command subsections.
 const (
-	SubsectionFlags      = "Flags"
-	SubsectionJSONOutput = "JSON output"
-	SubsectionExitCodes  = "Exit codes"
+	SubsectionFlags       = "Flags"
+	SubsectionJSONOutput  = "JSON output"
+	SubsectionExitCodes   = "Exit codes"
+	SubsectionReadOnly    = "Read-only"
+	SubsectionErrorOutput = "Error output"
+	SubsectionVolatile    = "Volatile fields"
 )
 
 // SkillFile represents a parsed SKILL.md.
 type SkillFile struct {
 	Name        string
+	Line        int // line of the H1 heading, or of the tool heading in a tool file; 0 if absent
+	Description string
+	Sections    map[string]*Section
+	Commands    []Command
+	// Tools lists the binaries of a SKILL.md that documents several, in
+	// document order. Empty for a single tool.
+	Tools []Tool
+}
+
+// Tool is one binary of a multi-tool SKILL.md: the content under its
+// "## Tool: <name>" heading, with sections as H3 and commands as H4.
+type Tool struct {
+	Name        string
+	Line        int // line of the tool heading
 	Description string
 	Sections    map[string]*Section
 	Commands    []Command
@@ -27,22 +48,37 @@ type SkillFile struct {
 type Section struct {
 	Heading string
 	Level   int
+	Line    int // line of the heading
 	Content string
+
+	body []string // lines after the heading, from Line+1
+}
+
+// Pos locates a command or flag: a line of SKILL.md, or for commands
+// recovered from source, a file and line.
+type Pos struct {
+	File string // slash-separated path of a source file; empty for SKILL.md
+	Line int    // 1-based; 0 if unknown
 }
 
 // Command represents a documented CLI command (H3 under Commands).
 type Command struct {
 	Name       string
+	Pos        Pos
 	Desc       string
 	Flags      []Flag
 	JSONOutput string
+	ErrorJSON  string // error object shape from **Error output:**
 	ExitCodes  []ExitCode
+	ReadOnly   bool     // marked safe to execute with **Read-only:** yes
+	Volatile   []string // JSON paths or key names that may differ between runs
 }
 
 // Flag represents a documented CLI flag.
 type Flag struct {
 	Name string
 	Desc string
+	Pos  Pos
 }
 
 // ExitCode represents a documented exit code.
@@ -0,0 +1,104 @@
+package skillmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// parseTools extracts the tools of a SKILL.md that documents several
+// binaries, each under an H2 heading starting with ToolHeadingPrefix. first
+// is the line number of lines[0].
+func parseTools(lines []string, first int, l Limits) ([]Tool, error) {
+	var tools []Tool
+	name, start := "", -1
+
+	flush := func(end int) error {
+		if start < 0 {
+			return nil
+		}
+		if len(tools) >= l.MaxItems {
+			return &LimitError{What: "number of tools", Limit: int64(l.MaxItems)}
+		}
+		t, err := parseTool(name, lines[start:end], first+start, l)
+		if err != nil {
+			return fmt.Errorf("tool %q: %w", name, err)
+		}
+		tools = append(tools, t)
+		return nil
+	}
+
+	for i, line := range lines {
+		m := reHeading.FindStringSubmatch(line)
+		if m == nil || len(m[1]) != 2 {
+			continue
+		}
+		if err := flush(i); err != nil {
+			return nil, err
+		}
+		start = -1
+		if n, ok := strings.CutPrefix(strings.TrimSpace(m[2]), ToolHeadingPrefix); ok {
+			name, start = strings.TrimSpace(n), i+1
+		}
+	}
+	if err := flush(len(lines)); err != nil {
+		return nil, err
+	}
+	return tools, nil
+}
+
+// parseTool parses the lines under a tool's heading: a description
+// paragraph, then H3 sections with the commands as H4 under Commands. first
+// is the line number of lines[0].
+func parseTool(name string, lines []string, first int, l Limits) (Tool, error) {
+	t := Tool{Name: name, Line: first - 1}
+	var i int
+	t.Description, i = parseParagraph(lines, 0)
+	t.Sections = parseSections(lines[i:], first+i, 3)
+	if sec, ok := t.Sections[SectionCommands]; ok {
+		cmds, err := parseCommands(sec, 4, l)
+		if err != nil {
+			return t, err
+		}
+		t.Commands = cmds
+	}
+	return t, nil
+}
+
+// ToolFiles returns a SkillFile per tool of a multi-tool SKILL.md, in
+// document order, or nil for a single tool. Each holds the tool's name,
+// description and commands, and its own sections over the file's shared
+// H2 sections, so an Install or Parsing examples section written once
+// applies to every tool. Commands are never shared.
+func (sf *SkillFile) ToolFiles() []*SkillFile {
+	var files []*SkillFile
+	for _, t := range sf.Tools {
+		f := &SkillFile{
+			Name:        t.Name,
+			Line:        t.Line,
+			Description: t.Description,
+			Sections:    make(map[string]*Section),
+			Commands:    t.Commands,
+		}
+		for heading, sec := range sf.Sections {
+			if heading != SectionCommands && !strings.HasPrefix(heading, ToolHeadingPrefix) {
+				f.Sections[heading] = sec
+			}
+		}
+		for heading, sec := range t.Sections {
+			f.Sections[heading] = sec
+		}
+		files = append(files, f)
+	}
+	return files
+}
+
+// Tool returns the SkillFile of the named tool of a multi-tool SKILL.md,
+// as ToolFiles does, or nil.
+func (sf *SkillFile) Tool(name string) *SkillFile {
+	for _, f := range sf.ToolFiles() {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package validator
+
+import (
+	"sort"
+	"sync"
+)
+
+// RepoResult is the outcome of validating one repo of a batch.
+type RepoResult struct {
+	Path   string            `json:"path"`
+	Status string            `json:"status"`          // "pass", "fail", "partial"
+	Error  string            `json:"error,omitempty"` // why the repo could not be validated
+	Result *ValidationResult `json:"result,omitempty"`
+}
+
+// CheckTally counts the repos in which a check failed or warned.
+type CheckTally struct {
+	Name    string   `json:"name"`
+	Fail    int      `json:"fail"`
+	Warn    int      `json:"warn"`
+	Failing []string `json:"failing,omitempty"` // paths of the repos it failed in
+}
+
+// BatchSummary counts repos by status. Repos that could not be validated
+// count as Error and not as Fail.
+type BatchSummary struct {
+	Total   int `json:"total"`
+	Pass    int `json:"pass"`
+	Fail    int `json:"fail"`
+	Partial int `json:"partial"`
+	Error   int `json:"error"`
+}
+
+// BatchResult aggregates the validation of several repos.
+type BatchResult struct {
+	Status  string       `json:"status"` // the worst repo status
+	Repos   []RepoResult `json:"repos"`
+	Checks  []CheckTally `json:"checks"` // checks that failed or warned, most failures first
+	Summary BatchSummary `json:"summary"`
+}
+
+// ValidateFunc validates the repo at path, as ValidateWithOptions does.
+type ValidateFunc func(path string) (*ValidationResult, error)
+
+// ValidateAll validates paths with validate on at most workers goroutines.
+// Repos are reported in the order of paths however the work interleaves.
+// A repo that cannot be validated fails the batch.
+func ValidateAll(paths []string, workers int, validate ValidateFunc) *BatchResult {
+	if workers < 1 {
+		workers = 1
+	}
+	repos := make([]RepoResult, len(paths))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range min(workers, len(paths)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				repos[i] = validateRepo(paths[i], validate)
+			}
+		}()
+	}
+	for i := range paths {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	b := &BatchResult{Repos: repos}
+	summarizeBatch(b)
+	return b
+}
+
+func validateRepo(path string, validate ValidateFunc) RepoResult {
+	result, err := validate(path)
+	if err != nil {
+		return RepoResult{Path: path, Status: OverallFail, Error: err.Error()}
+	}
+	return RepoResult{Path: path, Status: result.Status, Result: result}
+}
+
+// statusRank orders overall statuses from best to worst.
+var statusRank = map[string]int{OverallPass: 0, OverallPartial: 1, OverallFail: 2}
+
+// summarizeBatch sets the status, summary and check tallies of b.
+func summarizeBatch(b *BatchResult) {
+	b.Status = OverallPass
+	b.Summary = BatchSummary{Total: len(b.Repos)}
+	tallies := make(map[string]*CheckTally)
+	for _, r := range b.Repos {
+		if statusRank[r.Status] > statusRank[b.Status] {
+			b.Status = r.Status
+		}
+		switch {
+		case r.Error != "":
+			b.Summary.Error++
+			continue
+		case r.Status == OverallPass:
+			b.Summary.Pass++
+		case r.Status == OverallPartial:
+			b.Summary.Partial++
+		default:
+			b.Summary.Fail++
+		}
+
+		for _, c := range repoCheckStatus(r.Result) {
+			t := tallies[c.Name]
+			if t == nil {
+				t = &CheckTally{Name: c.Name}
+				tallies[c.Name] = t
+			}
+			if c.Status == StatusFail {
+				t.Fail++
+				t.Failing = append(t.Failing, r.Path)
+			} else {
+				t.Warn++
+			}
+		}
+	}
+
+	b.Checks = make([]CheckTally, 0, len(tallies))
+	for _, t := range tallies {
+		b.Checks = append(b.Checks, *t)
+	}
+	sort.Slice(b.Checks, func(i, j int) bool {
+		x, y := b.Checks[i], b.Checks[j]
+		if x.Fail != y.Fail {
+			return x.Fail > y.Fail
+		}
+		if x.Warn != y.Warn {
+			return x.Warn > y.Warn
+		}
+		return x.Name < y.Name
+	})
+}
+
+// repoCheckStatus returns the checks of result that did not pass, each
+// once at its worst status across the repo's tools.
+func repoCheckStatus(result *ValidationResult) []CheckResult {
+	var out []CheckResult
+	seen := make(map[string]int)
+	for _, c := range result.AllChecks() {
+		if c.Status == StatusPass {
+			continue
+		}
+		if i, ok := seen[c.Name]; ok {
+			if c.Status == StatusFail {
+				out[i].Status = StatusFail
+			}
+			continue
+		}
+		seen[c.Name] = len(out)
+		out = append(out, CheckResult{Name: c.Name, Status: c.Status})
+	}
+	return out
+}
@@ -0,0 +1,96 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestValidateAll(t *testing.T) {
+	results := map[string]*ValidationResult{
+		"a": {Status: OverallPass, Checks: []CheckResult{pass(CheckSkillMDExists, "")}},
+		"b": {Status: OverallPartial, Checks: []CheckResult{warn(CheckHasDoctorCommand, ""), warn(CheckHasBinaryRelease, "")}},
+		"c": {Status: OverallFail, Checks: []CheckResult{fail(CheckHasInitCommand, ""), warn(CheckHasDoctorCommand, "")}},
+		"d": {Status: OverallFail, Checks: []CheckResult{fail(CheckHasInitCommand, ""), fail(CheckHasDoctorCommand, "")}},
+	}
+	var running, peak atomic.Int32
+	validate := func(path string) (*ValidationResult, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		// Finish in reverse order of the paths.
+		time.Sleep(time.Duration(5-len(path)) * time.Millisecond)
+		if r, ok := results[strings.TrimSpace(path)]; ok {
+			return r, nil
+		}
+		return nil, errors.New("no such repo")
+	}
+
+	paths := []string{"a", "b", "c", "d", " e"}
+	got := ValidateAll(paths, 2, validate)
+
+	if peak.Load() > 2 {
+		t.Errorf("%d validations ran at once, want at most 2", peak.Load())
+	}
+	for i, r := range got.Repos {
+		if r.Path != paths[i] {
+			t.Errorf("repo %d = %q, want %q", i, r.Path, paths[i])
+		}
+	}
+	if e := got.Repos[4]; e.Status != OverallFail || e.Error != "no such repo" {
+		t.Errorf("unvalidated repo = %+v", e)
+	}
+	if got.Status != OverallFail {
+		t.Errorf("status = %q, want fail", got.Status)
+	}
+	want := BatchSummary{Total: 5, Pass: 1, Partial: 1, Fail: 2, Error: 1}
+	if got.Summary != want {
+		t.Errorf("summary = %+v, want %+v", got.Summary, want)
+	}
+
+	var tallies []string
+	for _, c := range got.Checks {
+		tallies = append(tallies, fmt.Sprintf("%s %d/%d %v", c.Name, c.Fail, c.Warn, c.Failing))
+	}
+	wantTallies := []string{
+		"has-init-command 2/0 [c d]",
+		"has-doctor-command 1/2 [d]",
+		"has-binary-release 0/1 []",
+	}
+	if strings.Join(tallies, "; ") != strings.Join(wantTallies, "; ") {
+		t.Errorf("checks = %q, want %q", tallies, wantTallies)
+	}
+}
+
+func TestValidateAll_WorstStatus(t *testing.T) {
+	tests := []struct {
+		statuses []string
+		want     string
+	}{
+		{[]string{OverallPass, OverallPass}, OverallPass},
+		{[]string{OverallPass, OverallPartial}, OverallPartial},
+		{[]string{OverallPartial, OverallFail, OverallPass}, OverallFail},
+	}
+	for _, tt := range tests {
+		var paths []string
+		for i := range tt.statuses {
+			paths = append(paths, fmt.Sprint(i))
+		}
+		got := ValidateAll(paths, 4, func(path string) (*ValidationResult, error) {
+			var i int
+			_, _ = fmt.Sscan(path, &i)
+			return &ValidationResult{Status: tt.statuses[i]}, nil
+		})
+		if got.Status != tt.want {
+			t.Errorf("%v: status = %q, want %q", tt.statuses, got.Status, tt.want)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package validator
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+	neturl "net/url"
+)
+
+// bitbucketDownloadsTag names the pseudo-release Bitbucket downloads are
+// reported as.
+const bitbucketDownloadsTag = "downloads"
+
+// bitbucketClient talks to the Bitbucket Cloud API (2.0). Bitbucket has no
+// releases; the repo's Downloads are treated as one release.
+type bitbucketClient struct {
+	restClient
+	// webURL serves repo archives, which the API does not.
+	webURL string
+}
+
+func newBitbucketClient(hc *http.Client) *bitbucketClient {
+	token := forgeToken(ForgeBitbucket)
+	c := &bitbucketClient{
+		restClient: restClient{
+			baseURL: "https://api.bitbucket.org/2.0",
+			authorize: func(r *http.Request) {
+				if token != "" {
+					r.Header.Set("Authorization", "Bearer "+token)
+				}
+			},
+			tokenHosts: []string{"bitbucket.org"},
+			// Downloads are served from S3, which gets no token.
+			redirectHosts: []string{"bbuseruploads.s3.amazonaws.com"},
+		},
+		webURL: "https://bitbucket.org",
+	}
+	c.httpClient = trustedRedirects(hc, c.redirectAllowed)
+	return c
+}
+
+// Name implements Forge.
+func (c *bitbucketClient) Name() string { return ForgeBitbucket }
+
+func (c *bitbucketClient) repoURL(owner, repo string) string {
+	return fmt.Sprintf("%s/repositories/%s/%s", c.baseURL, neturl.PathEscape(owner), neturl.PathEscape(repo))
+}
+
+// ResolveRef implements Forge.
+func (c *bitbucketClient) ResolveRef(owner, repo, ref string) (string, error) {
+	if ref == "" {
+		var info struct {
+			MainBranch struct {
+				Name string `json:"name"`
+			} `json:"mainbranch"`
+		}
+		if _, err := c.getJSON(c.repoURL(owner, repo), &info); err != nil {
+			return "", notFoundRef("default branch", err)
+		}
+		ref = info.MainBranch.Name
+	}
+	var commit struct {
+		Hash string `json:"hash"`
+	}
+	if _, err := c.getJSON(c.repoURL(owner, repo)+"/commit/"+neturl.PathEscape(ref), &commit); err != nil {
+		return "", notFoundRef(ref, err)
+	}
+	return commit.Hash, nil
+}
+
+// FetchArchive implements Forge.
+func (c *bitbucketClient) FetchArchive(owner, repo, ref string) (fs.FS, error) {
+	if ref == "" {
+		sha, err := c.ResolveRef(owner, repo, "")
+		if err != nil {
+			return nil, err
+		}
+		ref = sha
+	}
+	url := fmt.Sprintf("%s/%s/%s/get/%s.zip", c.webURL, neturl.PathEscape(owner), neturl.PathEscape(repo), neturl.PathEscape(ref))
+	return c.fetchZip(url, owner+"/"+repo)
+}
+
+// downloads lists the repo's Downloads as a release, following the "next"
+// links of the paginated response.
+func (c *bitbucketClient) downloads(owner, repo string) (*Release, error) {
+	rel := &Release{TagName: bitbucketDownloadsTag}
+	url := c.repoURL(owner, repo) + "/downloads?pagelen=100"
+	for page := 0; url != "" && page < maxReleasePages; page++ {
+		var body struct {
+			Values []struct {
+				Name  string `json:"name"`
+				Links struct {
+					Self struct {
+						Href string `json:"href"`
+					} `json:"self"`
+				} `json:"links"`
+			} `json:"values"`
+			Next string `json:"next"`
+		}
+		if _, err := c.getJSON(url, &body); err != nil {
+			return nil, fmt.Errorf("fetching downloads: %w", err)
+		}
+		for _, v := range body.Values {
+			rel.Assets = append(rel.Assets, ReleaseAsset{Name: v.Name, BrowserDownloadURL: v.Links.Self.Href})
+		}
+		url = body.Next
+	}
+	if url != "" {
+		return nil, pageLimitError("downloads", maxReleasePages)
+	}
+	return rel, nil
+}
+
+// EachRelease implements Forge. A repo with Downloads has one release.
+func (c *bitbucketClient) EachRelease(owner, repo string, fn func(*Release) bool) error {
+	rel, err := c.downloads(owner, repo)
+	if err != nil {
+		return err
+	}
+	if len(rel.Assets) > 0 {
+		fn(rel)
+	}
+	return nil
+}
+
+// ReleaseByTag implements Forge. Downloads are not tied to tags, so only
+// the "downloads" pseudo-tag matches.
+func (c *bitbucketClient) ReleaseByTag(owner, repo, tag string) (*Release, error) {
+	if tag != bitbucketDownloadsTag {
+		return nil, nil
+	}
+	rel, err := c.downloads(owner, repo)
+	if err != nil || len(rel.Assets) == 0 {
+		return nil, err
+	}
+	return rel, nil
+}
+
+// FetchAsset implements Forge.
+func (c *bitbucketClient) FetchAsset(a ReleaseAsset, max int64) ([]byte, error) {
+	return c.download(a.BrowserDownloadURL, max)
+}
@@ -0,0 +1,87 @@
+package validator
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// bitbucketServer fakes the Bitbucket API and web host for ws/tool, whose
+// Downloads span two pages.
+func bitbucketServer(t *testing.T, archivePath *string) (*httptest.Server, *bitbucketClient) {
+	t.Helper()
+	t.Setenv("BITBUCKET_TOKEN", "bb")
+	var srv *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/repositories/ws/tool", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `{"mainbranch":{"name":"master"}}`)
+	})
+	mux.HandleFunc("/api/repositories/ws/tool/commit/master", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `{"hash":"f00d"}`)
+	})
+	mux.HandleFunc("/api/repositories/ws/tool/downloads", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			_, _ = fmt.Fprint(w, `{"values":[{"name":"tool_darwin_arm64.tar.gz","links":{"self":{"href":"https://x/2"}}}]}`)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"values":[{"name":"tool_linux_amd64.tar.gz","links":{"self":{"href":"https://x/1"}}}],"next":"%s/api/repositories/ws/tool/downloads?page=2"}`, srv.URL)
+	})
+	mux.HandleFunc("/ws/tool/get/", func(w http.ResponseWriter, r *http.Request) {
+		*archivePath = r.URL.Path
+		_, _ = w.Write(zipball(t, map[string]string{"SKILL.md": "# tool\n"}))
+	})
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer bb" {
+			t.Errorf("Authorization = %q", got)
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	client := newBitbucketClient(nil)
+	client.baseURL = srv.URL + "/api"
+	client.webURL = srv.URL
+	client.httpClient = srv.Client()
+	return srv, client
+}
+
+func TestBitbucket_ResolveRefAndArchive(t *testing.T) {
+	var archivePath string
+	srv, client := bitbucketServer(t, &archivePath)
+	defer srv.Close()
+
+	if sha, err := client.ResolveRef("ws", "tool", ""); err != nil || sha != "f00d" {
+		t.Errorf("ResolveRef = %q, %v", sha, err)
+	}
+	if _, err := client.ResolveRef("ws", "tool", "nope"); err == nil {
+		t.Error("expected error for unknown ref")
+	}
+
+	fsys, err := client.FetchArchive("ws", "tool", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if archivePath != "/ws/tool/get/f00d.zip" {
+		t.Errorf("archive path = %q", archivePath)
+	}
+	if _, err := fs.Stat(fsys, "SKILL.md"); err != nil {
+		t.Errorf("SKILL.md missing: %v", err)
+	}
+}
+
+func TestBitbucket_Downloads(t *testing.T) {
+	var archivePath string
+	srv, client := bitbucketServer(t, &archivePath)
+	defer srv.Close()
+
+	rel, _, err := selectRelease(client, "ws", "tool", ReleaseLatest)
+	if err != nil || rel == nil {
+		t.Fatalf("latest = %v, %v", rel, err)
+	}
+	if rel.TagName != bitbucketDownloadsTag || len(rel.Assets) != 2 {
+		t.Errorf("release = %+v, want both pages of downloads", rel)
+	}
+	if rel, err := client.ReleaseByTag("ws", "tool", "v1.0.0"); err != nil || rel != nil {
+		t.Errorf("tag lookup = %v, %v; want nil, nil", rel, err)
+	}
+}
@@ -2,10 +2,12 @@ package validator
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
+	"io/fs"
+	"slices"
 	"strings"
 
+	"github.com/ppiankov/ancc/internal/drift"
+	"github.com/ppiankov/ancc/internal/release"
 	"github.com/ppiankov/ancc/internal/skillmd"
 )
 
@@ -22,6 +24,7 @@ const (
 	CheckHasInitCommand   = "has-init-command"
 	CheckHasDoctorCommand = "has-doctor-command"
 	CheckHasBinaryRelease = "has-binary-release"
+	CheckCommandDrift     = "command-drift"
 )
 
 func pass(name, msg string) CheckResult {
@@ -36,32 +39,53 @@ func warn(name, msg string) CheckResult {
 	return CheckResult{Name: name, Status: StatusWarn, Message: msg}
 }
 
-// checkSkillMDExists verifies SKILL.md exists at the repo root.
-func checkSkillMDExists(path string) CheckResult {
-	p := filepath.Join(path, "SKILL.md")
-	if _, err := os.Stat(p); err != nil {
-		return fail(CheckSkillMDExists, "SKILL.md not found at repo root")
+// checkSkillMDExists verifies SKILL.md exists at the root of fsys, the
+// directory dir of the repo.
+func checkSkillMDExists(fsys fs.FS, dir string) CheckResult {
+	where := "at repo root"
+	if dir != "." {
+		where = "in " + dir
 	}
-	return pass(CheckSkillMDExists, "SKILL.md found at repo root")
+	if _, err := fs.Stat(fsys, "SKILL.md"); err != nil {
+		return at(fail(CheckSkillMDExists, "SKILL.md not found "+where), 0)
+	}
+	return at(pass(CheckSkillMDExists, "SKILL.md found "+where), 0)
+}
+
+// at points r at a line of SKILL.md, or at the whole file for line 0.
+func at(r CheckResult, line int) CheckResult {
+	r.Locations = []Location{{Path: "SKILL.md", Line: line}}
+	return r
+}
+
+// sectionLine returns the line of the named section, or of the heading it
+// belongs under when it is missing.
+func sectionLine(sf *skillmd.SkillFile, heading string) int {
+	if sec := sf.Sections[heading]; sec != nil {
+		return sec.Line
+	}
+	return sf.Line
 }
 
 // checkInstall verifies the Install section exists.
 func checkInstall(sf *skillmd.SkillFile) CheckResult {
+	line := sectionLine(sf, skillmd.SectionInstall)
 	if sf.Sections[skillmd.SectionInstall] == nil {
-		return fail(CheckSkillMDInstall, "missing ## Install section")
+		return at(fail(CheckSkillMDInstall, "missing ## Install section"), line)
 	}
-	return pass(CheckSkillMDInstall, "Install section found")
+	return at(pass(CheckSkillMDInstall, "Install section found"), line)
 }
 
 // checkCommands verifies the Commands section exists with at least one command.
 func checkCommands(sf *skillmd.SkillFile) CheckResult {
+	line := sectionLine(sf, skillmd.SectionCommands)
 	if sf.Sections[skillmd.SectionCommands] == nil {
-		return fail(CheckSkillMDCommands, "missing ## Commands section")
+		return at(fail(CheckSkillMDCommands, "missing ## Commands section"), line)
 	}
 	if len(sf.Commands) == 0 {
-		return fail(CheckSkillMDCommands, "Commands section has no documented commands")
+		return at(fail(CheckSkillMDCommands, "Commands section has no documented commands"), line)
 	}
-	return pass(CheckSkillMDCommands, fmt.Sprintf("%d command(s) documented", len(sf.Commands)))
+	return at(pass(CheckSkillMDCommands, fmt.Sprintf("%d command(s) documented", len(sf.Commands))), line)
 }
 
 // checkFlags verifies at least one command documents --format json.
@@ -69,57 +93,59 @@ func checkFlags(sf *skillmd.SkillFile) CheckResult {
 	for _, cmd := range sf.Commands {
 		for _, f := range cmd.Flags {
 			if strings.Contains(f.Name, "--format json") {
-				return pass(CheckSkillMDFlags, "--format json flag documented")
+				return at(pass(CheckSkillMDFlags, "--format json flag documented"), f.Pos.Line)
 			}
 		}
 	}
-	return fail(CheckSkillMDFlags, "no command documents --format json flag")
+	return at(fail(CheckSkillMDFlags, "no command documents --format json flag"), sectionLine(sf, skillmd.SectionCommands))
 }
 
 // checkJSONOutput verifies at least one command shows a JSON output schema.
 func checkJSONOutput(sf *skillmd.SkillFile) CheckResult {
 	for _, cmd := range sf.Commands {
 		if cmd.JSONOutput != "" {
-			return pass(CheckSkillMDJSON, "JSON output schema documented")
+			return at(pass(CheckSkillMDJSON, "JSON output schema documented"), cmd.Pos.Line)
 		}
 	}
-	return fail(CheckSkillMDJSON, "no command shows JSON output schema")
+	return at(fail(CheckSkillMDJSON, "no command shows JSON output schema"), sectionLine(sf, skillmd.SectionCommands))
 }
 
 // checkExitCodes verifies at least one command documents exit codes.
 func checkExitCodes(sf *skillmd.SkillFile) CheckResult {
 	for _, cmd := range sf.Commands {
 		if len(cmd.ExitCodes) > 0 {
-			return pass(CheckSkillMDExitCodes, "exit codes documented")
+			return at(pass(CheckSkillMDExitCodes, "exit codes documented"), cmd.Pos.Line)
 		}
 	}
-	return fail(CheckSkillMDExitCodes, "no command documents exit codes")
+	return at(fail(CheckSkillMDExitCodes, "no command documents exit codes"), sectionLine(sf, skillmd.SectionCommands))
 }
 
 // checkNotDo verifies the "What this does NOT do" section exists.
 func checkNotDo(sf *skillmd.SkillFile) CheckResult {
+	line := sectionLine(sf, skillmd.SectionWhatNotDo)
 	if sf.Sections[skillmd.SectionWhatNotDo] == nil {
-		return fail(CheckSkillMDNotDo, "missing \"What this does NOT do\" section")
+		return at(fail(CheckSkillMDNotDo, "missing \"What this does NOT do\" section"), line)
 	}
-	return pass(CheckSkillMDNotDo, "\"What this does NOT do\" section found")
+	return at(pass(CheckSkillMDNotDo, "\"What this does NOT do\" section found"), line)
 }
 
 // checkParsing verifies the parsing examples section exists.
 func checkParsing(sf *skillmd.SkillFile) CheckResult {
+	line := sectionLine(sf, skillmd.SectionParsingExamples)
 	if sf.Sections[skillmd.SectionParsingExamples] == nil {
-		return fail(CheckSkillMDParsing, "missing \"Parsing examples\" section")
+		return at(fail(CheckSkillMDParsing, "missing \"Parsing examples\" section"), line)
 	}
-	return pass(CheckSkillMDParsing, "Parsing examples section found")
+	return at(pass(CheckSkillMDParsing, "Parsing examples section found"), line)
 }
 
 // checkInitCommand verifies a command named "init" is documented.
 func checkInitCommand(sf *skillmd.SkillFile) CheckResult {
 	for _, cmd := range sf.Commands {
 		if strings.HasSuffix(cmd.Name, " init") || cmd.Name == "init" {
-			return pass(CheckHasInitCommand, "init command documented")
+			return at(pass(CheckHasInitCommand, "init command documented"), cmd.Pos.Line)
 		}
 	}
-	return fail(CheckHasInitCommand, "no init command documented")
+	return at(fail(CheckHasInitCommand, "no init command documented"), sectionLine(sf, skillmd.SectionCommands))
 }
 
 // checkDoctorCommand verifies a command named "doctor" is documented.
@@ -127,15 +153,74 @@ func checkInitCommand(sf *skillmd.SkillFile) CheckResult {
 func checkDoctorCommand(sf *skillmd.SkillFile) CheckResult {
 	for _, cmd := range sf.Commands {
 		if strings.HasSuffix(cmd.Name, " doctor") || cmd.Name == "doctor" {
-			return pass(CheckHasDoctorCommand, "doctor command documented")
+			return at(pass(CheckHasDoctorCommand, "doctor command documented"), cmd.Pos.Line)
 		}
 	}
-	return warn(CheckHasDoctorCommand, "no doctor command documented (recommended)")
+	return at(warn(CheckHasDoctorCommand, "no doctor command documented (recommended)"), sectionLine(sf, skillmd.SectionCommands))
 }
 
-// checkBinaryRelease checks for binary release assets.
-// For now, this only applies to GitHub repos and warns otherwise.
-func checkBinaryRelease(_ string) CheckResult {
-	// GitHub release checking is WO-005 scope.
-	return warn(CheckHasBinaryRelease, "binary release check requires GitHub URL (skipped)")
+// checkBinaryRelease looks for a release pipeline in a local checkout:
+// GoReleaser or cargo-dist config, a release workflow, or a Makefile
+// release target. It reports the platforms that would be published and
+// warns when they are known and miss a required one.
+func checkBinaryRelease(fsys fs.FS, required []release.Target) CheckResult {
+	p, err := release.Detect(fsys)
+	if err != nil {
+		return warn(CheckHasBinaryRelease, fmt.Sprintf("could not read release config: %v", err))
+	}
+	if p == nil {
+		return warn(CheckHasBinaryRelease, "no binary release config found (goreleaser, cargo-dist, release workflow or Makefile target)")
+	}
+	if missing := release.Missing(required, p.Targets); len(p.Targets) > 0 && len(missing) > 0 {
+		return warn(CheckHasBinaryRelease, fmt.Sprintf("release pipeline: %s; missing %s", p.Summary(), release.JoinTargets(missing)))
+	}
+	return pass(CheckHasBinaryRelease, "release pipeline: "+p.Summary())
+}
+
+// checkCommandDrift compares documented commands and flags with those a
+// source analyzer finds in the repo. It reports false when no analyzer
+// applies, so repos in unsupported languages keep the standard check set.
+func checkCommandDrift(fsys fs.FS, sf *skillmd.SkillFile) (CheckResult, bool) {
+	a := drift.Detect(fsys)
+	if a == nil {
+		return CheckResult{}, false
+	}
+
+	implemented, err := a.Analyze(fsys)
+	if err != nil {
+		return at(warn(CheckCommandDrift, fmt.Sprintf("%s: could not analyze source: %v", a.Name(), err)), sectionLine(sf, skillmd.SectionCommands)), true
+	}
+	if len(implemented) == 0 {
+		return at(warn(CheckCommandDrift, fmt.Sprintf("%s: no commands found in source", a.Name())), sectionLine(sf, skillmd.SectionCommands)), true
+	}
+
+	issues := drift.Diff(sf, implemented)
+	if len(issues) == 0 {
+		return pass(CheckCommandDrift, fmt.Sprintf("%s: documented commands match source", a.Name())), true
+	}
+
+	// Documenting something that does not exist misleads agents; leaving
+	// something undocumented only hides it.
+	r := warn(CheckCommandDrift, drift.Summarize(a.Name(), issues))
+	for _, i := range issues {
+		if i.Kind == drift.IssueMissing {
+			r.Status = StatusFail
+		}
+		// Missing items are located in SKILL.md, undocumented ones in the
+		// source, when the analyzer knows where.
+		loc := Location{Path: "SKILL.md", Line: i.Pos.Line}
+		if i.Kind == drift.IssueUndocumented {
+			if i.Pos.File == "" {
+				continue
+			}
+			loc.Path = i.Pos.File
+		}
+		if !slices.Contains(r.Locations, loc) {
+			r.Locations = append(r.Locations, loc)
+		}
+	}
+	if len(r.Locations) == 0 {
+		r = at(r, sectionLine(sf, skillmd.SectionCommands))
+	}
+	return r, true
 }
@@ -0,0 +1,90 @@
+package validator
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ppiankov/ancc/internal/config"
+)
+
+// repoMarkers are the files that make a directory a repo to validate.
+var repoMarkers = []string{"SKILL.md", "go.mod"}
+
+// skipDirs are never searched for repos.
+var skipDirs = map[string]bool{"vendor": true, "node_modules": true, "testdata": true}
+
+// Discover returns the directories under root, root included, that hold a
+// SKILL.md or go.mod, in lexical order. A repo found is not searched
+// further, and hidden directories, vendor, node_modules and testdata are
+// skipped.
+func Discover(root string) ([]string, error) {
+	var repos []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && (strings.HasPrefix(d.Name(), ".") || skipDirs[d.Name()]) {
+			return filepath.SkipDir
+		}
+		for _, m := range repoMarkers {
+			if _, err := os.Stat(filepath.Join(path, m)); err == nil {
+				repos = append(repos, path)
+				return filepath.SkipDir
+			}
+		}
+		return nil
+	})
+	return repos, err
+}
+
+// toolDirs returns the directories of fsys documented by a SKILL.md: those
+// listed, else those of the target's .ancc.yml, else every directory
+// holding one, in lexical order with the root first.
+func toolDirs(fsys fs.FS, listed []string) ([]string, error) {
+	if len(listed) == 0 {
+		cfg, err := config.LoadFS(fsys)
+		if err != nil {
+			return nil, err
+		}
+		listed = cfg.Tools
+	}
+	if len(listed) > 0 {
+		dirs := make([]string, 0, len(listed))
+		for _, d := range listed {
+			clean := path.Clean(strings.TrimPrefix(filepath.ToSlash(d), "./"))
+			if !fs.ValidPath(clean) {
+				return nil, fmt.Errorf("tool directory %q is outside the repo", d)
+			}
+			if !slices.Contains(dirs, clean) {
+				dirs = append(dirs, clean)
+			}
+		}
+		return dirs, nil
+	}
+
+	var dirs []string
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != "." && (strings.HasPrefix(d.Name(), ".") || skipDirs[d.Name()]) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.Name() == "SKILL.md" {
+			dirs = append(dirs, path.Dir(p))
+		}
+		return nil
+	})
+	return dirs, err
+}
@@ -0,0 +1,108 @@
+package validator
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func TestDiscover(t *testing.T) {
+	root := t.TempDir()
+	for _, f := range []string{
+		"tools/b/go.mod",
+		"tools/b/cmd/inner/SKILL.md", // inside a repo already found
+		"tools/a/SKILL.md",
+		"tools/.cache/x/SKILL.md",
+		"tools/c/README.md",
+		"tools/c/deep/nested/go.mod",
+		"vendor/dep/go.mod",
+		"node_modules/pkg/SKILL.md",
+	} {
+		path := filepath.Join(root, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := Discover(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		filepath.Join(root, "tools/a"),
+		filepath.Join(root, "tools/b"),
+		filepath.Join(root, "tools/c/deep/nested"),
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Discover = %q, want %q", got, want)
+	}
+
+	// A repo root is itself the only repo.
+	got, err = Discover(filepath.Join(root, "tools/b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0] != filepath.Join(root, "tools/b") {
+		t.Errorf("Discover(repo) = %q", got)
+	}
+}
+
+func TestDiscover_Missing(t *testing.T) {
+	if _, err := Discover(filepath.Join(t.TempDir(), "none")); err == nil {
+		t.Error("want an error for a missing directory")
+	}
+}
+
+func TestToolDirs(t *testing.T) {
+	fsys := fstest.MapFS{
+		"SKILL.md":                  {},
+		"cmd/b/SKILL.md":            {},
+		"cmd/a/SKILL.md":            {},
+		"cmd/a/README.md":           {},
+		".github/SKILL.md":          {},
+		"testdata/broken/SKILL.md":  {},
+		"vendor/dep/SKILL.md":       {},
+		"internal/notes/SKILL.txt":  {},
+		"tools/c/nested/d/SKILL.md": {},
+	}
+	got, err := toolDirs(fsys, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{".", "cmd/a", "cmd/b", "tools/c/nested/d"}
+	if !slices.Equal(got, want) {
+		t.Errorf("toolDirs = %q, want %q", got, want)
+	}
+
+	// A config list replaces discovery.
+	fsys[".ancc.yml"] = &fstest.MapFile{Data: []byte("tools:\n  - ./cmd/b\n  - cmd/missing/\n  - cmd/b\n")}
+	got, err = toolDirs(fsys, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"cmd/b", "cmd/missing"}; !slices.Equal(got, want) {
+		t.Errorf("toolDirs(config) = %q, want %q", got, want)
+	}
+
+	// And a given list replaces the config.
+	got, err = toolDirs(fsys, []string{"cmd/a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"cmd/a"}; !slices.Equal(got, want) {
+		t.Errorf("toolDirs(listed) = %q, want %q", got, want)
+	}
+}
+
+func TestToolDirs_OutsideRepo(t *testing.T) {
+	for _, dir := range []string{"../other", "/etc", "cmd/../../x"} {
+		if _, err := toolDirs(fstest.MapFS{}, []string{dir}); err == nil {
+			t.Errorf("toolDirs(%q): want an error", dir)
+		}
+	}
+}
@@ -0,0 +1,433 @@
+package validator
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"strings"
+
+	"github.com/ppiankov/ancc/internal/httpclient"
+)
+
+// Forge names.
+const (
+	ForgeGitHub    = "github"
+	ForgeGitLab    = "gitlab"
+	ForgeGitea     = "gitea" // Gitea and Forgejo, including Codeberg
+	ForgeBitbucket = "bitbucket"
+)
+
+// Forge is a code hosting service: it resolves refs, serves repo archives
+// and lists releases with their assets.
+type Forge interface {
+	// Name returns one of the Forge* constants.
+	Name() string
+	// ResolveRef returns the commit SHA ref points to. An empty ref
+	// resolves the default branch.
+	ResolveRef(owner, repo, ref string) (string, error)
+	// FetchArchive returns the repo's files at ref, or at the default
+	// branch when ref is empty.
+	FetchArchive(owner, repo, ref string) (fs.FS, error)
+	// EachRelease calls fn on releases, newest first, until fn returns
+	// true or the releases run out.
+	EachRelease(owner, repo string, fn func(*Release) bool) error
+	// ReleaseByTag returns the release tagged tag, or nil when there is
+	// none.
+	ReleaseByTag(owner, repo, tag string) (*Release, error)
+	// FetchAsset downloads a release asset, reading at most max bytes.
+	FetchAsset(a ReleaseAsset, max int64) ([]byte, error)
+}
+
+// Release is a published release. The JSON tags match the GitHub and Gitea
+// APIs; other forges convert to it.
+type Release struct {
+	TagName    string         `json:"tag_name"`
+	Draft      bool           `json:"draft"`
+	Prerelease bool           `json:"prerelease"`
+	Assets     []ReleaseAsset `json:"assets"`
+}
+
+// ReleaseAsset is a file attached to a release.
+type ReleaseAsset struct {
+	Name               string `json:"name"`
+	URL                string `json:"url"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+	Digest             string `json:"digest"`
+}
+
+// RemoteRepo identifies a repo on a forge, and the git ref to read when one
+// is given.
+type RemoteRepo struct {
+	Forge string
+	Host  string
+	Owner string // GitLab owners may be nested groups: "group/subgroup"
+	Repo  string
+	Ref   string // branch, tag or commit SHA; empty for the default branch
+}
+
+// String returns host/owner/repo.
+func (r *RemoteRepo) String() string {
+	return r.Host + "/" + r.Owner + "/" + r.Repo
+}
+
+// Token environment variables, per forge. The first one set is used.
+var forgeTokenEnv = map[string][]string{
+	ForgeGitHub:    {"GITHUB_TOKEN"},
+	ForgeGitLab:    {"GITLAB_TOKEN"},
+	ForgeGitea:     {"GITEA_TOKEN", "FORGEJO_TOKEN"},
+	ForgeBitbucket: {"BITBUCKET_TOKEN"},
+}
+
+func forgeToken(forge string) string {
+	return firstEnv(forgeTokenEnv[forge])
+}
+
+// firstEnv returns the value of the first variable in names that is set.
+func firstEnv(names []string) string {
+	for _, name := range names {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// Forges configures how repo URLs map to forges beyond the public hosts:
+// GitHub Enterprise hosts and the API they are reached through. The zero
+// value knows the public forges only.
+type Forges struct {
+	// GitHubAPIURL is the API base of GitHub Enterprise hosts, e.g.
+	// https://ghe.example.com/api/v3. Its host is a GitHub host. Empty
+	// means https://<host>/api/v3 per host. github.com always uses
+	// https://api.github.com.
+	GitHubAPIURL string
+	// GitHubHosts lists further GitHub Enterprise hosts, e.g.
+	// ghe.example.com.
+	GitHubHosts []string
+	// HTTPClient sends the API requests. Nil means a client with the
+	// httpclient defaults: timeouts and retries, but no cache.
+	HTTPClient *http.Client
+}
+
+var defaultHTTPClient = httpclient.MustNew(httpclient.Options{})
+
+// gitHubHosts returns the GitHub Enterprise hosts, including the host of
+// GitHubAPIURL.
+func (f Forges) gitHubHosts() []string {
+	var hosts []string
+	seen := map[string]bool{"github.com": true}
+	add := func(h string) {
+		h = strings.ToLower(strings.TrimSpace(h))
+		if h != "" && !seen[h] {
+			seen[h] = true
+			hosts = append(hosts, h)
+		}
+	}
+	if f.GitHubAPIURL != "" {
+		add(enterpriseHost(f.GitHubAPIURL))
+	}
+	for _, h := range f.GitHubHosts {
+		add(h)
+	}
+	return hosts
+}
+
+// NewForge returns a client for the forge hosting r.
+func NewForge(r *RemoteRepo) (Forge, error) {
+	return Forges{}.NewForge(r)
+}
+
+// NewForge returns a client for the forge hosting r. GitHub hosts other
+// than github.com get a GitHub Enterprise client.
+func (f Forges) NewForge(r *RemoteRepo) (Forge, error) {
+	hc := f.HTTPClient
+	if hc == nil {
+		hc = defaultHTTPClient
+	}
+	switch r.Forge {
+	case ForgeGitHub:
+		if r.Host == "" || r.Host == "github.com" {
+			return newGitHubClient(hc), nil
+		}
+		apiURL := f.GitHubAPIURL
+		if enterpriseHost(apiURL) == "github.com" {
+			// GITHUB_API_URL as GitHub Actions sets it on github.com.
+			apiURL = ""
+		}
+		return newGitHubEnterpriseClient(r.Host, apiURL, hc), nil
+	case ForgeGitLab:
+		return newGitLabClient(r.Host, hc), nil
+	case ForgeGitea:
+		return newGiteaClient(r.Host, hc), nil
+	case ForgeBitbucket:
+		return newBitbucketClient(hc), nil
+	}
+	return nil, fmt.Errorf("unsupported forge %q", r.Forge)
+}
+
+// wellKnownHosts maps public forge hosts to their forge. Self-hosted
+// instances are recognized by "gitlab", "gitea" or "forgejo" in the host
+// name.
+var wellKnownHosts = map[string]string{
+	"github.com":    ForgeGitHub,
+	"gitlab.com":    ForgeGitLab,
+	"codeberg.org":  ForgeGitea,
+	"gitea.com":     ForgeGitea,
+	"bitbucket.org": ForgeBitbucket,
+}
+
+func hostForge(host string) string {
+	if f, ok := wellKnownHosts[host]; ok {
+		return f
+	}
+	for _, label := range strings.FieldsFunc(host, func(r rune) bool { return r == '.' || r == '-' }) {
+		switch label {
+		case "gitlab":
+			return ForgeGitLab
+		case "gitea", "forgejo":
+			return ForgeGitea
+		}
+	}
+	return ""
+}
+
+// ParseRepoURL recognizes repo URLs on GitHub, GitLab, Gitea/Forgejo and
+// Bitbucket Cloud, in HTTPS, SSH and scheme-less forms, with an optional
+// @ref suffix or the forge's branch URL (/tree/<ref>, /-/tree/<ref>,
+// /src/branch/<ref>, /src/<ref>). Self-hosted hosts need a scheme or SSH
+// form. Returns nil if the input is not a repo reference.
+func ParseRepoURL(input string) *RemoteRepo {
+	return Forges{}.ParseRepoURL(input)
+}
+
+// ParseRepoURL is like the package-level ParseRepoURL, and also recognizes
+// the configured GitHub Enterprise hosts, with or without a scheme.
+func (f Forges) ParseRepoURL(input string) *RemoteRepo {
+	if gh := ParseGitHubURL(input); gh != nil {
+		return gh
+	}
+	for _, host := range f.gitHubHosts() {
+		if r := parseGitHubHost(gitHubURLPattern(host), host, input); r != nil {
+			return r
+		}
+	}
+
+	host, rest, explicit := splitRepoURL(input)
+	forge := hostForge(host)
+	if forge == "" || forge == ForgeGitHub {
+		return nil
+	}
+	if _, known := wellKnownHosts[host]; !known && !explicit {
+		return nil
+	}
+
+	rest = strings.Trim(rest, "/")
+	var ref string
+	switch forge {
+	case ForgeGitLab:
+		rest, ref, _ = cutAny(rest, "/-/tree/", "/-/blob/", "/-/commit/")
+	case ForgeGitea:
+		rest, ref, _ = cutAny(rest, "/src/branch/", "/src/tag/", "/src/commit/")
+	case ForgeBitbucket:
+		rest, ref, _ = cutAny(rest, "/src/")
+	}
+	if ref == "" {
+		if i := strings.LastIndex(rest, "@"); i > 0 {
+			rest, ref = rest[:i], rest[i+1:]
+		}
+	}
+	rest = strings.TrimSuffix(rest, ".git")
+
+	parts := strings.Split(rest, "/")
+	if len(parts) < 2 || (forge != ForgeGitLab && len(parts) != 2) {
+		return nil
+	}
+	for _, p := range parts {
+		if p == "" {
+			return nil
+		}
+	}
+	return &RemoteRepo{
+		Forge: forge,
+		Host:  host,
+		Owner: strings.Join(parts[:len(parts)-1], "/"),
+		Repo:  parts[len(parts)-1],
+		Ref:   strings.Trim(ref, "/"),
+	}
+}
+
+// splitRepoURL splits a URL or SSH remote into host and path. explicit
+// reports whether the input had a scheme or SSH form, not just a host.
+func splitRepoURL(input string) (host, rest string, explicit bool) {
+	if i := strings.Index(input, "://"); i > 0 {
+		u, err := neturl.Parse(input)
+		if err != nil {
+			return "", "", false
+		}
+		return strings.ToLower(u.Hostname()), u.Path, true
+	}
+	if at := strings.Index(input, "@"); at > 0 && at < strings.Index(input+":", ":") {
+		// scp-like SSH: git@host:owner/repo.git
+		if h, p, ok := strings.Cut(input[at+1:], ":"); ok {
+			return strings.ToLower(h), p, true
+		}
+	}
+	h, p, _ := strings.Cut(input, "/")
+	return strings.ToLower(h), p, false
+}
+
+// cutAny cuts s around the first separator present.
+func cutAny(s string, seps ...string) (before, after string, found bool) {
+	for _, sep := range seps {
+		if b, a, ok := strings.Cut(s, sep); ok {
+			return b, a, true
+		}
+	}
+	return s, "", false
+}
+
+// errNotFound reports a 404 from a forge API.
+var errNotFound = errors.New("not found")
+
+// pageLimitError reports a listing cut off at its page limit, so what lies
+// beyond it is not mistaken for missing.
+func pageLimitError(what string, pages int) error {
+	return fmt.Errorf("%s: stopped listing at the limit of %d pages", what, pages)
+}
+
+// restClient is the HTTP plumbing the GitLab, Gitea and Bitbucket clients
+// share.
+type restClient struct {
+	baseURL    string
+	httpClient *http.Client
+	authorize  func(*http.Request)
+	// tokenHosts are the domains, with their subdomains, that the token is
+	// sent to besides the API host: the forge's own download hosts.
+	tokenHosts []string
+	// redirectHosts are the domains, with their subdomains, that redirects
+	// may lead to besides the hosts the token is sent to.
+	redirectHosts []string
+}
+
+// authorizes reports whether the token may be sent to u. Release links
+// may point anywhere, so only the forge's own hosts get it.
+func (c *restClient) authorizes(u *neturl.URL) bool {
+	if api, err := neturl.Parse(c.baseURL); err == nil && strings.EqualFold(u.Host, api.Host) {
+		return true
+	}
+	for _, d := range c.tokenHosts {
+		if hostWithin(u.Hostname(), d) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *restClient) get(url, accept string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if c.authorize != nil && c.authorizes(req.URL) {
+		c.authorize(req)
+	}
+	resp, err := c.httpClient.Do(req)
+	return resp, requestError(err)
+}
+
+// requestError unwraps a rate limit from the *url.Error around it, whose
+// message already names the host.
+func requestError(err error) error {
+	var rl *httpclient.RateLimitError
+	if errors.As(err, &rl) {
+		return rl
+	}
+	return err
+}
+
+// getJSON decodes a successful response into v and returns its headers.
+// A 404 returns errNotFound.
+func (c *restClient) getJSON(url string, v any) (http.Header, error) {
+	resp, err := c.get(url, "application/json")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, errNotFound
+	default:
+		return nil, fmt.Errorf("API error: %s", resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", url, err)
+	}
+	return resp.Header, nil
+}
+
+// download reads at most max bytes from url.
+func (c *restClient) download(url string, max int64) ([]byte, error) {
+	resp, err := c.get(url, "")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, errNotFound
+	default:
+		return nil, fmt.Errorf("downloading %s: %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, max+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > max {
+		return nil, fmt.Errorf("%s exceeds %d bytes", url, max)
+	}
+	return data, nil
+}
+
+// fetchZip downloads a repo zip archive and serves its files from memory.
+func (c *restClient) fetchZip(url, repo string) (fs.FS, error) {
+	data, err := c.download(url, maxArchiveSize)
+	if errors.Is(err, errNotFound) {
+		return nil, fmt.Errorf("repository %s not found", repo)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fetching archive of %s: %w", repo, err)
+	}
+	return readZip(data)
+}
+
+// readZip serves the files of a zip archive from memory, descending into
+// the single top-level directory forges wrap repo archives in.
+func readZip(data []byte) (fs.FS, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("reading archive: %w", err)
+	}
+	return archiveRoot(zr)
+}
+
+// notFoundRef maps errNotFound from a ref lookup to errRefNotFound.
+func notFoundRef(ref string, err error) error {
+	if errors.Is(err, errNotFound) {
+		return fmt.Errorf("resolving %s: %w", ref, errRefNotFound)
+	}
+	return fmt.Errorf("resolving %s: %w", ref, err)
+}
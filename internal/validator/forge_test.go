@@ -0,0 +1,191 @@
+package validator
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseRepoURL(t *testing.T) {
+	tests := []struct {
+		input string
+		want  *RemoteRepo // nil when input is not a repo URL
+	}{
+		{"github.com/foo/bar@v1", &RemoteRepo{ForgeGitHub, "github.com", "foo", "bar", "v1"}},
+		{"gitlab.com/foo/bar", &RemoteRepo{ForgeGitLab, "gitlab.com", "foo", "bar", ""}},
+		{"https://gitlab.com/group/sub/bar.git", &RemoteRepo{ForgeGitLab, "gitlab.com", "group/sub", "bar", ""}},
+		{"https://gitlab.com/group/bar/-/tree/feature/x", &RemoteRepo{ForgeGitLab, "gitlab.com", "group", "bar", "feature/x"}},
+		{"git@gitlab.com:group/sub/bar.git", &RemoteRepo{ForgeGitLab, "gitlab.com", "group/sub", "bar", ""}},
+		{"https://gitlab.example.com/team/bar", &RemoteRepo{ForgeGitLab, "gitlab.example.com", "team", "bar", ""}},
+		{"codeberg.org/foo/bar@main", &RemoteRepo{ForgeGitea, "codeberg.org", "foo", "bar", "main"}},
+		{"https://codeberg.org/foo/bar/src/branch/dev", &RemoteRepo{ForgeGitea, "codeberg.org", "foo", "bar", "dev"}},
+		{"https://git.forgejo.example.org/foo/bar/src/tag/v1.2.0", &RemoteRepo{ForgeGitea, "git.forgejo.example.org", "foo", "bar", "v1.2.0"}},
+		{"ssh://git@gitea.example.com/foo/bar.git", &RemoteRepo{ForgeGitea, "gitea.example.com", "foo", "bar", ""}},
+		{"bitbucket.org/ws/bar", &RemoteRepo{ForgeBitbucket, "bitbucket.org", "ws", "bar", ""}},
+		{"https://bitbucket.org/ws/bar/src/release", &RemoteRepo{ForgeBitbucket, "bitbucket.org", "ws", "bar", "release"}},
+		{"git@bitbucket.org:ws/bar.git", &RemoteRepo{ForgeBitbucket, "bitbucket.org", "ws", "bar", ""}},
+		// Self-hosted hosts need a scheme: this could be a local path.
+		{"gitlab.example.com/team/bar", nil},
+		{"codeberg.org/foo/bar/baz", nil},
+		{"https://example.com/foo/bar", nil},
+		{"gitlab.com/foo", nil},
+		{"/some/local/path", nil},
+		{".", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := ParseRepoURL(tt.input)
+			switch {
+			case tt.want == nil && got != nil:
+				t.Errorf("expected nil, got %+v", got)
+			case tt.want != nil && (got == nil || *got != *tt.want):
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemoteRepo_String(t *testing.T) {
+	r := ParseRepoURL("https://gitlab.com/group/sub/bar@v1")
+	if got := r.String(); got != "gitlab.com/group/sub/bar" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestNewForge(t *testing.T) {
+	for _, input := range []string{"github.com/a/b", "gitlab.com/a/b", "codeberg.org/a/b", "bitbucket.org/a/b"} {
+		r := ParseRepoURL(input)
+		f, err := NewForge(r)
+		if err != nil {
+			t.Fatalf("%s: %v", input, err)
+		}
+		if f.Name() != r.Forge {
+			t.Errorf("%s: forge %s, want %s", input, f.Name(), r.Forge)
+		}
+	}
+	if _, err := NewForge(&RemoteRepo{Forge: "sourcehut"}); err == nil {
+		t.Error("expected error for unsupported forge")
+	}
+}
+
+func TestForgeToken(t *testing.T) {
+	t.Setenv("GITEA_TOKEN", "")
+	t.Setenv("FORGEJO_TOKEN", "fj")
+	if got := forgeToken(ForgeGitea); got != "fj" {
+		t.Errorf("forgeToken(gitea) = %q, want fj", got)
+	}
+	t.Setenv("GITEA_TOKEN", "gt")
+	if got := forgeToken(ForgeGitea); got != "gt" {
+		t.Errorf("forgeToken(gitea) = %q, want gt", got)
+	}
+}
+
+func TestForges_ParseRepoURL(t *testing.T) {
+	forges := Forges{GitHubAPIURL: "https://ghe.example.com/api/v3", GitHubHosts: []string{"Git.Corp.Example"}}
+	tests := []struct {
+		input string
+		want  *RemoteRepo
+	}{
+		{"ghe.example.com/owner/repo", &RemoteRepo{ForgeGitHub, "ghe.example.com", "owner", "repo", ""}},
+		{"https://ghe.example.com/owner/repo/tree/main", &RemoteRepo{ForgeGitHub, "ghe.example.com", "owner", "repo", "main"}},
+		{"git@ghe.example.com:owner/repo.git", &RemoteRepo{ForgeGitHub, "ghe.example.com", "owner", "repo", ""}},
+		{"git.corp.example/owner/repo@v1", &RemoteRepo{ForgeGitHub, "git.corp.example", "owner", "repo", "v1"}},
+		{"github.com/owner/repo", &RemoteRepo{ForgeGitHub, "github.com", "owner", "repo", ""}},
+		{"other.example.com/owner/repo", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := forges.ParseRepoURL(tt.input)
+			switch {
+			case tt.want == nil && got != nil:
+				t.Errorf("expected nil, got %+v", got)
+			case tt.want != nil && (got == nil || *got != *tt.want):
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if r := ParseRepoURL("ghe.example.com/owner/repo"); r != nil {
+		t.Errorf("unconfigured host parsed as %+v", r)
+	}
+	if r := (Forges{GitHubAPIURL: "https://api.octo.ghe.com"}).ParseRepoURL("octo.ghe.com/owner/repo"); r == nil || r.Host != "octo.ghe.com" {
+		t.Errorf("GHE.com host = %+v", r)
+	}
+}
+
+func TestForges_NewForge(t *testing.T) {
+	t.Setenv("GH_ENTERPRISE_TOKEN", "ghe-token")
+	t.Setenv("GITHUB_TOKEN", "dotcom-token")
+	tests := []struct {
+		forges  Forges
+		host    string
+		baseURL string
+		token   string
+	}{
+		{Forges{}, "github.com", "https://api.github.com", "dotcom-token"},
+		{Forges{GitHubAPIURL: "https://ghe.example.com/api/v3"}, "github.com", "https://api.github.com", "dotcom-token"},
+		{Forges{}, "ghe.example.com", "https://ghe.example.com/api/v3", "ghe-token"},
+		{Forges{GitHubAPIURL: "https://ghe.example.com:8443/api/v3/"}, "ghe.example.com", "https://ghe.example.com:8443/api/v3", "ghe-token"},
+		{Forges{}, "octo.ghe.com", "https://api.octo.ghe.com", "ghe-token"},
+		// GitHub Actions sets GITHUB_API_URL to the public API.
+		{Forges{GitHubAPIURL: "https://api.github.com"}, "ghe.example.com", "https://ghe.example.com/api/v3", "ghe-token"},
+	}
+	for _, tt := range tests {
+		f, err := tt.forges.NewForge(&RemoteRepo{Forge: ForgeGitHub, Host: tt.host, Owner: "o", Repo: "r"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := f.(*gitHubClient)
+		if c.baseURL != tt.baseURL || c.token != tt.token {
+			t.Errorf("%+v %s: client %s with %q, want %s with %q", tt.forges, tt.host, c.baseURL, c.token, tt.baseURL, tt.token)
+		}
+	}
+}
+
+func TestRestClient_TokenScope(t *testing.T) {
+	t.Setenv("GITLAB_TOKEN", "glpat-test")
+	t.Setenv("GITEA_TOKEN", "gitea-test")
+	t.Setenv("BITBUCKET_TOKEN", "bb-test")
+	var gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization") + r.Header.Get("PRIVATE-TOKEN")
+		_, _ = w.Write([]byte("data"))
+	}))
+	defer srv.Close()
+	// The same server under another name stands in for a host a release
+	// links to.
+	offHost := strings.Replace(srv.URL, "127.0.0.1", "localhost", 1)
+
+	bb := newBitbucketClient(srv.Client())
+	bb.baseURL = srv.URL + "/2.0"
+	tests := []struct {
+		name  string
+		forge Forge
+		want  string
+	}{
+		{"gitlab", newGitLabClient("127.0.0.1", srv.Client()), "glpat-test"},
+		{"gitea", newGiteaClient("127.0.0.1", srv.Client()), "token gitea-test"},
+		{"bitbucket", bb, "Bearer bb-test"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			own := ReleaseAsset{Name: "checksums.txt", BrowserDownloadURL: srv.URL + "/downloads/checksums.txt"}
+			if _, err := tt.forge.FetchAsset(own, 16); err != nil {
+				t.Fatal(err)
+			}
+			if gotAuth != tt.want {
+				t.Errorf("forge host auth = %q, want %q", gotAuth, tt.want)
+			}
+
+			other := ReleaseAsset{Name: "checksums.txt", BrowserDownloadURL: offHost + "/checksums.txt"}
+			if _, err := tt.forge.FetchAsset(other, 16); err != nil {
+				t.Fatal(err)
+			}
+			if gotAuth != "" {
+				t.Errorf("token sent to an off-host asset URL: %q", gotAuth)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package validator
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// gitDir locates the git directory of the checkout at path. A .git file
+// (worktrees, submodules) points elsewhere with "gitdir: <path>". Returns
+// "" when path is not a checkout.
+func gitDir(path string) (string, error) {
+	dotGit := filepath.Join(path, ".git")
+	info, err := os.Stat(dotGit)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return dotGit, nil
+	}
+
+	data, err := os.ReadFile(dotGit)
+	if err != nil {
+		return "", err
+	}
+	target, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+	if !ok {
+		return "", fmt.Errorf("%s: unrecognized .git file", dotGit)
+	}
+	target = strings.TrimSpace(target)
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(path, target)
+	}
+	return target, nil
+}
+
+// gitConfigPath returns the config file for a git directory. Linked
+// worktrees share the config of the main repository via "commondir".
+func gitConfigPath(dir string) string {
+	if data, err := os.ReadFile(filepath.Join(dir, "commondir")); err == nil {
+		common := strings.TrimSpace(string(data))
+		if !filepath.IsAbs(common) {
+			common = filepath.Join(dir, common)
+		}
+		dir = common
+	}
+	return filepath.Join(dir, "config")
+}
+
+// gitRemoteURL reads the URL of the named remote from the checkout at path
+// without running git. url.<base>.insteadOf rewrites are applied. Returns
+// "" when there is no checkout or no such remote.
+func gitRemoteURL(path, remote string) (string, error) {
+	dir, err := gitDir(path)
+	if err != nil || dir == "" {
+		return "", err
+	}
+	f, err := os.Open(gitConfigPath(dir))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+
+	var section, remoteURL string
+	insteadOf := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = gitSection(line[1 : len(line)-1])
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+		switch {
+		case section == `remote "`+remote+`"` && key == "url" && remoteURL == "":
+			remoteURL = value
+		case strings.HasPrefix(section, `url "`) && key == "insteadof":
+			insteadOf[value] = strings.TrimSuffix(strings.TrimPrefix(section, `url "`), `"`)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	// The longest matching prefix wins, as in git.
+	best := ""
+	for prefix := range insteadOf {
+		if strings.HasPrefix(remoteURL, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	if best != "" {
+		remoteURL = insteadOf[best] + strings.TrimPrefix(remoteURL, best)
+	}
+	return remoteURL, nil
+}
+
+// gitSection normalizes a section header: the section name is
+// case-insensitive, the quoted subsection is not.
+func gitSection(header string) string {
+	name, sub, ok := strings.Cut(strings.TrimSpace(header), " ")
+	name = strings.ToLower(name)
+	if !ok {
+		return name
+	}
+	return name + " " + strings.TrimSpace(sub)
+}
+
+// remoteOrigin returns the forge repo behind the origin remote of the
+// checkout at path, or nil when there is none.
+func remoteOrigin(path string) (*RemoteRepo, error) {
+	return Forges{}.remoteOrigin(path)
+}
+
+func (f Forges) remoteOrigin(path string) (*RemoteRepo, error) {
+	u, err := gitRemoteURL(path, "origin")
+	if err != nil || u == "" {
+		return nil, err
+	}
+	return f.ParseRepoURL(u), nil
+}
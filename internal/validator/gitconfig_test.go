@@ -0,0 +1,175 @@
+package validator
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeGitConfig(t *testing.T, dir, config string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFile(filepath.Join(dir, "config"), []byte(config)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGitRemoteURL(t *testing.T) {
+	const origin = `[core]
+	bare = false
+[remote "upstream"]
+	url = https://github.com/other/fork.git
+[Remote "origin"]
+	url = git@github.com:ppiankov/ancc.git
+	fetch = +refs/heads/*:refs/remotes/origin/*
+`
+	t.Run("checkout", func(t *testing.T) {
+		repo := t.TempDir()
+		writeGitConfig(t, filepath.Join(repo, ".git"), origin)
+
+		got, err := gitRemoteURL(repo, "origin")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != "git@github.com:ppiankov/ancc.git" {
+			t.Errorf("url = %q", got)
+		}
+	})
+
+	t.Run("worktree", func(t *testing.T) {
+		root := t.TempDir()
+		mainGit := filepath.Join(root, "main", ".git")
+		writeGitConfig(t, mainGit, origin)
+		wtGitDir := filepath.Join(mainGit, "worktrees", "wt")
+		if err := os.MkdirAll(wtGitDir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := writeFile(filepath.Join(wtGitDir, "commondir"), []byte("../..\n")); err != nil {
+			t.Fatal(err)
+		}
+		wt := filepath.Join(root, "wt")
+		if err := os.MkdirAll(wt, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := writeFile(filepath.Join(wt, ".git"), []byte("gitdir: "+wtGitDir+"\n")); err != nil {
+			t.Fatal(err)
+		}
+
+		gh, err := remoteOrigin(wt)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if gh == nil || gh.Owner != "ppiankov" || gh.Repo != "ancc" {
+			t.Errorf("origin = %+v, want ppiankov/ancc", gh)
+		}
+	})
+
+	t.Run("insteadOf", func(t *testing.T) {
+		repo := t.TempDir()
+		writeGitConfig(t, filepath.Join(repo, ".git"), `[url "https://github.com/"]
+	insteadOf = gh:
+[remote "origin"]
+	url = gh:foo/bar
+`)
+		got, err := gitRemoteURL(repo, "origin")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != "https://github.com/foo/bar" {
+			t.Errorf("url = %q", got)
+		}
+	})
+
+	t.Run("not a checkout", func(t *testing.T) {
+		gh, err := remoteOrigin(t.TempDir())
+		if err != nil || gh != nil {
+			t.Errorf("got %+v, %v; want nil, nil", gh, err)
+		}
+	})
+}
+
+// TestValidateLocal_MatchesRemote validates the same SKILL.md as a local
+// checkout with a GitHub origin and as a GitHub URL.
+func TestValidateLocal_MatchesRemote(t *testing.T) {
+	content, err := readFile(testdataPath("valid-skill.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The origin is an enterprise host so the local checkout reaches the
+	// test server through the same Forges configuration as a real one.
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/repos/owner/repo/zipball", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(zipball(t, map[string]string{"SKILL.md": string(content)}))
+	})
+	mux.HandleFunc("/api/v3/repos/owner/repo/releases", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]Release{{TagName: "v1.0.0", Assets: []ReleaseAsset{{Name: "mytool-linux-amd64.tar.gz"}}}})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	forges := Forges{GitHubAPIURL: srv.URL + "/api/v3"}
+	client := &gitHubClient{baseURL: srv.URL + "/api/v3", httpClient: srv.Client()}
+
+	repo := t.TempDir()
+	if err := writeFile(filepath.Join(repo, "SKILL.md"), content); err != nil {
+		t.Fatal(err)
+	}
+	writeGitConfig(t, filepath.Join(repo, ".git"), "[remote \"origin\"]\n\turl = https://127.0.0.1/owner/repo.git\n")
+
+	local, err := ValidateWithOptions(repo, Options{Forges: forges})
+	if err != nil {
+		t.Fatalf("local: %v", err)
+	}
+	if local.Release != "v1.0.0" {
+		t.Errorf("local release = %q, want the origin's v1.0.0", local.Release)
+	}
+	remote, err := validateGitHubWithClient(client, "owner", "repo", Options{})
+	if err != nil {
+		t.Fatalf("remote: %v", err)
+	}
+
+	if len(local.Checks) != len(remote.Checks) {
+		t.Fatalf("local has %d checks, remote %d", len(local.Checks), len(remote.Checks))
+	}
+	for i := range local.Checks {
+		l, r := local.Checks[i], remote.Checks[i]
+		if l.Name != r.Name || l.Status != r.Status {
+			t.Errorf("check %d: local %s=%s, remote %s=%s", i, l.Name, l.Status, r.Name, r.Status)
+		}
+	}
+
+	offline, err := ValidateWithOptions(repo, Options{Forges: forges, Offline: true})
+	if err != nil {
+		t.Fatalf("offline: %v", err)
+	}
+	if got := offline.Checks[len(offline.Checks)-1]; got.Name != CheckHasBinaryRelease || got.Status != StatusWarn {
+		t.Errorf("offline binary release = %+v, want a warning from the local config check", got)
+	}
+}
+
+func TestValidateLocal_GitHubUnreachable(t *testing.T) {
+	srv, client := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	defer srv.Close()
+
+	repo := t.TempDir()
+	writeGitConfig(t, filepath.Join(repo, ".git"), "[remote \"origin\"]\n\turl = git@github.com:owner/repo.git\n")
+	if err := writeFile(filepath.Join(repo, ".goreleaser.yml"), []byte("builds:\n  - goos: [linux, darwin]\n    goarch: [amd64, arm64]\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	target, err := LocalTarget(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checks, _ := checkReleases(target, client, Options{})
+	if len(checks) != 1 || checks[0].Status != StatusPass {
+		t.Errorf("got %+v, want only the local config check, passing", checks)
+	}
+}
@@ -0,0 +1,112 @@
+package validator
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+	neturl "net/url"
+)
+
+// giteaClient talks to the Gitea API (v1), which Forgejo and Codeberg
+// serve too.
+type giteaClient struct {
+	restClient
+}
+
+func newGiteaClient(host string, hc *http.Client) *giteaClient {
+	token := forgeToken(ForgeGitea)
+	c := &giteaClient{restClient{
+		baseURL: "https://" + host + "/api/v1",
+		authorize: func(r *http.Request) {
+			if token != "" {
+				r.Header.Set("Authorization", "token "+token)
+			}
+		},
+		tokenHosts: []string{host},
+	}}
+	c.httpClient = trustedRedirects(hc, c.redirectAllowed)
+	return c
+}
+
+// Name implements Forge.
+func (c *giteaClient) Name() string { return ForgeGitea }
+
+func (c *giteaClient) repoURL(owner, repo string) string {
+	return fmt.Sprintf("%s/repos/%s/%s", c.baseURL, neturl.PathEscape(owner), neturl.PathEscape(repo))
+}
+
+// ResolveRef implements Forge. Without a ref the commit list starts at the
+// default branch.
+func (c *giteaClient) ResolveRef(owner, repo, ref string) (string, error) {
+	url := c.repoURL(owner, repo) + "/commits?limit=1&stat=false"
+	if ref != "" {
+		url += "&sha=" + neturl.QueryEscape(ref)
+	}
+	var commits []struct {
+		SHA string `json:"sha"`
+	}
+	if _, err := c.getJSON(url, &commits); err != nil {
+		return "", notFoundRef(ref, err)
+	}
+	if len(commits) == 0 {
+		return "", notFoundRef(ref, errNotFound)
+	}
+	return commits[0].SHA, nil
+}
+
+// FetchArchive implements Forge.
+func (c *giteaClient) FetchArchive(owner, repo, ref string) (fs.FS, error) {
+	if ref == "" {
+		var info struct {
+			DefaultBranch string `json:"default_branch"`
+		}
+		if _, err := c.getJSON(c.repoURL(owner, repo), &info); err != nil {
+			if err == errNotFound {
+				return nil, fmt.Errorf("repository %s/%s not found", owner, repo)
+			}
+			return nil, fmt.Errorf("fetching %s/%s: %w", owner, repo, err)
+		}
+		ref = info.DefaultBranch
+	}
+	return c.fetchZip(c.repoURL(owner, repo)+"/archive/"+neturl.PathEscape(ref)+".zip", owner+"/"+repo)
+}
+
+// EachRelease implements Forge, following the Link header across pages.
+// Gitea releases share GitHub's JSON shape.
+func (c *giteaClient) EachRelease(owner, repo string, fn func(*Release) bool) error {
+	url := c.repoURL(owner, repo) + "/releases?limit=50"
+	for page := 0; url != "" && page < maxReleasePages; page++ {
+		var releases []Release
+		header, err := c.getJSON(url, &releases)
+		if err != nil {
+			return fmt.Errorf("fetching releases: %w", err)
+		}
+		for i := range releases {
+			if fn(&releases[i]) {
+				return nil
+			}
+		}
+		url = nextLink(header.Get("Link"))
+	}
+	if url != "" {
+		return pageLimitError("releases", maxReleasePages)
+	}
+	return nil
+}
+
+// ReleaseByTag implements Forge.
+func (c *giteaClient) ReleaseByTag(owner, repo, tag string) (*Release, error) {
+	var rel Release
+	if _, err := c.getJSON(c.repoURL(owner, repo)+"/releases/tags/"+neturl.PathEscape(tag), &rel); err != nil {
+		if err == errNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("fetching release %s: %w", tag, err)
+	}
+	return &rel, nil
+}
+
+// FetchAsset implements Forge.
+func (c *giteaClient) FetchAsset(a ReleaseAsset, max int64) ([]byte, error) {
+	return c.download(a.BrowserDownloadURL, max)
+}
@@ -0,0 +1,95 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// giteaServer fakes the Gitea API for foo/tool, whose default branch is
+// trunk.
+func giteaServer(t *testing.T, archivePath *string) (*httptest.Server, *giteaClient) {
+	t.Helper()
+	t.Setenv("GITEA_TOKEN", "gt")
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/foo/tool", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `{"default_branch":"trunk"}`)
+	})
+	mux.HandleFunc("/repos/foo/tool/commits", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("sha") {
+		case "", "trunk":
+			_, _ = fmt.Fprint(w, `[{"sha":"abc123"}]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	mux.HandleFunc("/repos/foo/tool/archive/", func(w http.ResponseWriter, r *http.Request) {
+		*archivePath = r.URL.Path
+		_, _ = w.Write(zipball(t, map[string]string{"SKILL.md": "# tool\n"}))
+	})
+	mux.HandleFunc("/repos/foo/tool/releases", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]Release{
+			{TagName: "v2.0.0-rc1", Prerelease: true},
+			{TagName: "v1.0.0", Assets: assets("tool_linux_amd64.tar.gz")},
+		})
+	})
+	mux.HandleFunc("/repos/foo/tool/releases/tags/v1.0.0", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(Release{TagName: "v1.0.0"})
+	})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "token gt" {
+			t.Errorf("Authorization = %q", got)
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	client := newGiteaClient("codeberg.org", nil)
+	client.baseURL = srv.URL
+	client.httpClient = srv.Client()
+	return srv, client
+}
+
+func TestGitea_ResolveRefAndArchive(t *testing.T) {
+	var archivePath string
+	srv, client := giteaServer(t, &archivePath)
+	defer srv.Close()
+
+	if sha, err := client.ResolveRef("foo", "tool", ""); err != nil || sha != "abc123" {
+		t.Errorf("ResolveRef = %q, %v", sha, err)
+	}
+	if _, err := client.ResolveRef("foo", "tool", "nope"); err == nil || !strings.Contains(err.Error(), "no such branch, tag or commit") {
+		t.Errorf("expected unknown ref error, got %v", err)
+	}
+
+	fsys, err := client.FetchArchive("foo", "tool", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if archivePath != "/repos/foo/tool/archive/trunk.zip" {
+		t.Errorf("archive path = %q, want the default branch", archivePath)
+	}
+	if _, err := fs.Stat(fsys, "SKILL.md"); err != nil {
+		t.Errorf("SKILL.md missing: %v", err)
+	}
+}
+
+func TestGitea_SelectRelease(t *testing.T) {
+	var archivePath string
+	srv, client := giteaServer(t, &archivePath)
+	defer srv.Close()
+
+	rel, _, err := selectRelease(client, "foo", "tool", ReleaseLatest)
+	if err != nil || rel == nil || rel.TagName != "v1.0.0" {
+		t.Errorf("latest = %+v, %v; want v1.0.0", rel, err)
+	}
+	rel, _, err = selectRelease(client, "foo", "tool", ReleaseAny)
+	if err != nil || rel == nil || rel.TagName != "v2.0.0-rc1" {
+		t.Errorf("any = %+v, %v; want v2.0.0-rc1", rel, err)
+	}
+	if rel, err := client.ReleaseByTag("foo", "tool", "v9"); err != nil || rel != nil {
+		t.Errorf("missing tag = %v, %v; want nil, nil", rel, err)
+	}
+}
@@ -2,30 +2,47 @@ package validator
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"net/http"
-	"os"
+	neturl "net/url"
 	"regexp"
+	"slices"
 	"strings"
+
+	"github.com/ppiankov/ancc/internal/release"
 )
 
-var reGitHubURL = regexp.MustCompile(`^(?:https?://)?github\.com/([^/]+)/([^/]+?)(?:\.git)?/?$`)
+var reGitHubURL = gitHubURLPattern("github.com")
 
-// GitHubRepo holds parsed owner and repo name.
-type GitHubRepo struct {
-	Owner string
-	Repo  string
+// gitHubURLPattern matches repo URLs on a GitHub host: owner and repo, then
+// a ref as @ref or /tree/<ref>.
+func gitHubURLPattern(host string) *regexp.Regexp {
+	return regexp.MustCompile(`^(?:(?:https?|ssh|git)://)?(?:[^@/]+@)?` + regexp.QuoteMeta(host) +
+		`[/:]([^/]+)/([^/@]+?)(?:\.git)?(?:@(\S+?)|/tree/(\S+?))?/?$`)
 }
 
-// ParseGitHubURL extracts owner/repo from a GitHub URL or shorthand.
+// ParseGitHubURL extracts owner/repo from a GitHub URL or shorthand,
+// including the SSH forms git remotes use (git@github.com:owner/repo.git).
+// A ref follows as owner/repo@ref or /tree/<ref>, as in branch URLs.
 // Returns nil if the input is not a GitHub reference.
-func ParseGitHubURL(input string) *GitHubRepo {
-	m := reGitHubURL.FindStringSubmatch(input)
+func ParseGitHubURL(input string) *RemoteRepo {
+	return parseGitHubHost(reGitHubURL, "github.com", input)
+}
+
+func parseGitHubHost(re *regexp.Regexp, host, input string) *RemoteRepo {
+	m := re.FindStringSubmatch(input)
 	if m == nil {
 		return nil
 	}
-	return &GitHubRepo{Owner: m[1], Repo: m[2]}
+	return &RemoteRepo{Forge: ForgeGitHub, Host: host, Owner: m[1], Repo: m[2], Ref: m[3] + m[4]}
+}
+
+// gitHubRepo returns the RemoteRepo of a github.com repo.
+func gitHubRepo(owner, repo, ref string) *RemoteRepo {
+	return &RemoteRepo{Forge: ForgeGitHub, Host: "github.com", Owner: owner, Repo: repo, Ref: ref}
 }
 
 // gitHubClient handles GitHub API requests.
@@ -33,112 +50,311 @@ type gitHubClient struct {
 	baseURL    string
 	httpClient *http.Client
 	token      string
+	// webHost is the host serving repo pages and browser downloads. The
+	// token is sent only to it, its subdomains and the API host.
+	webHost string
+}
+
+func newGitHubClient(hc *http.Client) *gitHubClient {
+	c := &gitHubClient{
+		baseURL: "https://api.github.com",
+		token:   forgeToken(ForgeGitHub),
+		webHost: "github.com",
+	}
+	c.httpClient = trustedRedirects(hc, c.redirectAllowed)
+	return c
+}
+
+// newGitHubEnterpriseClient returns a client for a GitHub Enterprise host.
+// An empty apiURL means the host's default API location.
+func newGitHubEnterpriseClient(host, apiURL string, hc *http.Client) *gitHubClient {
+	if apiURL == "" {
+		apiURL = enterpriseAPIURL(host)
+	}
+	c := &gitHubClient{
+		baseURL: strings.TrimSuffix(apiURL, "/"),
+		token:   firstEnv(gitHubEnterpriseTokenEnv),
+		webHost: host,
+	}
+	c.httpClient = trustedRedirects(hc, c.redirectAllowed)
+	return c
+}
+
+// gitHubEnterpriseTokenEnv are the token variables for GitHub Enterprise
+// hosts, as the gh CLI reads them.
+var gitHubEnterpriseTokenEnv = []string{"GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN", "GITHUB_TOKEN"}
+
+// enterpriseAPIURL returns the default API location of a GitHub Enterprise
+// host: api.<host> on GHE.com, /api/v3 on GitHub Enterprise Server.
+func enterpriseAPIURL(host string) string {
+	if strings.HasSuffix(host, ".ghe.com") {
+		return "https://api." + host
+	}
+	return "https://" + host + "/api/v3"
+}
+
+// enterpriseHost returns the web host of a GitHub Enterprise API URL.
+func enterpriseHost(apiURL string) string {
+	u, err := neturl.Parse(apiURL)
+	if err != nil || u.Hostname() == "" {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	if h, ok := strings.CutPrefix(host, "api."); ok {
+		return h
+	}
+	return host
 }
 
-func newGitHubClient() *gitHubClient {
-	return &gitHubClient{
-		baseURL:    "https://api.github.com",
-		httpClient: http.DefaultClient,
-		token:      os.Getenv("GITHUB_TOKEN"),
+// authorizes reports whether the token may be sent to url: the API host,
+// the web host or one of its subdomains, such as a GitHub Enterprise
+// Server's raw and codeload hosts.
+func (c *gitHubClient) authorizes(url string) bool {
+	u, err := neturl.Parse(url)
+	if err != nil {
+		return false
 	}
+	host := strings.ToLower(u.Host)
+	if api, err := neturl.Parse(c.baseURL); err == nil && host == strings.ToLower(api.Host) {
+		return true
+	}
+	return c.webHost != "" && (host == c.webHost || strings.HasSuffix(host, "."+c.webHost))
 }
 
+// Name implements Forge.
+func (c *gitHubClient) Name() string { return ForgeGitHub }
+
 func (c *gitHubClient) doRequest(url string) (*http.Response, error) {
+	return c.doRequestAccept(url, "application/vnd.github.v3+json")
+}
+
+func (c *gitHubClient) doRequestAccept(url, accept string) (*http.Response, error) {
 	req, err := http.NewRequest(http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
-	if c.token != "" {
+	req.Header.Set("Accept", accept)
+	if c.token != "" && c.authorizes(url) {
 		req.Header.Set("Authorization", "Bearer "+c.token)
 	}
-	return c.httpClient.Do(req)
+	resp, err := c.httpClient.Do(req)
+	return resp, requestError(err)
 }
 
-// FetchSkillMD fetches SKILL.md content from a GitHub repo.
-func (c *gitHubClient) FetchSkillMD(owner, repo string) (string, error) {
-	url := fmt.Sprintf("%s/repos/%s/%s/contents/SKILL.md", c.baseURL, owner, repo)
+// maxArchiveSize bounds the repository archive held in memory.
+const maxArchiveSize = 256 << 20
+
+// FetchArchive downloads the zip archive of a GitHub repo at ref, or at the
+// default branch when ref is empty, and serves its files from memory.
+func (c *gitHubClient) FetchArchive(owner, repo, ref string) (fs.FS, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/zipball", c.baseURL, owner, repo)
+	if ref != "" {
+		url += "/" + neturl.PathEscape(ref)
+	}
 	resp, err := c.doRequest(url)
 	if err != nil {
-		return "", fmt.Errorf("fetching SKILL.md: %w", err)
+		return nil, fmt.Errorf("fetching archive of %s/%s: %w", owner, repo, err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode == http.StatusNotFound {
-		return "", fmt.Errorf("SKILL.md not found in %s/%s", owner, repo)
+		return nil, fmt.Errorf("repository %s/%s not found", owner, repo)
 	}
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("GitHub API error: %s", resp.Status)
+		return nil, fmt.Errorf("fetching archive of %s/%s: GitHub API error: %s", owner, repo, resp.Status)
 	}
 
-	// GitHub Contents API returns JSON with download_url.
-	var content struct {
-		DownloadURL string `json:"download_url"`
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxArchiveSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading archive of %s/%s: %w", owner, repo, err)
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&content); err != nil {
-		return "", fmt.Errorf("decoding response: %w", err)
+	if len(data) > maxArchiveSize {
+		return nil, fmt.Errorf("archive of %s/%s exceeds %d bytes", owner, repo, maxArchiveSize)
 	}
+	// GitHub wraps the repo in an "owner-repo-<sha>/" directory.
+	return readZip(data)
+}
 
-	if content.DownloadURL == "" {
-		return "", fmt.Errorf("no download URL for SKILL.md")
-	}
+// errRefNotFound reports a ref that names no branch, tag or commit.
+var errRefNotFound = errors.New("no such branch, tag or commit")
 
-	// Fetch the raw file.
-	rawResp, err := c.doRequest(content.DownloadURL)
+// ResolveRef returns the commit SHA that ref points to. An empty ref
+// resolves the default branch.
+func (c *gitHubClient) ResolveRef(owner, repo, ref string) (string, error) {
+	if ref == "" {
+		ref = "HEAD"
+	}
+	url := fmt.Sprintf("%s/repos/%s/%s/commits/%s", c.baseURL, owner, repo, neturl.PathEscape(ref))
+	resp, err := c.doRequestAccept(url, "application/vnd.github.sha")
 	if err != nil {
-		return "", fmt.Errorf("downloading SKILL.md: %w", err)
+		return "", fmt.Errorf("resolving %s: %w", ref, err)
 	}
-	defer func() { _ = rawResp.Body.Close() }()
+	defer func() { _ = resp.Body.Close() }()
 
-	body, err := io.ReadAll(rawResp.Body)
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusUnprocessableEntity:
+		return "", fmt.Errorf("resolving %s: %w", ref, errRefNotFound)
+	default:
+		return "", fmt.Errorf("resolving %s: GitHub API error: %s", ref, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 128))
 	if err != nil {
-		return "", fmt.Errorf("reading SKILL.md: %w", err)
+		return "", fmt.Errorf("resolving %s: %w", ref, err)
 	}
-
-	return string(body), nil
+	sha := strings.TrimSpace(string(body))
+	if !reCommitSHA.MatchString(sha) {
+		return "", fmt.Errorf("resolving %s: unexpected response %q", ref, sha)
+	}
+	return sha, nil
 }
 
-// releaseAsset represents a GitHub release asset.
-type releaseAsset struct {
-	Name string `json:"name"`
+var reCommitSHA = regexp.MustCompile(`^[0-9a-f]{40}([0-9a-f]{24})?$`)
+
+// maxReleasePages bounds release pagination at 1000 releases. Listings
+// that go on fail rather than report a partial list.
+const maxReleasePages = 10
+
+// ListReleases returns every release, newest first, following the Link
+// header across pages.
+func (c *gitHubClient) ListReleases(owner, repo string) ([]Release, error) {
+	var all []Release
+	err := c.EachRelease(owner, repo, func(r *Release) bool {
+		all = append(all, *r)
+		return false
+	})
+	return all, err
 }
 
-// release represents a GitHub release.
-type release struct {
-	TagName string         `json:"tag_name"`
-	Assets  []releaseAsset `json:"assets"`
+// EachRelease calls fn on releases, newest first, until fn returns true or
+// the releases run out. Pages are fetched only as needed.
+func (c *gitHubClient) EachRelease(owner, repo string, fn func(*Release) bool) error {
+	url := fmt.Sprintf("%s/repos/%s/%s/releases?per_page=100", c.baseURL, owner, repo)
+	for page := 0; url != "" && page < maxReleasePages; page++ {
+		releases, next, err := c.releasePage(url)
+		if err != nil {
+			return err
+		}
+		for i := range releases {
+			if fn(&releases[i]) {
+				return nil
+			}
+		}
+		url = next
+	}
+	if url != "" {
+		return pageLimitError("releases", maxReleasePages)
+	}
+	return nil
 }
 
-// HasBinaryRelease checks if the repo has releases with binary assets.
-func (c *gitHubClient) HasBinaryRelease(owner, repo string) (bool, error) {
-	url := fmt.Sprintf("%s/repos/%s/%s/releases?per_page=5", c.baseURL, owner, repo)
+func (c *gitHubClient) releasePage(url string) ([]Release, string, error) {
 	resp, err := c.doRequest(url)
 	if err != nil {
-		return false, fmt.Errorf("fetching releases: %w", err)
+		return nil, "", fmt.Errorf("fetching releases: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusOK {
-		return false, fmt.Errorf("GitHub API error: %s", resp.Status)
+		return nil, "", fmt.Errorf("GitHub API error: %s", resp.Status)
 	}
 
-	var releases []release
+	var releases []Release
 	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
-		return false, fmt.Errorf("decoding releases: %w", err)
+		return nil, "", fmt.Errorf("decoding releases: %w", err)
 	}
+	return releases, nextLink(resp.Header.Get("Link")), nil
+}
 
-	for _, r := range releases {
-		for _, a := range r.Assets {
-			if isBinaryAsset(a.Name) {
-				return true, nil
+// nextLink returns the rel="next" URL of a Link header, or "".
+func nextLink(header string) string {
+	for _, link := range strings.Split(header, ",") {
+		target, params, ok := strings.Cut(link, ";")
+		if !ok {
+			continue
+		}
+		for _, p := range strings.Split(params, ";") {
+			if strings.ReplaceAll(strings.TrimSpace(p), " ", "") == `rel="next"` {
+				return strings.Trim(strings.TrimSpace(target), "<>")
 			}
 		}
 	}
+	return ""
+}
+
+// ReleaseByTag returns the release tagged tag, or nil when there is none.
+func (c *gitHubClient) ReleaseByTag(owner, repo, tag string) (*Release, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/releases/tags/%s", c.baseURL, owner, repo, neturl.PathEscape(tag))
+	resp, err := c.doRequest(url)
+	if err != nil {
+		return nil, fmt.Errorf("fetching release %s: %w", tag, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("GitHub API error: %s", resp.Status)
+	}
+	var rel Release
+	if err := json.NewDecoder(resp.Body).Decode(&rel); err != nil {
+		return nil, fmt.Errorf("decoding release: %w", err)
+	}
+	return &rel, nil
+}
+
+// FetchAsset downloads a release asset, reading at most max bytes. The API
+// URL works for private repos; the browser URL is the fallback.
+func (c *gitHubClient) FetchAsset(a ReleaseAsset, max int64) ([]byte, error) {
+	url, accept := a.URL, "application/octet-stream"
+	if url == "" {
+		url, accept = a.BrowserDownloadURL, "*/*"
+	}
+	if url == "" {
+		return nil, fmt.Errorf("no download URL for %s", a.Name)
+	}
+	resp, err := c.doRequestAccept(url, accept)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("downloading %s: %s", a.Name, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, max+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > max {
+		return nil, fmt.Errorf("%s exceeds %d bytes", a.Name, max)
+	}
+	return data, nil
+}
 
-	return false, nil
+// releaseMatrix parses the assets of a release into the platforms and
+// formats they ship. Assets that name no platform but still look like
+// binaries per isBinaryAsset are counted as unplaced.
+func releaseMatrix(r *Release) (targets []release.Target, formats []string, unplaced int) {
+	for _, a := range r.Assets {
+		parsed, ok := release.ParseAsset(a.Name)
+		if !ok {
+			if !release.IsAuxiliary(a.Name) && isBinaryAsset(a.Name) {
+				unplaced++
+			}
+			continue
+		}
+		targets = append(targets, parsed.Targets...)
+		formats = append(formats, parsed.Format)
+	}
+	slices.Sort(formats)
+	return release.SortTargets(targets), slices.Compact(formats), unplaced
 }
 
-// isBinaryAsset checks if the asset name looks like a binary release.
+// isBinaryAsset checks if the asset name looks like a binary release. It is
+// the fallback for names release.ParseAsset cannot place on a platform.
 func isBinaryAsset(name string) bool {
 	name = strings.ToLower(name)
 	binaryExtensions := []string{".tar.gz", ".zip", ".tgz", ".deb", ".rpm", ".dmg", ".exe"}
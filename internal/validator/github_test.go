@@ -2,9 +2,15 @@ package validator
 
 import (
 	"encoding/json"
+	"fmt"
+	"io/fs"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
+
+	"github.com/ppiankov/ancc/internal/httpclient"
 )
 
 func TestParseGitHubURL(t *testing.T) {
@@ -19,6 +25,9 @@ func TestParseGitHubURL(t *testing.T) {
 		{"http://github.com/foo/bar", "foo", "bar", false},
 		{"https://github.com/foo/bar.git", "foo", "bar", false},
 		{"https://github.com/foo/bar/", "foo", "bar", false},
+		{"git@github.com:foo/bar.git", "foo", "bar", false},
+		{"ssh://git@github.com/foo/bar.git", "foo", "bar", false},
+		{"https://user@github.com/foo/bar", "foo", "bar", false},
 		{"/some/local/path", "", "", true},
 		{".", "", "", true},
 		{"https://gitlab.com/foo/bar", "", "", true},
@@ -46,6 +55,32 @@ func TestParseGitHubURL(t *testing.T) {
 	}
 }
 
+func TestParseGitHubURL_Ref(t *testing.T) {
+	tests := []struct {
+		input string
+		repo  string
+		ref   string
+	}{
+		{"github.com/foo/bar@v1.2.0", "bar", "v1.2.0"},
+		{"https://github.com/foo/bar@feature/x", "bar", "feature/x"},
+		{"https://github.com/foo/bar/tree/main", "bar", "main"},
+		{"https://github.com/foo/bar/tree/fix/issue-12/", "bar", "fix/issue-12"},
+		{"github.com/foo/bar.git@0123abc", "bar", "0123abc"},
+		{"git@github.com:foo/bar.git", "bar", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			gh := ParseGitHubURL(tt.input)
+			if gh == nil {
+				t.Fatal("expected non-nil result")
+			}
+			if gh.Owner != "foo" || gh.Repo != tt.repo || gh.Ref != tt.ref {
+				t.Errorf("got %+v, want foo/%s at %q", gh, tt.repo, tt.ref)
+			}
+		})
+	}
+}
+
 func newTestServer(handler http.HandlerFunc) (*httptest.Server, *gitHubClient) {
 	srv := httptest.NewServer(handler)
 	client := &gitHubClient{
@@ -55,53 +90,49 @@ func newTestServer(handler http.HandlerFunc) (*httptest.Server, *gitHubClient) {
 	return srv, client
 }
 
-func TestFetchSkillMD_Success(t *testing.T) {
+func TestFetchArchive_Success(t *testing.T) {
 	skillContent := "# mytool\n\nA tool.\n"
+	archive := zipball(t, map[string]string{"SKILL.md": skillContent, "cmd/mytool/main.go": "package main\n"})
 
-	var srvURL string
-	mux := http.NewServeMux()
-	mux.HandleFunc("/repos/owner/repo/contents/SKILL.md", func(w http.ResponseWriter, r *http.Request) {
-		resp := map[string]string{
-			"download_url": srvURL + "/raw/SKILL.md",
-		}
-		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(resp)
-	})
-	mux.HandleFunc("/raw/SKILL.md", func(w http.ResponseWriter, r *http.Request) {
-		_, _ = w.Write([]byte(skillContent))
+	var gotPath string
+	srv, client := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		_, _ = w.Write(archive)
 	})
-
-	srv := httptest.NewServer(mux)
 	defer srv.Close()
-	srvURL = srv.URL
 
-	client := &gitHubClient{
-		baseURL:    srv.URL,
-		httpClient: srv.Client(),
-	}
-
-	content, err := client.FetchSkillMD("owner", "repo")
+	fsys, err := client.FetchArchive("owner", "repo", "v1.0.0")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if content != skillContent {
+	if gotPath != "/repos/owner/repo/zipball/v1.0.0" {
+		t.Errorf("path = %q", gotPath)
+	}
+	content, err := fs.ReadFile(fsys, "SKILL.md")
+	if err != nil {
+		t.Fatalf("reading SKILL.md: %v", err)
+	}
+	if string(content) != skillContent {
 		t.Errorf("content = %q, want %q", content, skillContent)
 	}
+	if _, err := fs.Stat(fsys, "cmd/mytool/main.go"); err != nil {
+		t.Errorf("expected nested files: %v", err)
+	}
 }
 
-func TestFetchSkillMD_NotFound(t *testing.T) {
+func TestFetchArchive_NotFound(t *testing.T) {
 	srv, client := newTestServer(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusNotFound)
 	})
 	defer srv.Close()
 
-	_, err := client.FetchSkillMD("owner", "repo")
+	_, err := client.FetchArchive("owner", "repo", "")
 	if err == nil {
 		t.Error("expected error for 404")
 	}
 }
 
-func TestFetchSkillMD_WithToken(t *testing.T) {
+func TestFetchArchive_WithToken(t *testing.T) {
 	var gotAuth string
 	srv, client := newTestServer(func(w http.ResponseWriter, r *http.Request) {
 		gotAuth = r.Header.Get("Authorization")
@@ -110,63 +141,377 @@ func TestFetchSkillMD_WithToken(t *testing.T) {
 	defer srv.Close()
 
 	client.token = "test-token-123"
-	_, _ = client.FetchSkillMD("owner", "repo")
+	_, _ = client.FetchArchive("owner", "repo", "")
 
 	if gotAuth != "Bearer test-token-123" {
 		t.Errorf("auth header = %q, want %q", gotAuth, "Bearer test-token-123")
 	}
 }
 
-func TestHasBinaryRelease_WithAssets(t *testing.T) {
-	srv, client := newTestServer(func(w http.ResponseWriter, r *http.Request) {
-		releases := []release{
-			{
-				TagName: "v1.0.0",
-				Assets: []releaseAsset{
-					{Name: "tool-linux-amd64.tar.gz"},
-					{Name: "tool-darwin-arm64.tar.gz"},
-				},
-			},
-		}
+func releasesServer(releases []Release) (*httptest.Server, *gitHubClient) {
+	return newTestServer(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		_ = json.NewEncoder(w).Encode(releases)
 	})
-	defer srv.Close()
+}
 
-	has, err := client.HasBinaryRelease("owner", "repo")
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
+func assets(names ...string) []ReleaseAsset {
+	out := make([]ReleaseAsset, len(names))
+	for i, n := range names {
+		out[i] = ReleaseAsset{Name: n}
 	}
-	if !has {
-		t.Error("expected true, got false")
+	return out
+}
+
+func TestBinaryReleaseGitHub(t *testing.T) {
+	full := assets(
+		"tool_1.0.0_linux_amd64.tar.gz",
+		"tool_1.0.0_linux_arm64.tar.gz",
+		"tool_1.0.0_darwin_amd64.tar.gz",
+		"tool_1.0.0_darwin_arm64.tar.gz",
+		"checksums.txt",
+	)
+	tests := []struct {
+		name     string
+		releases []Release
+		want     string
+		contains string
+	}{
+		{
+			name:     "full matrix",
+			releases: []Release{{TagName: "v1.0.0", Assets: full}},
+			want:     StatusPass,
+			contains: "v1.0.0: ships darwin/amd64, darwin/arm64, linux/amd64, linux/arm64 as tar.gz",
+		},
+		{
+			name:     "source zip only",
+			releases: []Release{{TagName: "v1.0.0", Assets: assets("tool-1.0.0-source.zip")}},
+			want:     StatusWarn,
+			contains: "no binary release assets",
+		},
+		{
+			name:     "darwin amd64 only",
+			releases: []Release{{TagName: "v1.0.0", Assets: assets("tool-darwin-amd64.zip")}},
+			want:     StatusWarn,
+			contains: "missing darwin/arm64, linux/amd64, linux/arm64",
+		},
+		{
+			name:     "universal macOS and rust triples",
+			releases: []Release{{TagName: "v2", Assets: assets("tool-universal-apple-darwin.tar.gz", "tool-x86_64-unknown-linux-musl.tar.xz", "tool-aarch64-unknown-linux-gnu.tar.xz")}},
+			want:     StatusPass,
+			contains: "as tar.gz, tar.xz",
+		},
+		{
+			name: "prerelease skipped",
+			releases: []Release{
+				{TagName: "v2.0.0-rc1", Prerelease: true, Assets: full},
+				{TagName: "v1.0.0", Assets: assets("tool-linux-amd64.tar.gz")},
+			},
+			want:     StatusWarn,
+			contains: "v1.0.0: missing",
+		},
+		{
+			name:     "unplaced binaries",
+			releases: []Release{{TagName: "v1.0.0", Assets: assets("tool.deb")}},
+			want:     StatusWarn,
+			contains: "none names its platform",
+		},
+		{
+			name:     "no releases",
+			releases: []Release{},
+			want:     StatusWarn,
+			contains: "no releases found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, client := releasesServer(tt.releases)
+			defer srv.Close()
+
+			checks, _, err := releaseChecksRemote(client, "owner", "repo", Options{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			r := checks[0]
+			if r.Status != tt.want || !strings.Contains(r.Message, tt.contains) {
+				t.Errorf("got %s %q, want %s containing %q", r.Status, r.Message, tt.want, tt.contains)
+			}
+		})
 	}
 }
 
-func TestHasBinaryRelease_NoAssets(t *testing.T) {
+func TestListReleases_APIError(t *testing.T) {
 	srv, client := newTestServer(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		_, _ = w.Write([]byte("[]"))
+		w.WriteHeader(http.StatusForbidden)
 	})
 	defer srv.Close()
 
-	has, err := client.HasBinaryRelease("owner", "repo")
+	_, err := client.ListReleases("owner", "repo")
+	if err == nil {
+		t.Error("expected error for 403")
+	}
+}
+
+// pagedServer serves releases two per page, linking pages with a Link
+// header as GitHub does, plus the release-by-tag endpoint, which does not
+// return drafts.
+func pagedServer(t *testing.T, releases []Release) (*httptest.Server, *gitHubClient, *int) {
+	t.Helper()
+	const perPage = 2
+	requests := 0
+	var srv *httptest.Server
+	srv, client := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if strings.HasPrefix(r.URL.Path, "/repos/owner/repo/zipball") {
+			_, _ = w.Write(zipball(t, nil))
+			return
+		}
+		if tag, ok := strings.CutPrefix(r.URL.Path, "/repos/owner/repo/releases/tags/"); ok {
+			for _, rel := range releases {
+				if rel.TagName == tag && !rel.Draft {
+					_ = json.NewEncoder(w).Encode(rel)
+					return
+				}
+			}
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		page = max(page, 1)
+		lo, hi := min((page-1)*perPage, len(releases)), min(page*perPage, len(releases))
+		if hi < len(releases) {
+			next := fmt.Sprintf("%s/repos/owner/repo/releases?per_page=100&page=%d", srv.URL, page+1)
+			w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next", <%s>; rel="last"`, next, next))
+		}
+		_ = json.NewEncoder(w).Encode(releases[lo:hi])
+	})
+	return srv, client, &requests
+}
+
+func TestListReleases_Pagination(t *testing.T) {
+	var releases []Release
+	for i := 0; i < 5; i++ {
+		releases = append(releases, Release{TagName: fmt.Sprintf("v1.%d.0", 4-i)})
+	}
+	srv, client, requests := pagedServer(t, releases)
+	defer srv.Close()
+
+	got, err := client.ListReleases("owner", "repo")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if has {
-		t.Error("expected false, got true")
+	if len(got) != 5 || got[4].TagName != "v1.0.0" {
+		t.Errorf("got %d releases, want all 5 across pages", len(got))
+	}
+	if *requests != 3 {
+		t.Errorf("made %d requests, want 3", *requests)
 	}
 }
 
-func TestHasBinaryRelease_APIError(t *testing.T) {
-	srv, client := newTestServer(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusForbidden)
+func TestListReleases_PageLimit(t *testing.T) {
+	// Two per page, one more than maxReleasePages pages hold.
+	var releases []Release
+	for i := 0; i <= 2*maxReleasePages; i++ {
+		releases = append(releases, Release{TagName: fmt.Sprintf("v0.%d.0", i)})
+	}
+	srv, client, _ := pagedServer(t, releases)
+	defer srv.Close()
+
+	if _, err := client.ListReleases("owner", "repo"); err == nil || !strings.Contains(err.Error(), "limit of 10 pages") {
+		t.Errorf("err = %v, want the page limit", err)
+	}
+	// A tag beyond the limit is not reported as missing.
+	if rel, reason, err := selectRelease(client, "owner", "repo", "v9.9.9"); err == nil {
+		t.Errorf("got %v, %q; want the page limit error", rel, reason)
+	}
+}
+
+func TestNextLink(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{`<https://api.github.com/r?page=2>; rel="next", <https://api.github.com/r?page=9>; rel="last"`, "https://api.github.com/r?page=2"},
+		{`<https://api.github.com/r?page=1>; rel="prev", <https://api.github.com/r?page=1>; rel="first"`, ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := nextLink(tt.header); got != tt.want {
+			t.Errorf("nextLink(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestSelectRelease(t *testing.T) {
+	full := assets("tool-darwin-amd64.tar.gz", "tool-darwin-arm64.tar.gz", "tool-linux-amd64.tar.gz", "tool-linux-arm64.tar.gz")
+	releases := []Release{
+		{TagName: "v3.0.0", Draft: true, Assets: full},
+		{TagName: "v3.0.0-rc2", Prerelease: true, Assets: full},
+		{TagName: "v3.0.0-rc1", Prerelease: true},
+		{TagName: "v2.0.0", Assets: assets("tool-linux-amd64.tar.gz")},
+		{TagName: "v1.0.0", Assets: full},
+	}
+	tests := []struct {
+		selector string
+		wantTag  string
+		contains string
+	}{
+		{"", "v2.0.0", "v2.0.0: missing"},
+		{ReleaseLatest, "v2.0.0", "v2.0.0: missing"},
+		{ReleaseAny, "v3.0.0-rc2", "v3.0.0-rc2 (prerelease): ships"},
+		{"v1.0.0", "v1.0.0", "v1.0.0: ships"},
+		{"v3.0.0", "v3.0.0", "v3.0.0 (draft): ships"},
+		{"v9.9.9", "", "no release tagged v9.9.9"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			srv, client, _ := pagedServer(t, releases)
+			defer srv.Close()
+
+			checks, rel, err := releaseChecksRemote(client, "owner", "repo", Options{Release: tt.selector, Integrity: integrityOff()})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := tagOf(rel); got != tt.wantTag {
+				t.Errorf("selected %q, want %q", got, tt.wantTag)
+			}
+			if !strings.Contains(checks[0].Message, tt.contains) {
+				t.Errorf("message %q does not contain %q", checks[0].Message, tt.contains)
+			}
+		})
+	}
+}
+
+func TestGitHubClient_ReleaseByTagEscapes(t *testing.T) {
+	var gotPath string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.EscapedPath()
+		_, _ = w.Write([]byte(`{"tag_name":"tool/v1.2.0#1"}`))
+	}))
+	defer srv.Close()
+	client := &gitHubClient{baseURL: srv.URL, httpClient: srv.Client()}
+
+	rel, err := client.ReleaseByTag("owner", "repo", "tool/v1.2.0#1")
+	if err != nil || rel == nil {
+		t.Fatalf("ReleaseByTag = %v, %v", rel, err)
+	}
+	if want := "/repos/owner/repo/releases/tags/tool%2Fv1.2.0%231"; gotPath != want {
+		t.Errorf("requested %s, want %s", gotPath, want)
+	}
+}
+
+func TestSelectRelease_OnlyDrafts(t *testing.T) {
+	srv, client, _ := pagedServer(t, []Release{{TagName: "v1.0.0", Draft: true}})
+	defer srv.Close()
+
+	for _, sel := range []string{ReleaseLatest, ReleaseAny} {
+		rel, reason, err := selectRelease(client, "owner", "repo", sel)
+		if err != nil || rel != nil || !strings.Contains(reason, "drafts") {
+			t.Errorf("%s: got %v, %q, %v; want no release and a reason naming drafts", sel, rel, reason, err)
+		}
+	}
+}
+
+func TestValidateGitHub_ReportsRelease(t *testing.T) {
+	srv, client, _ := pagedServer(t, []Release{{TagName: "v0.2.0-beta", Prerelease: true}, {TagName: "v0.1.0"}})
+	defer srv.Close()
+
+	result, err := validateGitHubWithClient(client, "owner", "repo", Options{Integrity: integrityOff()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Release != "v0.1.0" {
+		t.Errorf("release = %q, want v0.1.0", result.Release)
+	}
+}
+
+const testSHA = "0123456789abcdef0123456789abcdef01234567"
+
+// refServer serves a repo whose archive can be read at any resolvable ref
+// and whose releases are v2.0.0 and v1.0.0. It records the ref the archive
+// was downloaded at.
+func refServer(t *testing.T, readAt *string) (*httptest.Server, *gitHubClient) {
+	t.Helper()
+	content, err := readFile(testdataPath("valid-skill.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v1 := Release{TagName: "v1.0.0", Assets: assets("tool-linux-amd64.tar.gz")}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/repo/commits/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != "application/vnd.github.sha" {
+			t.Errorf("Accept = %q", r.Header.Get("Accept"))
+		}
+		switch strings.TrimPrefix(r.URL.Path, "/repos/owner/repo/commits/") {
+		case "HEAD", "v1.0.0", "feature/x":
+			_, _ = w.Write([]byte(testSHA))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
 	})
+	mux.HandleFunc("/repos/owner/repo/zipball/", func(w http.ResponseWriter, r *http.Request) {
+		*readAt = strings.TrimPrefix(r.URL.Path, "/repos/owner/repo/zipball/")
+		_, _ = w.Write(zipball(t, map[string]string{"SKILL.md": string(content)}))
+	})
+	mux.HandleFunc("/repos/owner/repo/releases/tags/v1.0.0", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(v1)
+	})
+	mux.HandleFunc("/repos/owner/repo/releases", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]Release{{TagName: "v2.0.0", Assets: assets("tool-linux-arm64.tar.gz")}, v1})
+	})
+	srv := httptest.NewServer(mux)
+	return srv, &gitHubClient{baseURL: srv.URL, httpClient: srv.Client()}
+}
+
+func TestValidateGitHub_Ref(t *testing.T) {
+	tests := []struct {
+		ref         string
+		wantRelease string
+	}{
+		{"", "v2.0.0"},
+		{"v1.0.0", "v1.0.0"},
+		{"feature/x", "v2.0.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			var readAt string
+			srv, client := refServer(t, &readAt)
+			defer srv.Close()
+
+			result, err := validateGitHubWithClient(client, "owner", "repo", Options{Ref: tt.ref, Integrity: integrityOff()})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Commit != testSHA || result.Ref != tt.ref {
+				t.Errorf("ref %q commit %q, want %q at %s", result.Ref, result.Commit, tt.ref, testSHA)
+			}
+			if readAt != testSHA {
+				t.Errorf("archive read at %q, want the resolved commit", readAt)
+			}
+			if result.Release != tt.wantRelease {
+				t.Errorf("release = %q, want %q", result.Release, tt.wantRelease)
+			}
+		})
+	}
+}
+
+func TestValidateGitHub_UnknownRef(t *testing.T) {
+	var readAt string
+	srv, client := refServer(t, &readAt)
 	defer srv.Close()
 
-	_, err := client.HasBinaryRelease("owner", "repo")
-	if err == nil {
-		t.Error("expected error for 403")
+	_, err := validateGitHubWithClient(client, "owner", "repo", Options{Ref: "nope"})
+	if err == nil || !strings.Contains(err.Error(), "no such branch, tag or commit") {
+		t.Errorf("expected unknown ref error, got %v", err)
+	}
+}
+
+func TestValidateWithOptions_RefErrors(t *testing.T) {
+	if _, err := ValidateWithOptions("github.com/owner/repo@v1", Options{Ref: "v2"}); err == nil || !strings.Contains(err.Error(), "conflicts") {
+		t.Errorf("expected conflicting ref error, got %v", err)
+	}
+	if _, err := ValidateWithOptions(t.TempDir(), Options{Ref: "main", Offline: true}); err == nil || !strings.Contains(err.Error(), "only to remote repos") {
+		t.Errorf("expected local ref error, got %v", err)
 	}
 }
 
@@ -239,29 +584,26 @@ mytool run --format json | jq '.'
 ` + "```" + `
 `
 
-	var srvURL string
 	mux := http.NewServeMux()
-	mux.HandleFunc("/repos/owner/repo/contents/SKILL.md", func(w http.ResponseWriter, r *http.Request) {
-		resp := map[string]string{"download_url": srvURL + "/raw/SKILL.md"}
-		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(resp)
-	})
-	mux.HandleFunc("/raw/SKILL.md", func(w http.ResponseWriter, r *http.Request) {
-		_, _ = w.Write([]byte(skillContent))
+	mux.HandleFunc("/repos/owner/repo/zipball", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(zipball(t, map[string]string{"SKILL.md": skillContent}))
 	})
 	mux.HandleFunc("/repos/owner/repo/releases", func(w http.ResponseWriter, r *http.Request) {
-		releases := []release{{TagName: "v1.0.0", Assets: []releaseAsset{{Name: "mytool-linux-amd64.tar.gz"}}}}
+		releases := []Release{{TagName: "v1.0.0", Assets: assets(
+			"mytool-linux-amd64.tar.gz", "mytool-linux-arm64.tar.gz",
+			"mytool-darwin-amd64.tar.gz", "mytool-darwin-arm64.tar.gz",
+		)}}
 		w.Header().Set("Content-Type", "application/json")
 		_ = json.NewEncoder(w).Encode(releases)
 	})
 
 	srv := httptest.NewServer(mux)
 	defer srv.Close()
-	srvURL = srv.URL
 
 	client := &gitHubClient{baseURL: srv.URL, httpClient: srv.Client()}
 
-	result, err := validateGitHubWithClient(client, "owner", "repo")
+	// Integrity checks are covered in integrity_test.go.
+	result, err := validateGitHubWithClient(client, "owner", "repo", Options{Integrity: integrityOff()})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -279,16 +621,19 @@ mytool run --format json | jq '.'
 
 func TestValidateGitHubWithClient_NoSkillMD(t *testing.T) {
 	srv, client := newTestServer(func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path == "/repos/owner/repo/releases" {
+		switch r.URL.Path {
+		case "/repos/owner/repo/releases":
 			w.Header().Set("Content-Type", "application/json")
 			_, _ = w.Write([]byte("[]"))
-			return
+		case "/repos/owner/repo/zipball":
+			_, _ = w.Write(zipball(t, map[string]string{"README.md": "# repo\n"}))
+		default:
+			w.WriteHeader(http.StatusNotFound)
 		}
-		w.WriteHeader(http.StatusNotFound)
 	})
 	defer srv.Close()
 
-	result, err := validateGitHubWithClient(client, "owner", "repo")
+	result, err := validateGitHubWithClient(client, "owner", "repo", Options{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -296,7 +641,145 @@ func TestValidateGitHubWithClient_NoSkillMD(t *testing.T) {
 	if result.Status != OverallFail {
 		t.Errorf("status = %q, want %q", result.Status, OverallFail)
 	}
-	if result.Summary.Total != 11 {
-		t.Errorf("total = %d, want 11", result.Summary.Total)
+	// 11 standard checks plus the 4 integrity checks, which have no release to inspect.
+	if result.Summary.Total != 15 {
+		t.Errorf("total = %d, want 15", result.Summary.Total)
+	}
+}
+
+// TestValidateGitHub_RepoWide checks that remote validation reads the whole
+// repo: drift analysis runs on its source and, with the releases API down,
+// the release config in the archive decides the binary release check.
+func TestValidateGitHub_RepoWide(t *testing.T) {
+	content, err := readFile(testdataPath("valid-skill.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"SKILL.md":         string(content),
+		"pyproject.toml":   "[project]\nname = \"mytool\"\n",
+		"mytool/cli.py":    "import argparse\nparser = argparse.ArgumentParser()\nsub = parser.add_subparsers()\nsub.add_parser(\"init\")\n",
+		".goreleaser.yaml": "builds:\n  - goos: [linux, darwin]\n    goarch: [amd64, arm64]\n",
+	}
+	srv, client := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/owner/repo/zipball" {
+			_, _ = w.Write(zipball(t, files))
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	defer srv.Close()
+
+	result, err := validateGitHubWithClient(client, "owner", "repo", Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := make(map[string]CheckResult)
+	for _, c := range result.Checks {
+		got[c.Name] = c
+	}
+	if _, ok := got[CheckCommandDrift]; !ok {
+		t.Error("expected the drift check to run on the repo source")
+	}
+	if r := got[CheckHasBinaryRelease]; r.Status != StatusPass || !strings.Contains(r.Message, "goreleaser") {
+		t.Errorf("binary release = %+v, want a pass from .goreleaser.yaml", r)
+	}
+}
+
+func TestGitHubClient_TokenScope(t *testing.T) {
+	var gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte("data"))
+	}))
+	defer srv.Close()
+	client := newGitHubEnterpriseClient("127.0.0.1", srv.URL+"/api/v3", srv.Client())
+	client.token = "ghe-token"
+
+	// Raw download URLs on the enterprise host carry the token.
+	raw := ReleaseAsset{Name: "checksums.txt", BrowserDownloadURL: srv.URL + "/owner/repo/releases/download/v1/checksums.txt"}
+	if _, err := client.FetchAsset(raw, 16); err != nil {
+		t.Fatal(err)
+	}
+	if gotAuth != "Bearer ghe-token" {
+		t.Errorf("enterprise host auth = %q", gotAuth)
+	}
+
+	// Other hosts, such as asset storage, do not.
+	other := ReleaseAsset{Name: "checksums.txt", BrowserDownloadURL: strings.Replace(srv.URL, "127.0.0.1", "localhost", 1) + "/checksums.txt"}
+	if _, err := client.FetchAsset(other, 16); err != nil {
+		t.Fatal(err)
+	}
+	if gotAuth != "" {
+		t.Errorf("token sent to another host: %q", gotAuth)
+	}
+}
+
+func TestValidateWithOptions_GitHubEnterprise(t *testing.T) {
+	t.Setenv("GH_ENTERPRISE_TOKEN", "ghe-token")
+	var readAt string
+	api, _ := refServer(t, &readAt)
+	defer api.Close()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer ghe-token" {
+			t.Errorf("%s: auth = %q", r.URL.Path, got)
+		}
+		path, ok := strings.CutPrefix(r.URL.Path, "/api/v3")
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		r.URL.Path = path
+		api.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	opts := Options{Integrity: integrityOff(), Forges: Forges{GitHubAPIURL: srv.URL + "/api/v3"}}
+	result, err := ValidateWithOptions("127.0.0.1/owner/repo@v1.0.0", opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Path != "127.0.0.1/owner/repo" || result.Commit != testSHA || result.Release != "v1.0.0" {
+		t.Errorf("path %q, commit %q, release %q", result.Path, result.Commit, result.Release)
+	}
+}
+
+func TestListReleases_RateLimited(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", "4102444800") // 2100-01-01
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+	client := &gitHubClient{baseURL: srv.URL, httpClient: httpclient.MustNew(httpclient.Options{})}
+
+	_, err := client.ListReleases("owner", "repo")
+	if err == nil || !strings.Contains(err.Error(), "fetching releases: "+strings.TrimPrefix(srv.URL, "http://")+" rate limit exceeded; resets at 2100-01-01T00:00:00Z") {
+		t.Errorf("expected rate limit error with reset time, got %v", err)
+	}
+}
+
+func TestValidateWithOptions_Replay(t *testing.T) {
+	client, err := httpclient.New(httpclient.Options{ReplayDir: testdataPath("cassettes/github-tool")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := ValidateWithOptions("github.com/owner/tool", Options{Forges: Forges{HTTPClient: client}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Commit != "3f786850e387550fdab836ed7e6dc881de23001b" || result.Release != "v1.2.0" {
+		t.Errorf("commit %q, release %q", result.Commit, result.Release)
+	}
+	want := map[string]string{
+		CheckSkillMDExists:     StatusPass,
+		CheckHasBinaryRelease:  StatusPass,
+		CheckReleaseChecksums:  StatusPass,
+		CheckReleaseSignatures: StatusWarn,
+	}
+	for _, c := range result.Checks {
+		if w, ok := want[c.Name]; ok && c.Status != w {
+			t.Errorf("%s = %s %q, want %s", c.Name, c.Status, c.Message, w)
+		}
 	}
 }
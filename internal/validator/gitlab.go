@@ -0,0 +1,130 @@
+package validator
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+	neturl "net/url"
+)
+
+// gitLabClient talks to the GitLab REST API (v4) of gitlab.com or a
+// self-hosted instance.
+type gitLabClient struct {
+	restClient
+}
+
+func newGitLabClient(host string, hc *http.Client) *gitLabClient {
+	token := forgeToken(ForgeGitLab)
+	c := &gitLabClient{restClient{
+		baseURL: "https://" + host + "/api/v4",
+		authorize: func(r *http.Request) {
+			if token != "" {
+				r.Header.Set("PRIVATE-TOKEN", token)
+			}
+		},
+		tokenHosts: []string{host},
+	}}
+	c.httpClient = trustedRedirects(hc, c.redirectAllowed)
+	return c
+}
+
+// Name implements Forge.
+func (c *gitLabClient) Name() string { return ForgeGitLab }
+
+// project returns the API path of a project, addressed by its URL-encoded
+// full path.
+func (c *gitLabClient) project(owner, repo string) string {
+	return c.baseURL + "/projects/" + neturl.PathEscape(owner+"/"+repo)
+}
+
+// ResolveRef implements Forge. GitLab resolves HEAD to the default branch.
+func (c *gitLabClient) ResolveRef(owner, repo, ref string) (string, error) {
+	if ref == "" {
+		ref = "HEAD"
+	}
+	var commit struct {
+		ID string `json:"id"`
+	}
+	if _, err := c.getJSON(c.project(owner, repo)+"/repository/commits/"+neturl.PathEscape(ref), &commit); err != nil {
+		return "", notFoundRef(ref, err)
+	}
+	return commit.ID, nil
+}
+
+// FetchArchive implements Forge.
+func (c *gitLabClient) FetchArchive(owner, repo, ref string) (fs.FS, error) {
+	url := c.project(owner, repo) + "/repository/archive.zip"
+	if ref != "" {
+		url += "?sha=" + neturl.QueryEscape(ref)
+	}
+	return c.fetchZip(url, owner+"/"+repo)
+}
+
+// gitLabRelease is a GitLab release. Assets are links; the generated
+// source archives are not release assets.
+type gitLabRelease struct {
+	TagName         string `json:"tag_name"`
+	UpcomingRelease bool   `json:"upcoming_release"`
+	Assets          struct {
+		Links []struct {
+			Name           string `json:"name"`
+			URL            string `json:"url"`
+			DirectAssetURL string `json:"direct_asset_url"`
+		} `json:"links"`
+	} `json:"assets"`
+}
+
+// release converts to a Release. GitLab has no drafts or prereleases;
+// a release scheduled for the future counts as a prerelease.
+func (r gitLabRelease) release() Release {
+	out := Release{TagName: r.TagName, Prerelease: r.UpcomingRelease}
+	for _, l := range r.Assets.Links {
+		url := l.DirectAssetURL
+		if url == "" {
+			url = l.URL
+		}
+		out.Assets = append(out.Assets, ReleaseAsset{Name: l.Name, BrowserDownloadURL: url})
+	}
+	return out
+}
+
+// EachRelease implements Forge, following the Link header across pages.
+func (c *gitLabClient) EachRelease(owner, repo string, fn func(*Release) bool) error {
+	url := c.project(owner, repo) + "/releases?per_page=100"
+	for page := 0; url != "" && page < maxReleasePages; page++ {
+		var releases []gitLabRelease
+		header, err := c.getJSON(url, &releases)
+		if err != nil {
+			return fmt.Errorf("fetching releases: %w", err)
+		}
+		for _, r := range releases {
+			rel := r.release()
+			if fn(&rel) {
+				return nil
+			}
+		}
+		url = nextLink(header.Get("Link"))
+	}
+	if url != "" {
+		return pageLimitError("releases", maxReleasePages)
+	}
+	return nil
+}
+
+// ReleaseByTag implements Forge.
+func (c *gitLabClient) ReleaseByTag(owner, repo, tag string) (*Release, error) {
+	var r gitLabRelease
+	if _, err := c.getJSON(c.project(owner, repo)+"/releases/"+neturl.PathEscape(tag), &r); err != nil {
+		if err == errNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("fetching release %s: %w", tag, err)
+	}
+	rel := r.release()
+	return &rel, nil
+}
+
+// FetchAsset implements Forge.
+func (c *gitLabClient) FetchAsset(a ReleaseAsset, max int64) ([]byte, error) {
+	return c.download(a.BrowserDownloadURL, max)
+}
@@ -0,0 +1,145 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// gitLabServer fakes the GitLab API for the project group/sub/tool, which
+// has two releases served one per page.
+func gitLabServer(t *testing.T, skill string) (*httptest.Server, *gitLabClient) {
+	t.Helper()
+	t.Setenv("GITLAB_TOKEN", "glpat-test")
+	const project = "/projects/group%2Fsub%2Ftool"
+	releases := []string{
+		`{"tag_name":"v1.1.0","upcoming_release":true,"assets":{"links":[]}}`,
+		`{"tag_name":"v1.0.0","assets":{"links":[` +
+			`{"name":"tool_1.0.0_linux_amd64.tar.gz","url":"https://x/l1","direct_asset_url":"https://x/d1"},` +
+			`{"name":"tool_1.0.0_darwin_arm64.tar.gz","url":"https://x/l2"}]}}`,
+	}
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("PRIVATE-TOKEN"); got != "glpat-test" {
+			t.Errorf("PRIVATE-TOKEN = %q", got)
+		}
+		path, ok := strings.CutPrefix(r.URL.EscapedPath(), project)
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch path {
+		case "/repository/commits/HEAD", "/repository/commits/main":
+			_, _ = fmt.Fprint(w, `{"id":"0123456789abcdef0123456789abcdef01234567"}`)
+		case "/repository/archive.zip":
+			if got := r.URL.Query().Get("sha"); got != "0123456789abcdef0123456789abcdef01234567" {
+				t.Errorf("archive sha = %q", got)
+			}
+			_, _ = w.Write(zipball(t, map[string]string{"SKILL.md": skill}))
+		case "/releases":
+			page := 0
+			if r.URL.Query().Get("page") == "2" {
+				page = 1
+			} else {
+				w.Header().Set("Link", fmt.Sprintf(`<%s/projects/group%%2Fsub%%2Ftool/releases?page=2>; rel="next"`, srv.URL))
+			}
+			_, _ = fmt.Fprintf(w, "[%s]", releases[page])
+		case "/releases/v1.0.0":
+			_, _ = fmt.Fprint(w, releases[1])
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	client := newGitLabClient("gitlab.example.com", nil)
+	client.baseURL = srv.URL
+	client.httpClient = srv.Client()
+	return srv, client
+}
+
+func TestGitLab_ResolveRefAndArchive(t *testing.T) {
+	srv, client := gitLabServer(t, "# tool\n")
+	defer srv.Close()
+
+	sha, err := client.ResolveRef("group/sub", "tool", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fsys, err := client.FetchArchive("group/sub", "tool", sha)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data, err := fs.ReadFile(fsys, "SKILL.md"); err != nil || string(data) != "# tool\n" {
+		t.Errorf("SKILL.md = %q, %v", data, err)
+	}
+
+	if _, err := client.ResolveRef("group/sub", "tool", "nope"); err == nil || !strings.Contains(err.Error(), "no such branch, tag or commit") {
+		t.Errorf("expected unknown ref error, got %v", err)
+	}
+}
+
+func TestGitLab_Releases(t *testing.T) {
+	srv, client := gitLabServer(t, "")
+	defer srv.Close()
+
+	var tags []string
+	err := client.EachRelease("group/sub", "tool", func(r *Release) bool {
+		tags = append(tags, fmt.Sprintf("%s:%v:%d", r.TagName, r.Prerelease, len(r.Assets)))
+		return false
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.Join(tags, " "); got != "v1.1.0:true:0 v1.0.0:false:2" {
+		t.Errorf("releases = %s", got)
+	}
+
+	rel, err := client.ReleaseByTag("group/sub", "tool", "v1.0.0")
+	if err != nil || rel == nil {
+		t.Fatalf("ReleaseByTag = %v, %v", rel, err)
+	}
+	if got := rel.Assets[0].BrowserDownloadURL; got != "https://x/d1" {
+		t.Errorf("direct asset URL = %q", got)
+	}
+	if got := rel.Assets[1].BrowserDownloadURL; got != "https://x/l2" {
+		t.Errorf("link URL = %q", got)
+	}
+	if rel, err := client.ReleaseByTag("group/sub", "tool", "v9"); err != nil || rel != nil {
+		t.Errorf("missing tag = %v, %v; want nil, nil", rel, err)
+	}
+}
+
+func TestValidateRemote_GitLab(t *testing.T) {
+	content, err := readFile("../../SKILL.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv, client := gitLabServer(t, string(content))
+	defer srv.Close()
+
+	r := ParseRepoURL("https://gitlab.example.com/group/sub/tool")
+	result, err := validateRemote(client, r, Options{Integrity: integrityOff()})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Path != "gitlab.example.com/group/sub/tool" {
+		t.Errorf("path = %q", result.Path)
+	}
+	if result.Release != "v1.0.0" {
+		t.Errorf("release = %q, want the latest non-upcoming release", result.Release)
+	}
+	for _, c := range result.Checks {
+		if c.Name == CheckHasBinaryRelease && !strings.Contains(c.Message, "missing") {
+			t.Errorf("binary release = %s %q, want missing platforms", c.Status, c.Message)
+		}
+	}
+
+	out, _ := json.Marshal(result)
+	if !strings.Contains(string(out), `"commit":"0123456789abcdef0123456789abcdef01234567"`) {
+		t.Errorf("commit not reported: %s", out)
+	}
+}
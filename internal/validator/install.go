@@ -0,0 +1,240 @@
+package validator
+
+import (
+	"fmt"
+	"io/fs"
+	"regexp"
+	"strings"
+
+	"github.com/ppiankov/ancc/internal/release"
+	"github.com/ppiankov/ancc/internal/skillmd"
+)
+
+// CheckInstallAssets verifies the release download URLs in the Install
+// section. It runs only when the section contains such URLs.
+const CheckInstallAssets = "install-assets"
+
+// versionMarker stands in for the release version when rendering local
+// GoReleaser name templates.
+const versionMarker = "@VERSION@"
+
+var (
+	// reDownloadURL matches GitHub release download URLs. Command
+	// substitutions are rewritten to ${_} first so their parentheses and
+	// spaces do not end the match.
+	reDownloadURL = regexp.MustCompile(`https?://github\.com/([A-Za-z0-9_.-]+)/([A-Za-z0-9_.-]+)/releases/(?:latest/download|download/([^/\s"'<>]+))/([^\s"'<>|;&)` + "`" + `]+)`)
+	reCommandSub  = regexp.MustCompile(`\$\([^)]*\)`)
+	// rePlaceholder matches shell variables and {{...}} template fields.
+	rePlaceholder = regexp.MustCompile(`\$\{[^}]*\}|\$[A-Za-z_][A-Za-z0-9_]*|\{\{[^}]*\}\}`)
+	reVersion     = regexp.MustCompile(`\d+\.\d+\.\d+`)
+)
+
+// downloadPath is a release download URL from the Install section.
+type downloadPath struct {
+	URL   string
+	Owner string
+	Repo  string
+	Tag   string // empty for latest/download
+	Asset string
+}
+
+// templatedTag reports whether the tag is filled in when the command runs,
+// so the release it names is not known.
+func (d downloadPath) templatedTag() bool {
+	return d.Tag == "latest" || rePlaceholder.MatchString(d.Tag)
+}
+
+// sharesPlaceholder reports whether the tag and the asset name use the same
+// placeholder, which then stands for the version in the asset name.
+func (d downloadPath) sharesPlaceholder() bool {
+	tag := make(map[string]bool)
+	for _, p := range rePlaceholder.FindAllString(d.Tag, -1) {
+		tag[placeholderName(p)] = true
+	}
+	for _, p := range rePlaceholder.FindAllString(d.Asset, -1) {
+		if tag[placeholderName(p)] {
+			return true
+		}
+	}
+	return false
+}
+
+// placeholderName reduces $V, ${V}, ${V#v} and {{ V }} to V.
+func placeholderName(p string) string {
+	p = strings.Trim(p, "${} ")
+	name, _, _ := strings.Cut(p, "#")
+	return strings.ToLower(name)
+}
+
+// version returns the version an asset name is rendered with: the tag's,
+// or, when the tag is templated without naming the version in the asset,
+// the version written in the asset name.
+func (d downloadPath) version() string {
+	if !d.templatedTag() {
+		return strings.TrimPrefix(d.Tag, "v")
+	}
+	if d.sharesPlaceholder() {
+		return ""
+	}
+	return reVersion.FindString(d.Asset)
+}
+
+// assetPattern matches asset names the URL can resolve to. Placeholders
+// match any non-empty text. Literal occurrences of version are replaced by
+// versionMarker when version is set.
+func (d downloadPath) assetPattern(version string) *regexp.Regexp {
+	asset := d.Asset
+	if version != "" {
+		asset = strings.ReplaceAll(asset, version, versionMarker)
+	}
+	var b strings.Builder
+	b.WriteString("^")
+	last := 0
+	for _, loc := range rePlaceholder.FindAllStringIndex(asset, -1) {
+		b.WriteString(regexp.QuoteMeta(asset[last:loc[0]]))
+		b.WriteString(".+")
+		last = loc[1]
+	}
+	b.WriteString(regexp.QuoteMeta(asset[last:]))
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
+
+// installDownloads extracts release download URLs from the Install
+// section, in order and without duplicates.
+func installDownloads(sf *skillmd.SkillFile) []downloadPath {
+	sec := sf.Sections[skillmd.SectionInstall]
+	if sec == nil {
+		return nil
+	}
+	text := reCommandSub.ReplaceAllLiteralString(sec.Content, "${_}")
+	seen := make(map[string]bool)
+	var out []downloadPath
+	for _, m := range reDownloadURL.FindAllStringSubmatch(text, -1) {
+		url := strings.TrimRight(m[0], ".,")
+		if seen[url] {
+			continue
+		}
+		seen[url] = true
+		out = append(out, downloadPath{
+			URL:   url,
+			Owner: m[1],
+			Repo:  m[2],
+			Tag:   m[3],
+			Asset: strings.TrimRight(m[4], ".,"),
+		})
+	}
+	return out
+}
+
+// checkInstallAssets verifies the download paths of the Install section:
+// against GitHub when a github.com client is available, otherwise or when
+// GitHub cannot be reached against the target's GoReleaser config. It
+// reports false when the Install section has none.
+func checkInstallAssets(t Target, client Forge, sf *skillmd.SkillFile) (CheckResult, bool) {
+	paths := installDownloads(sf)
+	if len(paths) == 0 {
+		return CheckResult{}, false
+	}
+	// The download URLs recognized are github.com's, so a GitHub Enterprise
+	// client cannot resolve them.
+	line := sectionLine(sf, skillmd.SectionInstall)
+	if gh, ok := client.(*gitHubClient); ok && gh.webHost == "github.com" {
+		if c, err := checkInstallAssetsGitHub(client, paths); err == nil {
+			return at(c, line), true
+		}
+	}
+	meta := t.Meta()
+	return at(checkInstallAssetsLocal(t.FS(), meta.Name, meta.Origin, paths), line), true
+}
+
+// checkInstallAssetsGitHub resolves each download path against the
+// published releases of the repo it names. latest/download and templated
+// tags resolve to the latest stable release.
+func checkInstallAssetsGitHub(client Forge, paths []downloadPath) (CheckResult, error) {
+	latest := make(map[string]*Release)
+	var broken []string
+	for _, p := range paths {
+		repo := p.Owner + "/" + p.Repo
+		if p.Tag != "" && !p.templatedTag() {
+			rel, err := client.ReleaseByTag(p.Owner, p.Repo, p.Tag)
+			if err != nil {
+				return CheckResult{}, err
+			}
+			switch {
+			case rel == nil:
+				broken = append(broken, fmt.Sprintf("%s: no release tagged %s", p.URL, p.Tag))
+			case !hasAsset(rel, p.assetPattern("")):
+				broken = append(broken, fmt.Sprintf("%s: %s has no asset %s", p.URL, p.Tag, p.Asset))
+			}
+			continue
+		}
+
+		rel, ok := latest[repo]
+		if !ok {
+			var err error
+			if rel, _, err = selectRelease(client, p.Owner, p.Repo, ReleaseLatest); err != nil {
+				return CheckResult{}, err
+			}
+			latest[repo] = rel
+		}
+		switch {
+		case rel == nil:
+			broken = append(broken, fmt.Sprintf("%s: %s has no published release", p.URL, repo))
+		case !hasAsset(rel, p.assetPattern("")):
+			broken = append(broken, fmt.Sprintf("%s: no matching asset in %s", p.URL, rel.TagName))
+		}
+	}
+	return installResult(len(paths), broken, "release assets"), nil
+}
+
+func hasAsset(rel *Release, pattern *regexp.Regexp) bool {
+	for _, a := range rel.Assets {
+		if pattern.MatchString(a.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkInstallAssetsLocal matches download paths against the asset names
+// the working tree's GoReleaser config renders. Paths into other repos
+// than origin, when origin is known, are left unchecked.
+func checkInstallAssetsLocal(fsys fs.FS, project string, origin *RemoteRepo, paths []downloadPath) CheckResult {
+	names, ok, err := release.ArchiveNames(fsys, project, versionMarker)
+	if err != nil {
+		return warn(CheckInstallAssets, fmt.Sprintf("could not render asset names: %v", err))
+	}
+	if !ok {
+		return warn(CheckInstallAssets, fmt.Sprintf("cannot verify %d download path(s) offline: no GoReleaser config", len(paths)))
+	}
+
+	var broken []string
+	checked := 0
+	for _, p := range paths {
+		if origin != nil && !strings.EqualFold(origin.Owner+"/"+origin.Repo, p.Owner+"/"+p.Repo) {
+			continue
+		}
+		checked++
+		pattern := p.assetPattern(p.version())
+		found := false
+		for _, n := range names {
+			found = found || pattern.MatchString(n)
+		}
+		if !found {
+			broken = append(broken, fmt.Sprintf("%s: %s matches no archive name_template", p.URL, p.Asset))
+		}
+	}
+	if checked == 0 {
+		return warn(CheckInstallAssets, "cannot verify download paths into other repos offline")
+	}
+	return installResult(checked, broken, "GoReleaser asset names")
+}
+
+func installResult(total int, broken []string, source string) CheckResult {
+	if len(broken) > 0 {
+		return fail(CheckInstallAssets, fmt.Sprintf("%d of %d download path(s) broken: %s",
+			len(broken), total, strings.Join(broken, "; ")))
+	}
+	return pass(CheckInstallAssets, fmt.Sprintf("%d download path(s) match %s", total, source))
+}
@@ -0,0 +1,190 @@
+package validator
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/ppiankov/ancc/internal/skillmd"
+)
+
+const installSection = "# tool\n\n## Install\n\n```\n" +
+	"curl -L https://github.com/owner/repo/releases/download/v1.0.0/tool-1.0.0-linux-amd64.tar.gz | tar xz\n" +
+	"curl -LO \"https://github.com/owner/repo/releases/download/v0.9.0/tool-0.9.0-linux-amd64.tar.gz\"\n" +
+	"curl -LO https://github.com/owner/repo/releases/latest/download/tool-$(uname -s)-$(uname -m).zip\n" +
+	"curl -LO https://github.com/owner/repo/releases/download/${VERSION}/tool-${VERSION#v}-darwin-arm64.tar.gz\n" +
+	"```\n"
+
+// remoteTree is a GitHub repo without a release config.
+var remoteTree = &fsTarget{
+	fsys: fstest.MapFS{},
+	meta: TargetMeta{Name: "repo", Forge: ForgeGitHub, Origin: &RemoteRepo{Owner: "owner", Repo: "repo"}},
+}
+
+func parseSkill(t *testing.T, content string) *skillmd.SkillFile {
+	t.Helper()
+	sf, err := skillmd.Parse(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sf
+}
+
+func TestInstallDownloads(t *testing.T) {
+	paths := installDownloads(parseSkill(t, installSection))
+	if len(paths) != 4 {
+		t.Fatalf("got %d paths, want 4: %+v", len(paths), paths)
+	}
+	if p := paths[0]; p.Owner != "owner" || p.Repo != "repo" || p.Tag != "v1.0.0" || p.Asset != "tool-1.0.0-linux-amd64.tar.gz" {
+		t.Errorf("literal path = %+v", p)
+	}
+	if p := paths[2]; p.Tag != "" || p.Asset != "tool-${_}-${_}.zip" {
+		t.Errorf("latest path = %+v", p)
+	}
+	if !paths[3].templatedTag() {
+		t.Errorf("expected ${VERSION} tag to be templated: %+v", paths[3])
+	}
+	if !paths[2].assetPattern("").MatchString("tool-Linux-x86_64.zip") {
+		t.Error("command substitutions should match any text")
+	}
+
+	if got := installDownloads(parseSkill(t, "# tool\n\n## Install\n\n```\nbrew install tool\n```\n")); got != nil {
+		t.Errorf("got %+v, want no download paths", got)
+	}
+}
+
+func TestCheckInstallAssets_GitHub(t *testing.T) {
+	v1 := Release{TagName: "v1.0.0", Assets: []ReleaseAsset{
+		{Name: "tool-1.0.0-linux-amd64.tar.gz"},
+		{Name: "tool-1.0.0-darwin-arm64.tar.gz"},
+	}}
+	srv, client := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/repo/releases/tags/v1.0.0":
+			_ = json.NewEncoder(w).Encode(v1)
+		case "/repos/owner/repo/releases":
+			_ = json.NewEncoder(w).Encode([]Release{v1})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer srv.Close()
+	client.webHost = "github.com"
+
+	c, ok := checkInstallAssets(remoteTree, client, parseSkill(t, installSection))
+	if !ok {
+		t.Fatal("expected the check to run")
+	}
+	if c.Status != StatusFail {
+		t.Fatalf("status = %s, want fail: %s", c.Status, c.Message)
+	}
+	for _, want := range []string{"2 of 4", "no release tagged v0.9.0", "no matching asset in v1.0.0"} {
+		if !strings.Contains(c.Message, want) {
+			t.Errorf("message %q does not mention %q", c.Message, want)
+		}
+	}
+	if strings.Contains(c.Message, "v1.0.0/tool-1.0.0-linux-amd64") || strings.Contains(c.Message, "darwin-arm64") {
+		t.Errorf("message reports a path that resolves: %s", c.Message)
+	}
+
+	if _, ok := checkInstallAssets(remoteTree, client, parseSkill(t, "# tool\n\n## Install\n\nbrew install tool\n")); ok {
+		t.Error("expected no check without download URLs")
+	}
+}
+
+func TestCheckInstallAssets_GitHubUnreachable(t *testing.T) {
+	srv, client := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	defer srv.Close()
+	client.webHost = "github.com"
+
+	c, _ := checkInstallAssets(remoteTree, client, parseSkill(t, installSection))
+	if c.Status != StatusWarn {
+		t.Errorf("status = %s, want warn: %s", c.Status, c.Message)
+	}
+}
+
+func TestCheckInstallAssets_GitHubEnterprise(t *testing.T) {
+	// The github.com download URLs are not looked up on an enterprise API.
+	srv, client := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s", r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+	})
+	defer srv.Close()
+	client.webHost = "ghe.example.com"
+
+	c, _ := checkInstallAssets(remoteTree, client, parseSkill(t, installSection))
+	if c.Status != StatusWarn {
+		t.Errorf("status = %s, want warn: %s", c.Status, c.Message)
+	}
+}
+
+func TestCheckInstallAssets_LocalGoreleaser(t *testing.T) {
+	const goreleaser = `project_name: tool
+builds:
+  - goos: [linux, darwin]
+    goarch: [amd64, arm64]
+archives:
+  - name_template: "{{ .ProjectName }}-{{ .Version }}-{{ .Os }}-{{ .Arch }}"
+`
+	repo := t.TempDir()
+	if err := writeFile(filepath.Join(repo, ".goreleaser.yml"), []byte(goreleaser)); err != nil {
+		t.Fatal(err)
+	}
+	fsys := os.DirFS(repo)
+
+	ok := "# tool\n\n## Install\n\n```\n" +
+		"curl -LO https://github.com/owner/repo/releases/download/v2.3.4/tool-2.3.4-linux-amd64.tar.gz\n" +
+		"curl -LO https://github.com/owner/repo/releases/download/$TAG/tool-${VERSION}-darwin-arm64.tar.gz\n```\n"
+	c, ran := checkInstallAssets(MemoryTarget("tool", fsys), nil, parseSkill(t, ok))
+	if !ran || c.Status != StatusPass {
+		t.Errorf("got %+v, want pass", c)
+	}
+
+	// name_template now uses underscores; the documented URL still has dashes.
+	rotted := "# tool\n\n## Install\n\n```\n" +
+		"curl -LO https://github.com/owner/repo/releases/download/v2.3.4/tool_2.3.4_linux_amd64.tar.gz\n```\n"
+	c, _ = checkInstallAssets(MemoryTarget("tool", fsys), nil, parseSkill(t, rotted))
+	if c.Status != StatusFail || !strings.Contains(c.Message, "tool_2.3.4_linux_amd64.tar.gz") {
+		t.Errorf("got %+v, want fail naming the broken asset", c)
+	}
+
+	// A templated tag does not make a version written in the asset name
+	// a mismatch; a placeholder in both stands for the version.
+	templated := "# tool\n\n## Install\n\n```\n" +
+		"curl -LO https://github.com/owner/repo/releases/download/$VERSION/tool-2.3.4-linux-amd64.tar.gz\n" +
+		"curl -LO https://github.com/owner/repo/releases/download/{{version}}/tool-2.3.4-darwin-amd64.tar.gz\n" +
+		"curl -LO https://github.com/owner/repo/releases/download/latest/tool-2.3.4-linux-arm64.tar.gz\n" +
+		"curl -LO https://github.com/owner/repo/releases/download/v{{version}}/tool-{{version}}-darwin-arm64.tar.gz\n```\n"
+	c, _ = checkInstallAssets(MemoryTarget("tool", fsys), nil, parseSkill(t, templated))
+	if c.Status != StatusPass {
+		t.Errorf("templated tags got %+v, want pass", c)
+	}
+
+	c, _ = checkInstallAssets(MemoryTarget("tool", os.DirFS(t.TempDir())), nil, parseSkill(t, ok))
+	if c.Status != StatusWarn {
+		t.Errorf("without a GoReleaser config got %+v, want warn", c)
+	}
+}
+
+func TestCheckInstallAssets_LocalOtherRepo(t *testing.T) {
+	repo := t.TempDir()
+	writeGitConfig(t, filepath.Join(repo, ".git"), "[remote \"origin\"]\n\turl = https://github.com/owner/repo.git\n")
+	if err := writeFile(filepath.Join(repo, ".goreleaser.yml"), []byte("builds:\n  - goos: [linux]\n")); err != nil {
+		t.Fatal(err)
+	}
+	sf := parseSkill(t, "# tool\n\n## Install\n\ncurl -LO https://github.com/other/dep/releases/download/v1.0.0/dep.tar.gz\n")
+	target, err := LocalTarget(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, _ := checkInstallAssets(target, nil, sf)
+	if c.Status != StatusWarn {
+		t.Errorf("got %+v, want warn for a path into another repo", c)
+	}
+}
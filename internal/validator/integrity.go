@@ -0,0 +1,325 @@
+package validator
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/ppiankov/ancc/internal/release"
+)
+
+// Supply-chain integrity checks, run when a published release is inspected.
+const (
+	CheckReleaseChecksums  = "release-checksums"
+	CheckReleaseSignatures = "release-signatures"
+	CheckReleaseSBOM       = "release-sbom"
+	CheckReleaseProvenance = "release-provenance"
+)
+
+// IntegrityChecks lists the integrity checks in report order.
+var IntegrityChecks = []string{
+	CheckReleaseChecksums,
+	CheckReleaseSignatures,
+	CheckReleaseSBOM,
+	CheckReleaseProvenance,
+}
+
+// Severities an integrity check reports when the release falls short.
+const (
+	SeverityWarn = "warn"
+	SeverityFail = "fail"
+	SeverityOff  = "off"
+)
+
+// maxChecksumFile bounds the checksum manifest download.
+const maxChecksumFile = 1 << 20
+
+// ParseIntegrity validates severities keyed by short name (checksums,
+// signatures, sbom, provenance) and returns them keyed by check name.
+func ParseIntegrity(levels map[string]string) (map[string]string, error) {
+	out := make(map[string]string, len(levels))
+	for k, v := range levels {
+		name := "release-" + strings.ToLower(k)
+		known := false
+		for _, c := range IntegrityChecks {
+			known = known || c == name
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown integrity check %q: want checksums, signatures, sbom or provenance", k)
+		}
+		switch v {
+		case SeverityWarn, SeverityFail, SeverityOff:
+			out[name] = v
+		default:
+			return nil, fmt.Errorf("invalid severity %q for %s: want warn, fail or off", v, k)
+		}
+	}
+	return out, nil
+}
+
+func (o Options) severity(check string) string {
+	if s := o.Integrity[check]; s != "" {
+		return s
+	}
+	return SeverityWarn
+}
+
+// shortfall reports an integrity problem at the configured severity.
+func shortfall(name, severity, msg string) CheckResult {
+	if severity == SeverityFail {
+		return fail(name, msg)
+	}
+	return warn(name, msg)
+}
+
+// integrityUnavailable reports every enabled integrity check as unmet
+// because no release could be inspected.
+func integrityUnavailable(opts Options, msg string) []CheckResult {
+	var out []CheckResult
+	for _, name := range IntegrityChecks {
+		if sev := opts.severity(name); sev != SeverityOff {
+			out = append(out, shortfall(name, sev, msg))
+		}
+	}
+	return out
+}
+
+// integrityChecks inspects the assets of rel for checksums, signatures,
+// SBOMs and provenance attestations.
+func integrityChecks(client Forge, owner, repo string, rel *Release, opts Options) []CheckResult {
+	if rel == nil {
+		return integrityUnavailable(opts, "no release to inspect")
+	}
+	binaries := binaryAssets(rel)
+	checks := map[string]func() CheckResult{
+		CheckReleaseChecksums:  func() CheckResult { return checkChecksums(client, rel, binaries, opts.severity(CheckReleaseChecksums)) },
+		CheckReleaseSignatures: func() CheckResult { return checkSignatures(rel, binaries, opts.severity(CheckReleaseSignatures)) },
+		CheckReleaseSBOM:       func() CheckResult { return checkSBOM(rel, opts.severity(CheckReleaseSBOM)) },
+		CheckReleaseProvenance: func() CheckResult {
+			return checkProvenance(client, owner, repo, rel, binaries, opts.severity(CheckReleaseProvenance))
+		},
+	}
+
+	var out []CheckResult
+	for _, name := range IntegrityChecks {
+		if opts.severity(name) != SeverityOff {
+			out = append(out, checks[name]())
+		}
+	}
+	return out
+}
+
+// binaryAssets returns the release assets that are binaries, placed on a
+// platform or not.
+func binaryAssets(rel *Release) []ReleaseAsset {
+	var out []ReleaseAsset
+	for _, a := range rel.Assets {
+		if release.IsAuxiliary(a.Name) {
+			continue
+		}
+		if _, ok := release.ParseAsset(a.Name); ok || isBinaryAsset(a.Name) {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// trimAnySuffix cuts the first of suffixes that name ends with, ignoring
+// case.
+func trimAnySuffix(name string, suffixes []string) (string, bool) {
+	lower := strings.ToLower(name)
+	for _, s := range suffixes {
+		if strings.HasSuffix(lower, s) {
+			return name[:len(name)-len(s)], true
+		}
+	}
+	return name, false
+}
+
+// isChecksumManifest reports whether an asset lists checksums for several
+// files, like GoReleaser's checksums.txt or SHA256SUMS.
+func isChecksumManifest(name string) bool {
+	lower := strings.ToLower(name)
+	if _, ok := trimAnySuffix(lower, release.SignatureSuffixes); ok {
+		return false
+	}
+	return strings.Contains(lower, "checksum") || strings.Contains(lower, "sha256sums") ||
+		strings.Contains(lower, "sha512sums") || lower == "sums.txt"
+}
+
+// checkChecksums requires every binary asset to be listed in a checksum
+// manifest or to have its own checksum file.
+func checkChecksums(client Forge, rel *Release, binaries []ReleaseAsset, severity string) CheckResult {
+	covered := make(map[string]bool)
+	var manifests []string
+	for _, a := range rel.Assets {
+		if subject, ok := trimAnySuffix(a.Name, release.ChecksumSuffixes); ok {
+			covered[subject] = true
+			continue
+		}
+		if !isChecksumManifest(a.Name) {
+			continue
+		}
+		manifests = append(manifests, a.Name)
+		data, err := client.FetchAsset(a, maxChecksumFile)
+		if err != nil {
+			return shortfall(CheckReleaseChecksums, severity, fmt.Sprintf("%s: could not read %s: %v", rel.TagName, a.Name, err))
+		}
+		for _, name := range checksumEntries(data) {
+			covered[name] = true
+		}
+	}
+
+	if len(manifests) == 0 && len(covered) == 0 {
+		return shortfall(CheckReleaseChecksums, severity, fmt.Sprintf("%s: no checksum file", rel.TagName))
+	}
+	var missing []string
+	for _, b := range binaries {
+		if !covered[b.Name] {
+			missing = append(missing, b.Name)
+		}
+	}
+	source := "per-asset checksum files"
+	if len(manifests) > 0 {
+		source = strings.Join(manifests, ", ")
+	}
+	if len(missing) > 0 {
+		return shortfall(CheckReleaseChecksums, severity,
+			fmt.Sprintf("%s: %s does not list %s", rel.TagName, source, strings.Join(missing, ", ")))
+	}
+	return pass(CheckReleaseChecksums, fmt.Sprintf("%s: %s covers all %d binary asset(s)", rel.TagName, source, len(binaries)))
+}
+
+// checksumEntries reads file names from sha256sum-style lines
+// ("<hex>  name" or "<hex> *name") and BSD-style lines
+// ("SHA256 (name) = <hex>").
+func checksumEntries(data []byte) []string {
+	var out []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if open := strings.Index(line, " ("); open > 0 && strings.Contains(line, ") = ") {
+			out = append(out, path.Base(line[open+2:strings.LastIndex(line, ") = ")]))
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		out = append(out, path.Base(strings.TrimPrefix(fields[len(fields)-1], "*")))
+	}
+	return out
+}
+
+// checkSignatures passes when the checksum manifest is signed, which
+// covers every binary it lists, or when every binary has a signature.
+func checkSignatures(rel *Release, binaries []ReleaseAsset, severity string) CheckResult {
+	signed := make(map[string]bool)
+	var sigs []string
+	for _, a := range rel.Assets {
+		if subject, ok := trimAnySuffix(a.Name, release.SignatureSuffixes); ok {
+			signed[subject] = true
+			sigs = append(sigs, a.Name)
+		}
+	}
+	if len(sigs) == 0 {
+		return shortfall(CheckReleaseSignatures, severity,
+			fmt.Sprintf("%s: no signatures (.sig, .asc, .pem, cosign bundles)", rel.TagName))
+	}
+
+	for subject := range signed {
+		if isChecksumManifest(subject) {
+			return pass(CheckReleaseSignatures, fmt.Sprintf("%s: checksum file %s is signed", rel.TagName, subject))
+		}
+	}
+	var unsigned []string
+	for _, b := range binaries {
+		if !signed[b.Name] {
+			unsigned = append(unsigned, b.Name)
+		}
+	}
+	if len(unsigned) > 0 {
+		return shortfall(CheckReleaseSignatures, severity,
+			fmt.Sprintf("%s: signatures cover %d of %d binary asset(s); unsigned: %s",
+				rel.TagName, len(binaries)-len(unsigned), len(binaries), strings.Join(unsigned, ", ")))
+	}
+	return pass(CheckReleaseSignatures, fmt.Sprintf("%s: all %d binary asset(s) are signed", rel.TagName, len(binaries)))
+}
+
+// checkSBOM passes when the release ships an SPDX or CycloneDX document.
+func checkSBOM(rel *Release, severity string) CheckResult {
+	var sboms []string
+	for _, a := range rel.Assets {
+		if _, ok := trimAnySuffix(a.Name, release.SBOMSuffixes); ok {
+			sboms = append(sboms, a.Name)
+		}
+	}
+	if len(sboms) == 0 {
+		return shortfall(CheckReleaseSBOM, severity,
+			fmt.Sprintf("%s: no SBOM (.spdx.json, .cdx.json)", rel.TagName))
+	}
+	sort.Strings(sboms)
+	return pass(CheckReleaseSBOM, fmt.Sprintf("%s: %s", rel.TagName, strings.Join(sboms, ", ")))
+}
+
+// checkProvenance passes on an in-toto/SLSA provenance asset, or on a
+// GitHub artifact attestation for a binary asset's digest.
+func checkProvenance(client Forge, owner, repo string, rel *Release, binaries []ReleaseAsset, severity string) CheckResult {
+	for _, a := range rel.Assets {
+		if _, ok := trimAnySuffix(a.Name, release.ProvenanceSuffixes); ok {
+			return pass(CheckReleaseProvenance, fmt.Sprintf("%s: provenance %s", rel.TagName, a.Name))
+		}
+	}
+	att, _ := client.(attester)
+	for _, b := range binaries {
+		if att == nil || b.Digest == "" {
+			continue
+		}
+		if ok, err := att.HasAttestation(owner, repo, b.Digest); err == nil && ok {
+			return pass(CheckReleaseProvenance, fmt.Sprintf("%s: GitHub artifact attestation for %s", rel.TagName, b.Name))
+		}
+		// One lookup is enough: attestations are produced per build.
+		break
+	}
+	return shortfall(CheckReleaseProvenance, severity,
+		fmt.Sprintf("%s: no SLSA provenance (.intoto.jsonl) or artifact attestation", rel.TagName))
+}
+
+// attester is a Forge that keeps artifact attestations, as GitHub does.
+type attester interface {
+	HasAttestation(owner, repo, digest string) (bool, error)
+}
+
+// HasAttestation reports whether GitHub holds an artifact attestation for
+// the subject digest, e.g. "sha256:<hex>".
+func (c *gitHubClient) HasAttestation(owner, repo, digest string) (bool, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/attestations/%s", c.baseURL, owner, repo, digest)
+	resp, err := c.doRequest(url)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("GitHub API error: %s", resp.Status)
+	}
+	var body struct {
+		Attestations []json.RawMessage `json:"attestations"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return false, fmt.Errorf("decoding attestations: %w", err)
+	}
+	return len(body.Attestations) > 0, nil
+}
@@ -0,0 +1,173 @@
+package validator
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func integrityOff() map[string]string {
+	out := make(map[string]string)
+	for _, name := range IntegrityChecks {
+		out[name] = SeverityOff
+	}
+	return out
+}
+
+// integrityServer serves one release whose assets download from the
+// server, plus the attestation endpoint for one digest.
+func integrityServer(t *testing.T, names []string, files map[string]string, attested string) (*httptest.Server, *gitHubClient) {
+	t.Helper()
+	var srv *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/repo/releases", func(w http.ResponseWriter, r *http.Request) {
+		rel := Release{TagName: "v1.0.0"}
+		for _, n := range names {
+			rel.Assets = append(rel.Assets, ReleaseAsset{
+				Name:               n,
+				BrowserDownloadURL: srv.URL + "/download/" + n,
+				Digest:             "sha256:" + n,
+			})
+		}
+		_ = json.NewEncoder(w).Encode([]Release{rel})
+	})
+	mux.HandleFunc("/download/", func(w http.ResponseWriter, r *http.Request) {
+		body, ok := files[strings.TrimPrefix(r.URL.Path, "/download/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	})
+	mux.HandleFunc("/repos/owner/repo/attestations/", func(w http.ResponseWriter, r *http.Request) {
+		if strings.TrimPrefix(r.URL.Path, "/repos/owner/repo/attestations/") != attested {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"attestations": [{"bundle": {}}]}`))
+	})
+	srv = httptest.NewServer(mux)
+	return srv, &gitHubClient{baseURL: srv.URL, httpClient: srv.Client()}
+}
+
+func integrityResults(t *testing.T, client *gitHubClient, opts Options) map[string]CheckResult {
+	t.Helper()
+	checks, _, err := releaseChecksRemote(client, "owner", "repo", opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := make(map[string]CheckResult)
+	for _, c := range checks[1:] {
+		out[c.Name] = c
+	}
+	return out
+}
+
+func TestIntegrityChecks_Complete(t *testing.T) {
+	names := []string{
+		"tool_linux_amd64.tar.gz",
+		"tool_darwin_arm64.tar.gz",
+		"checksums.txt",
+		"checksums.txt.sig",
+		"checksums.txt.pem",
+		"tool.spdx.json",
+		"multiple.intoto.jsonl",
+		// Sidecars named after a binary are not binaries themselves.
+		"tool_linux_amd64.tar.gz.sha512sum",
+		"tool_darwin_arm64.tar.gz.minisig",
+		"tool_linux_amd64.cdx.xml",
+	}
+	files := map[string]string{"checksums.txt": "aaa  tool_linux_amd64.tar.gz\nbbb  tool_darwin_arm64.tar.gz\n"}
+	srv, client := integrityServer(t, names, files, "")
+	defer srv.Close()
+
+	results := integrityResults(t, client, Options{})
+	if len(results) != len(IntegrityChecks) {
+		t.Fatalf("got %d integrity checks, want %d", len(results), len(IntegrityChecks))
+	}
+	for _, name := range IntegrityChecks {
+		if r := results[name]; r.Status != StatusPass {
+			t.Errorf("%s: %s %q, want pass", name, r.Status, r.Message)
+		}
+	}
+}
+
+func TestIntegrityChecks_Shortfalls(t *testing.T) {
+	names := []string{
+		"tool_linux_amd64.tar.gz",
+		"tool_darwin_arm64.tar.gz",
+		"checksums.txt",
+		"tool_linux_amd64.tar.gz.sig",
+	}
+	files := map[string]string{"checksums.txt": "SHA256 (tool_linux_amd64.tar.gz) = aaa\n"}
+	srv, client := integrityServer(t, names, files, "")
+	defer srv.Close()
+
+	opts := Options{Integrity: map[string]string{CheckReleaseChecksums: SeverityFail, CheckReleaseSBOM: SeverityOff}}
+	results := integrityResults(t, client, opts)
+
+	tests := []struct {
+		name     string
+		want     string
+		contains string
+	}{
+		{CheckReleaseChecksums, StatusFail, "does not list tool_darwin_arm64.tar.gz"},
+		{CheckReleaseSignatures, StatusWarn, "cover 1 of 2"},
+		{CheckReleaseProvenance, StatusWarn, "no SLSA provenance"},
+	}
+	for _, tt := range tests {
+		r := results[tt.name]
+		if r.Status != tt.want || !strings.Contains(r.Message, tt.contains) {
+			t.Errorf("%s: got %s %q, want %s containing %q", tt.name, r.Status, r.Message, tt.want, tt.contains)
+		}
+	}
+	if _, ok := results[CheckReleaseSBOM]; ok {
+		t.Error("sbom check is off but was reported")
+	}
+}
+
+func TestIntegrityChecks_AttestationAndSidecars(t *testing.T) {
+	names := []string{
+		"tool_linux_amd64.tar.gz",
+		"tool_linux_amd64.tar.gz.sha256",
+	}
+	srv, client := integrityServer(t, names, nil, "sha256:tool_linux_amd64.tar.gz")
+	defer srv.Close()
+
+	results := integrityResults(t, client, Options{})
+	if r := results[CheckReleaseChecksums]; r.Status != StatusPass {
+		t.Errorf("checksums: %s %q, want pass from the sidecar file", r.Status, r.Message)
+	}
+	if r := results[CheckReleaseProvenance]; r.Status != StatusPass || !strings.Contains(r.Message, "attestation") {
+		t.Errorf("provenance: %s %q, want pass from the attestation API", r.Status, r.Message)
+	}
+	if r := results[CheckReleaseSignatures]; r.Status != StatusWarn {
+		t.Errorf("signatures: %s %q, want warn", r.Status, r.Message)
+	}
+}
+
+func TestParseIntegrity(t *testing.T) {
+	got, err := ParseIntegrity(map[string]string{"checksums": "fail", "sbom": "off"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got[CheckReleaseChecksums] != SeverityFail || got[CheckReleaseSBOM] != SeverityOff {
+		t.Errorf("got %v", got)
+	}
+	if _, err := ParseIntegrity(map[string]string{"checksums": "error"}); err == nil {
+		t.Error("expected error for an unknown severity")
+	}
+	if _, err := ParseIntegrity(map[string]string{"licenses": "warn"}); err == nil {
+		t.Error("expected error for an unknown check")
+	}
+}
+
+func TestChecksumEntries(t *testing.T) {
+	data := "# comment\naaa  dist/a.tar.gz\nbbb *b.zip\nSHA256 (c.deb) = ccc\n"
+	got := strings.Join(checksumEntries([]byte(data)), ",")
+	if got != "a.tar.gz,b.zip,c.deb" {
+		t.Errorf("entries = %s", got)
+	}
+}
@@ -0,0 +1,68 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"strings"
+)
+
+// maxRedirects matches the limit net/http applies by default.
+const maxRedirects = 10
+
+// trustedRedirects returns a copy of hc that follows a redirect only when
+// allowed accepts its target or it stays on the host of the original
+// request, and never from https to http. A nil hc stays nil.
+func trustedRedirects(hc *http.Client, allowed func(*neturl.URL) bool) *http.Client {
+	if hc == nil {
+		return nil
+	}
+	c := *hc
+	next := hc.CheckRedirect
+	c.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxRedirects {
+			return errors.New("stopped after 10 redirects")
+		}
+		if via[len(via)-1].URL.Scheme == "https" && req.URL.Scheme != "https" {
+			return fmt.Errorf("refusing redirect from https to %s", req.URL.Redacted())
+		}
+		if !strings.EqualFold(req.URL.Host, via[0].URL.Host) && !allowed(req.URL) {
+			return fmt.Errorf("refusing redirect to untrusted host %s", req.URL.Host)
+		}
+		if next != nil {
+			return next(req, via)
+		}
+		return nil
+	}
+	return &c
+}
+
+// hostWithin reports whether host is domain or one of its subdomains.
+func hostWithin(host, domain string) bool {
+	host = strings.ToLower(host)
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// redirectAllowed trusts the hosts the token is sent to and, on github.com,
+// the githubusercontent.com hosts serving release assets.
+func (c *gitHubClient) redirectAllowed(u *neturl.URL) bool {
+	if c.authorizes(u.String()) {
+		return true
+	}
+	return c.webHost == "github.com" && hostWithin(u.Hostname(), "githubusercontent.com")
+}
+
+// redirectAllowed trusts the hosts the token is sent to and the domains in
+// redirectHosts.
+func (c *restClient) redirectAllowed(u *neturl.URL) bool {
+	if c.authorizes(u) {
+		return true
+	}
+	for _, d := range c.redirectHosts {
+		if hostWithin(u.Hostname(), d) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package validator
+
+import (
+	"net/http"
+	"net/http/httptest"
+	neturl "net/url"
+	"strings"
+	"testing"
+)
+
+func TestTrustedRedirects(t *testing.T) {
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("elsewhere"))
+	}))
+	defer other.Close()
+	// The same server under another name is another host.
+	otherURL := strings.Replace(other.URL, "127.0.0.1", "localhost", 1)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/same":
+			http.Redirect(w, r, "/data", http.StatusFound)
+		case "/away":
+			http.Redirect(w, r, otherURL+"/data", http.StatusFound)
+		default:
+			_, _ = w.Write([]byte("data"))
+		}
+	}))
+	defer srv.Close()
+	client := newGiteaClient("codeberg.org", srv.Client())
+	client.baseURL = srv.URL
+
+	resp, err := client.get(srv.URL+"/same", "")
+	if err != nil {
+		t.Fatalf("redirect on the same host: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	_, err = client.get(srv.URL+"/away", "")
+	if err == nil || !strings.Contains(err.Error(), "untrusted host") {
+		t.Fatalf("err = %v, want an untrusted host error", err)
+	}
+}
+
+func TestTrustedRedirects_Downgrade(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://"+r.Host+"/plain", http.StatusFound)
+	}))
+	defer srv.Close()
+	client := newGitHubEnterpriseClient("127.0.0.1", srv.URL, srv.Client())
+
+	_, err := client.doRequest(srv.URL + "/repos/o/r")
+	if err == nil || !strings.Contains(err.Error(), "refusing redirect from https") {
+		t.Fatalf("err = %v, want a downgrade error", err)
+	}
+}
+
+func TestRedirectAllowed(t *testing.T) {
+	gh := newGitHubClient(http.DefaultClient)
+	ghe := newGitHubEnterpriseClient("ghe.example.com", "", http.DefaultClient)
+	bb := newBitbucketClient(http.DefaultClient)
+	tests := []struct {
+		name    string
+		allowed func(*neturl.URL) bool
+		url     string
+		want    bool
+	}{
+		{"github api", gh.redirectAllowed, "https://api.github.com/x", true},
+		{"github codeload", gh.redirectAllowed, "https://codeload.github.com/o/r/zip", true},
+		{"github assets", gh.redirectAllowed, "https://objects.githubusercontent.com/x", true},
+		{"github lookalike", gh.redirectAllowed, "https://evilgithub.com/x", false},
+		{"github elsewhere", gh.redirectAllowed, "https://example.com/x", false},
+		{"enterprise subdomain", ghe.redirectAllowed, "https://codeload.ghe.example.com/x", true},
+		{"enterprise assets", ghe.redirectAllowed, "https://objects.githubusercontent.com/x", false},
+		{"bitbucket downloads", bb.redirectAllowed, "https://bbuseruploads.s3.amazonaws.com/x", true},
+		{"bitbucket web", bb.redirectAllowed, "https://bitbucket.org/o/r/get/main.zip", true},
+		{"bitbucket other bucket", bb.redirectAllowed, "https://other.s3.amazonaws.com/x", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := neturl.Parse(tt.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := tt.allowed(u); got != tt.want {
+				t.Errorf("redirectAllowed(%s) = %v, want %v", tt.url, got, tt.want)
+			}
+		})
+	}
+}
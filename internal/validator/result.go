@@ -16,9 +16,17 @@ const (
 
 // CheckResult holds the outcome of a single validation check.
 type CheckResult struct {
-	Name    string `json:"name"`
-	Status  string `json:"status"` // "pass", "fail", "warn"
-	Message string `json:"message"`
+	Name      string     `json:"name"`
+	Status    string     `json:"status"` // "pass", "fail", "warn"
+	Message   string     `json:"message"`
+	Locations []Location `json:"locations,omitempty"` // where to look, if anywhere
+}
+
+// Location points a check result at a file of the repo and, when known, a
+// line of it.
+type Location struct {
+	Path string `json:"path"`           // slash-separated, relative to the repo root
+	Line int    `json:"line,omitempty"` // 1-based; 0 for the whole file
 }
 
 // Summary holds aggregated counts.
@@ -29,10 +37,36 @@ type Summary struct {
 	Warn  int `json:"warn"`
 }
 
+// ToolResult holds the checks of one tool of a monorepo, or of a SKILL.md
+// documenting several: its SKILL.md and the subtree it documents.
+type ToolResult struct {
+	Path    string        `json:"path"`           // directory relative to the repo root
+	Name    string        `json:"name,omitempty"` // the tool, when its SKILL.md documents several
+	Status  string        `json:"status"`         // "pass", "fail", "partial"
+	Checks  []CheckResult `json:"checks"`
+	Summary Summary       `json:"summary"`
+}
+
 // ValidationResult holds the full validation outcome.
 type ValidationResult struct {
 	Path    string        `json:"path"`
-	Status  string        `json:"status"` // "pass", "fail", "partial"
-	Checks  []CheckResult `json:"checks"`
-	Summary Summary       `json:"summary"`
+	Ref     string        `json:"ref,omitempty"`     // git ref requested for a GitHub repo
+	Commit  string        `json:"commit,omitempty"`  // commit SHA the GitHub repo was read at
+	Release string        `json:"release,omitempty"` // tag of the GitHub release inspected
+	Status  string        `json:"status"`            // "pass", "fail", "partial"
+	Checks  []CheckResult `json:"checks"`            // repo-level checks only when Tools is set
+	Tools   []ToolResult  `json:"tools,omitempty"`   // the tools of a monorepo or multi-tool SKILL.md
+	Summary Summary       `json:"summary"`           // counts the checks of every tool too
+}
+
+// AllChecks returns the checks of every tool followed by the repo's.
+func (r *ValidationResult) AllChecks() []CheckResult {
+	if len(r.Tools) == 0 {
+		return r.Checks
+	}
+	var all []CheckResult
+	for _, t := range r.Tools {
+		all = append(all, t.Checks...)
+	}
+	return append(all, r.Checks...)
 }
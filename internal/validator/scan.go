@@ -0,0 +1,406 @@
+package validator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ppiankov/ancc/internal/httpclient"
+)
+
+// ScanOptions controls a scan of a GitHub organization or user.
+type ScanOptions struct {
+	// Options applies to each repo's validation.
+	Options
+	// IncludeArchived and IncludeForks scan archived repos and forks,
+	// which are skipped by default.
+	IncludeArchived bool
+	IncludeForks    bool
+	// Topics keeps only repos with at least one of these topics.
+	Topics []string
+	// Jobs is how many repos are validated at once.
+	Jobs int
+	// MaxWait is the longest the scan waits for the rate limit to reset;
+	// repos that would wait longer fail with the rate limit.
+	MaxWait time.Duration
+}
+
+// ScanEntry is the outcome of one repo of a scan.
+type ScanEntry struct {
+	Repo   string            `json:"repo"` // owner/name
+	URL    string            `json:"url"`
+	Score  int               `json:"score"`  // 0-100, see Score
+	Status string            `json:"status"` // "pass", "fail", "partial"
+	Error  string            `json:"error,omitempty"`
+	Result *ValidationResult `json:"result,omitempty"`
+}
+
+// ScanSummary counts the repos of a scan.
+type ScanSummary struct {
+	Listed   int `json:"listed"`
+	Archived int `json:"archived"`  // skipped as archived
+	Forks    int `json:"forks"`     // skipped as forks
+	OffTopic int `json:"off_topic"` // skipped for lacking the topics
+	Scanned  int `json:"scanned"`
+	Adopted  int `json:"adopted"` // scanned repos with a SKILL.md
+	Pass     int `json:"pass"`
+	Partial  int `json:"partial"`
+	Fail     int `json:"fail"`
+	Error    int `json:"error"`
+	Score    int `json:"score"` // mean score of the scanned repos
+}
+
+// ScanReport ranks the repos of an organization or user by compliance.
+type ScanReport struct {
+	Host    string       `json:"host"`
+	Owner   string       `json:"owner"`
+	Status  string       `json:"status"` // the worst repo status
+	Repos   []ScanEntry  `json:"repos"`  // highest score first
+	Checks  []CheckTally `json:"checks"` // checks that failed or warned, most failures first
+	Summary ScanSummary  `json:"summary"`
+}
+
+// Score rates a result from 0 to 100: passing checks count fully, warnings
+// half and failures not at all. A monorepo is scored over the checks of all
+// its tools.
+func Score(r *ValidationResult) int {
+	if r == nil {
+		return 0
+	}
+	checks := r.AllChecks()
+	if len(checks) == 0 {
+		return 0
+	}
+	points := 0.0
+	for _, c := range checks {
+		switch c.Status {
+		case StatusPass:
+			points++
+		case StatusWarn:
+			points += 0.5
+		}
+	}
+	return int(math.Round(100 * points / float64(len(checks))))
+}
+
+var reOwnerPath = regexp.MustCompile(`^([^/\s]+)/([A-Za-z0-9][A-Za-z0-9_.-]*)/?$`)
+
+// ParseOwnerURL parses a GitHub organization or user URL such as
+// github.com/org or https://ghe.example.com/org.
+func (f Forges) ParseOwnerURL(s string) (host, owner string, ok bool) {
+	rest := s
+	for _, scheme := range []string{"https://", "http://"} {
+		rest = strings.TrimPrefix(rest, scheme)
+	}
+	m := reOwnerPath.FindStringSubmatch(rest)
+	if m == nil {
+		return "", "", false
+	}
+	host = strings.ToLower(m[1])
+	if host != "github.com" && !containsFold(f.gitHubHosts(), host) {
+		return "", "", false
+	}
+	return host, m[2], true
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Scan lists the repos of a GitHub organization or user, given as
+// github.com/<owner> or an enterprise host's equivalent, and validates
+// them concurrently, pacing requests by the rate limit GitHub reports.
+func Scan(target string, opts ScanOptions) (*ScanReport, error) {
+	host, owner, ok := opts.Forges.ParseOwnerURL(target)
+	if !ok {
+		return nil, fmt.Errorf("%s: not a GitHub organization or user URL", target)
+	}
+	if opts.Offline {
+		return nil, fmt.Errorf("cannot scan %s offline", target)
+	}
+	gate := newRateGate(opts.Jobs, opts.MaxWait)
+	hc := opts.Forges.HTTPClient
+	if hc == nil {
+		hc = defaultHTTPClient
+	}
+	opts.Forges.HTTPClient = gate.client(hc)
+	forge, err := opts.Forges.NewForge(&RemoteRepo{Forge: ForgeGitHub, Host: host, Owner: owner})
+	if err != nil {
+		return nil, err
+	}
+	return scanOwner(forge.(*gitHubClient), gate, host, owner, opts)
+}
+
+// scanOwner is the testable core of Scan.
+func scanOwner(client *gitHubClient, gate *rateGate, host, owner string, opts ScanOptions) (*ScanReport, error) {
+	repos, err := client.ListRepos(owner)
+	if err != nil {
+		return nil, err
+	}
+	report := &ScanReport{Host: host, Owner: owner}
+	report.Summary.Listed = len(repos)
+
+	var names []string
+	byName := make(map[string]GitHubRepo)
+	for _, r := range repos {
+		switch {
+		case r.Archived && !opts.IncludeArchived:
+			report.Summary.Archived++
+		case r.Fork && !opts.IncludeForks:
+			report.Summary.Forks++
+		case len(opts.Topics) > 0 && !hasAnyTopic(r.Topics, opts.Topics):
+			report.Summary.OffTopic++
+		default:
+			names = append(names, r.Name)
+			byName[r.Name] = r
+		}
+	}
+
+	batch := ValidateAll(names, opts.Jobs, func(name string) (*ValidationResult, error) {
+		r := &RemoteRepo{Forge: ForgeGitHub, Host: host, Owner: owner, Repo: name}
+		for attempt := 0; ; attempt++ {
+			if err := gate.wait(host, client.token != ""); err != nil {
+				return nil, err
+			}
+			result, err := validateRemote(client, r, opts.Options)
+			var rl *httpclient.RateLimitError
+			if attempt == 0 && errors.As(err, &rl) {
+				gate.limited(rl)
+				continue
+			}
+			return result, err
+		}
+	})
+
+	report.Status, report.Checks = batch.Status, batch.Checks
+	s := &report.Summary
+	s.Scanned = len(names)
+	s.Pass, s.Partial, s.Fail, s.Error = batch.Summary.Pass, batch.Summary.Partial, batch.Summary.Fail, batch.Summary.Error
+	total := 0
+	for _, br := range batch.Repos {
+		r := byName[br.Path]
+		e := ScanEntry{Repo: r.FullName, URL: r.HTMLURL, Status: br.Status, Error: br.Error, Result: br.Result, Score: Score(br.Result)}
+		if e.Repo == "" {
+			e.Repo = owner + "/" + r.Name
+		}
+		if hasSkillMD(br.Result) {
+			s.Adopted++
+		}
+		total += e.Score
+		report.Repos = append(report.Repos, e)
+	}
+	if s.Scanned > 0 {
+		s.Score = int(math.Round(float64(total) / float64(s.Scanned)))
+	}
+	sort.SliceStable(report.Repos, func(i, j int) bool {
+		a, b := report.Repos[i], report.Repos[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return strings.ToLower(a.Repo) < strings.ToLower(b.Repo)
+	})
+	return report, nil
+}
+
+// hasSkillMD reports whether a repo, or any tool of it, has a usable
+// SKILL.md.
+func hasSkillMD(r *ValidationResult) bool {
+	if r == nil {
+		return false
+	}
+	for _, c := range r.AllChecks() {
+		if c.Name == CheckSkillMDExists && c.Status == StatusPass {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAnyTopic(topics, want []string) bool {
+	for _, w := range want {
+		if containsFold(topics, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// GitHubRepo is a repository as GitHub lists it.
+type GitHubRepo struct {
+	Name          string   `json:"name"`
+	FullName      string   `json:"full_name"`
+	HTMLURL       string   `json:"html_url"`
+	Archived      bool     `json:"archived"`
+	Fork          bool     `json:"fork"`
+	Topics        []string `json:"topics"`
+	DefaultBranch string   `json:"default_branch"`
+}
+
+// maxRepoPages bounds repo listing at 10,000 repos. Listings that go on
+// fail rather than scan a partial list.
+const maxRepoPages = 100
+
+// ListRepos lists the repos of an organization, or of a user when no
+// organization has that name, following the Link header across pages.
+func (c *gitHubClient) ListRepos(owner string) ([]GitHubRepo, error) {
+	repos, err := c.listRepos(fmt.Sprintf("%s/orgs/%s/repos?type=all&per_page=100", c.baseURL, owner))
+	if errors.Is(err, errNotFound) {
+		repos, err = c.listRepos(fmt.Sprintf("%s/users/%s/repos?type=owner&per_page=100", c.baseURL, owner))
+	}
+	if errors.Is(err, errNotFound) {
+		return nil, fmt.Errorf("no GitHub organization or user %s", owner)
+	}
+	return repos, err
+}
+
+func (c *gitHubClient) listRepos(url string) ([]GitHubRepo, error) {
+	var all []GitHubRepo
+	for page := 0; url != "" && page < maxRepoPages; page++ {
+		resp, err := c.doRequest(url)
+		if err != nil {
+			return nil, fmt.Errorf("listing repos: %w", err)
+		}
+		var repos []GitHubRepo
+		switch resp.StatusCode {
+		case http.StatusOK:
+			err = json.NewDecoder(resp.Body).Decode(&repos)
+		case http.StatusNotFound:
+			err = errNotFound
+		default:
+			err = fmt.Errorf("listing repos: GitHub API error: %s", resp.Status)
+		}
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, repos...)
+		url = nextLink(resp.Header.Get("Link"))
+	}
+	if url != "" {
+		return nil, pageLimitError("listing repos", maxRepoPages)
+	}
+	return all, nil
+}
+
+// requestsPerRepo estimates the API requests one repo's validation costs.
+const requestsPerRepo = 10
+
+// rateGate paces a scan by the rate limit GitHub reports with every
+// response. Once fewer requests remain than the running validations may
+// need, new ones wait for the reset, or fail at once when it is further
+// away than maxWait.
+type rateGate struct {
+	reserve int
+	maxWait time.Duration
+	now     func() time.Time
+	sleep   func(time.Duration)
+
+	mu        sync.Mutex
+	remaining int // -1 while unknown
+	reset     time.Time
+}
+
+func newRateGate(jobs int, maxWait time.Duration) *rateGate {
+	return &rateGate{
+		reserve:   max(jobs, 1) * requestsPerRepo,
+		maxWait:   maxWait,
+		now:       time.Now,
+		sleep:     time.Sleep,
+		remaining: -1,
+	}
+}
+
+// client returns a copy of hc whose responses update the gate.
+func (g *rateGate) client(hc *http.Client) *http.Client {
+	c := *hc
+	next := c.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	c.Transport = &gateTransport{next: next, gate: g}
+	return &c
+}
+
+// observe records the core rate limit a response reports.
+func (g *rateGate) observe(h http.Header) {
+	if res := h.Get("X-RateLimit-Resource"); res != "" && res != "core" {
+		return
+	}
+	remaining, err := strconv.Atoi(h.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	reset, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.remaining, g.reset = remaining, time.Unix(reset, 0)
+}
+
+// limited records a rate limit a validation ran into. A secondary limit
+// without Retry-After is waited out for a minute.
+func (g *rateGate) limited(rl *httpclient.RateLimitError) {
+	reset := rl.Reset
+	if reset.IsZero() {
+		wait := rl.RetryAfter
+		if wait <= 0 {
+			wait = time.Minute
+		}
+		reset = g.now().Add(wait)
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.remaining, g.reset = 0, reset
+}
+
+// wait blocks until another validation may start.
+func (g *rateGate) wait(host string, authenticated bool) error {
+	g.mu.Lock()
+	remaining, reset := g.remaining, g.reset
+	g.mu.Unlock()
+	if remaining < 0 || remaining >= g.reserve {
+		return nil
+	}
+	d := reset.Sub(g.now())
+	if d <= 0 {
+		return nil
+	}
+	if d > g.maxWait {
+		return &httpclient.RateLimitError{Host: host, Reset: reset, Authenticated: authenticated}
+	}
+	g.sleep(d)
+	g.mu.Lock()
+	if g.reset.Equal(reset) {
+		g.remaining = -1
+	}
+	g.mu.Unlock()
+	return nil
+}
+
+type gateTransport struct {
+	next http.RoundTripper
+	gate *rateGate
+}
+
+func (t *gateTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err == nil {
+		t.gate.observe(resp.Header)
+	}
+	return resp, err
+}
@@ -0,0 +1,257 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ppiankov/ancc/internal/httpclient"
+)
+
+// orgServer fakes the GitHub API of the acme organization: repos listed
+// over two pages, "tool" with a SKILL.md and "bare" without. Every
+// response reports remaining as the rate limit left.
+func orgServer(t *testing.T, remaining *atomic.Int64) *httptest.Server {
+	t.Helper()
+	skill, err := readFile(testdataPath("valid-skill.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	repos := []GitHubRepo{
+		{Name: "tool", FullName: "acme/tool", Topics: []string{"cli", "go"}},
+		{Name: "old", FullName: "acme/old", Archived: true, Topics: []string{"cli"}},
+		{Name: "tool-fork", FullName: "acme/tool-fork", Fork: true},
+		{Name: "bare", FullName: "acme/bare", Topics: []string{"CLI"}},
+		{Name: "site", FullName: "acme/site", Topics: []string{"web"}},
+	}
+
+	var srv *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("/orgs/acme/repos", func(w http.ResponseWriter, r *http.Request) {
+		page := repos[:2]
+		if r.URL.Query().Get("page") == "2" {
+			page = repos[2:]
+		} else {
+			w.Header().Set("Link", fmt.Sprintf(`<%s/orgs/acme/repos?page=2>; rel="next"`, srv.URL))
+		}
+		for i := range page {
+			page[i].HTMLURL = "https://github.com/" + page[i].FullName
+		}
+		_ = json.NewEncoder(w).Encode(page)
+	})
+	mux.HandleFunc("/repos/acme/", func(w http.ResponseWriter, r *http.Request) {
+		name, rest, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/repos/acme/"), "/")
+		switch {
+		case strings.HasPrefix(rest, "commits/"):
+			_, _ = w.Write([]byte(testSHA))
+		case strings.HasPrefix(rest, "zipball/") && name == "tool":
+			_, _ = w.Write(zipball(t, map[string]string{"SKILL.md": string(skill)}))
+		case strings.HasPrefix(rest, "zipball/"):
+			_, _ = w.Write(zipball(t, map[string]string{"README.md": "# bare"}))
+		case rest == "releases":
+			_, _ = w.Write([]byte("[]"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if remaining != nil {
+			w.Header().Set("X-RateLimit-Remaining", fmt.Sprint(remaining.Add(-1)))
+			w.Header().Set("X-RateLimit-Reset", fmt.Sprint(time.Now().Add(time.Hour).Unix()))
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	return srv
+}
+
+func TestScanOwner(t *testing.T) {
+	srv := orgServer(t, nil)
+	defer srv.Close()
+	client := &gitHubClient{baseURL: srv.URL, httpClient: srv.Client()}
+
+	report, err := scanOwner(client, newRateGate(2, 0), "github.com", "acme", ScanOptions{Jobs: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ranked []string
+	for _, e := range report.Repos {
+		ranked = append(ranked, fmt.Sprintf("%s:%s", e.Repo, e.Status))
+	}
+	// Equal scores rank by name.
+	if got := strings.Join(ranked, " "); got != "acme/tool:partial acme/bare:fail acme/site:fail" {
+		t.Errorf("ranking = %s", got)
+	}
+	if report.Repos[0].Score <= report.Repos[1].Score {
+		t.Errorf("scores %d, %d are not ranked", report.Repos[0].Score, report.Repos[1].Score)
+	}
+	if report.Repos[0].URL != "https://github.com/acme/tool" {
+		t.Errorf("url = %q", report.Repos[0].URL)
+	}
+	want := ScanSummary{Listed: 5, Archived: 1, Forks: 1, Scanned: 3, Adopted: 1, Partial: 1, Fail: 2}
+	got := report.Summary
+	got.Score = 0
+	if got != want {
+		t.Errorf("summary = %+v, want %+v", got, want)
+	}
+	if report.Status != OverallFail {
+		t.Errorf("status = %q", report.Status)
+	}
+	if len(report.Checks) == 0 || report.Checks[0].Fail != 2 {
+		t.Errorf("checks = %+v", report.Checks)
+	}
+}
+
+func TestScanOwner_Filters(t *testing.T) {
+	srv := orgServer(t, nil)
+	defer srv.Close()
+	client := &gitHubClient{baseURL: srv.URL, httpClient: srv.Client()}
+
+	opts := ScanOptions{Jobs: 1, IncludeArchived: true, IncludeForks: true, Topics: []string{"cli"}}
+	report, err := scanOwner(client, newRateGate(1, 0), "github.com", "acme", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range report.Repos {
+		names = append(names, e.Repo)
+	}
+	// Topics match case-insensitively; the fork has none.
+	if got := strings.Join(names, " "); got != "acme/tool acme/bare acme/old" {
+		t.Errorf("scanned %s", got)
+	}
+	if report.Summary.OffTopic != 2 {
+		t.Errorf("off topic = %d, want 2", report.Summary.OffTopic)
+	}
+}
+
+func TestScanOwner_UserFallback(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/users/someone/repos" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+	client := &gitHubClient{baseURL: srv.URL, httpClient: srv.Client()}
+
+	report, err := scanOwner(client, newRateGate(1, 0), "github.com", "someone", ScanOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Summary.Listed != 0 || report.Status != OverallPass {
+		t.Errorf("report = %+v", report)
+	}
+	if _, err := scanOwner(client, newRateGate(1, 0), "github.com", "nobody", ScanOptions{}); err == nil ||
+		!strings.Contains(err.Error(), "no GitHub organization or user nobody") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestListRepos_PageLimit(t *testing.T) {
+	// Every page links to another.
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Link", fmt.Sprintf(`<%s/orgs/huge/repos?page=next>; rel="next"`, srv.URL))
+		_, _ = w.Write([]byte(`[{"name":"r","full_name":"huge/r"}]`))
+	}))
+	defer srv.Close()
+	client := &gitHubClient{baseURL: srv.URL, httpClient: srv.Client()}
+
+	if _, err := client.ListRepos("huge"); err == nil || !strings.Contains(err.Error(), "limit of 100 pages") {
+		t.Errorf("err = %v, want the page limit rather than a partial list", err)
+	}
+}
+
+func TestScanOwner_RateLimit(t *testing.T) {
+	var remaining atomic.Int64
+	remaining.Store(5)
+	srv := orgServer(t, &remaining)
+	defer srv.Close()
+	gate := newRateGate(1, time.Minute)
+	client := &gitHubClient{baseURL: srv.URL, httpClient: gate.client(srv.Client())}
+
+	// The reset is an hour away, beyond MaxWait: repos fail fast rather
+	// than spending the last requests.
+	report, err := scanOwner(client, gate, "github.com", "acme", ScanOptions{Jobs: 1, MaxWait: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Summary.Error != 3 {
+		t.Fatalf("errors = %d, want 3", report.Summary.Error)
+	}
+	if !strings.Contains(report.Repos[0].Error, "rate limit exceeded; resets at") {
+		t.Errorf("error = %q", report.Repos[0].Error)
+	}
+}
+
+func TestRateGate_Wait(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	var slept time.Duration
+	g := newRateGate(1, 5*time.Minute)
+	g.now = func() time.Time { return now }
+	g.sleep = func(d time.Duration) { slept += d }
+
+	h := http.Header{}
+	h.Set("X-RateLimit-Remaining", "3")
+	h.Set("X-RateLimit-Reset", fmt.Sprint(now.Add(2*time.Minute).Unix()))
+	g.observe(h)
+	if err := g.wait("api.github.com", true); err != nil || slept != 2*time.Minute {
+		t.Fatalf("wait = %v after sleeping %v, want 2m", err, slept)
+	}
+	// Another resource's limit is not the one validation spends.
+	h.Set("X-RateLimit-Resource", "search")
+	h.Set("X-RateLimit-Remaining", "0")
+	g.observe(h)
+	if err := g.wait("api.github.com", true); err != nil || slept != 2*time.Minute {
+		t.Fatalf("search limit paced the scan: %v, slept %v", err, slept)
+	}
+
+	// A secondary limit without Retry-After waits a minute.
+	g.limited(&httpclient.RateLimitError{Host: "api.github.com"})
+	if err := g.wait("api.github.com", true); err != nil || slept != 3*time.Minute {
+		t.Fatalf("wait = %v after sleeping %v, want 3m", err, slept)
+	}
+
+	g.limited(&httpclient.RateLimitError{Host: "api.github.com", Reset: now.Add(time.Hour)})
+	if err := g.wait("api.github.com", false); err == nil || !strings.Contains(err.Error(), "set a token") {
+		t.Errorf("err = %v, want a rate limit error", err)
+	}
+}
+
+func TestForges_ParseOwnerURL(t *testing.T) {
+	f := Forges{GitHubHosts: []string{"ghe.example.com"}}
+	tests := []struct {
+		in, host, owner string
+		ok              bool
+	}{
+		{"github.com/acme", "github.com", "acme", true},
+		{"https://github.com/acme/", "github.com", "acme", true},
+		{"https://GHE.example.com/platform", "ghe.example.com", "platform", true},
+		{"github.com/acme/tool", "", "", false},
+		{"gitlab.com/acme", "", "", false},
+		{"acme", "", "", false},
+	}
+	for _, tt := range tests {
+		host, owner, ok := f.ParseOwnerURL(tt.in)
+		if host != tt.host || owner != tt.owner || ok != tt.ok {
+			t.Errorf("ParseOwnerURL(%q) = %q, %q, %v", tt.in, host, owner, ok)
+		}
+	}
+}
+
+func TestScore(t *testing.T) {
+	r := &ValidationResult{Checks: []CheckResult{pass("a", ""), pass("b", ""), warn("c", ""), fail("d", "")}}
+	if got := Score(r); got != 63 {
+		t.Errorf("Score = %d, want 63", got)
+	}
+	if got := Score(nil); got != 0 {
+		t.Errorf("Score(nil) = %d", got)
+	}
+}
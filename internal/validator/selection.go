@@ -0,0 +1,79 @@
+package validator
+
+import "fmt"
+
+// Release selectors for Options.Release. Any other value names a tag.
+const (
+	// ReleaseLatest is the newest release that is neither a draft nor a
+	// prerelease, as GitHub's "latest" badge shows.
+	ReleaseLatest = "latest"
+	// ReleaseAny is the newest release that is not a draft.
+	ReleaseAny = "any"
+)
+
+// selectRelease finds the release under test. Drafts are only chosen by
+// tag, since they are unpublished and visible only to maintainers. When
+// nothing matches it returns nil and the reason.
+func selectRelease(client Forge, owner, repo, selector string) (*Release, string, error) {
+	switch selector {
+	case "", ReleaseLatest, ReleaseAny:
+		return selectNewest(client, owner, repo, selector == ReleaseAny)
+	}
+
+	rel, err := client.ReleaseByTag(owner, repo, selector)
+	if err != nil || rel != nil {
+		return rel, "", err
+	}
+	// The tags endpoint does not return drafts.
+	var draft *Release
+	err = client.EachRelease(owner, repo, func(r *Release) bool {
+		if r.TagName == selector {
+			draft = r
+			return true
+		}
+		return false
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if draft == nil {
+		return nil, fmt.Sprintf("no release tagged %s", selector), nil
+	}
+	return draft, "", nil
+}
+
+func selectNewest(client Forge, owner, repo string, prereleases bool) (*Release, string, error) {
+	var found *Release
+	seen := 0
+	err := client.EachRelease(owner, repo, func(r *Release) bool {
+		seen++
+		if r.Draft || (r.Prerelease && !prereleases) {
+			return false
+		}
+		found = r
+		return true
+	})
+	switch {
+	case err != nil:
+		return nil, "", err
+	case found != nil:
+		return found, "", nil
+	case seen == 0:
+		return nil, "no releases found", nil
+	case prereleases:
+		return nil, "no release other than drafts found", nil
+	}
+	return nil, "no release other than drafts and prereleases found", nil
+}
+
+// releaseLabel names a release in check messages, marking drafts and
+// prereleases.
+func releaseLabel(r *Release) string {
+	switch {
+	case r.Draft:
+		return r.TagName + " (draft)"
+	case r.Prerelease:
+		return r.TagName + " (prerelease)"
+	}
+	return r.TagName
+}
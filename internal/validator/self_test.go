@@ -12,7 +12,7 @@ func TestSelfValidation(t *testing.T) {
 	_, file, _, _ := runtime.Caller(0)
 	repoRoot := filepath.Join(filepath.Dir(file), "..", "..")
 
-	result, err := Validate(repoRoot)
+	result, err := ValidateWithOptions(repoRoot, Options{Offline: true})
 	if err != nil {
 		t.Fatalf("self-validation error: %v", err)
 	}
@@ -30,8 +30,8 @@ func TestSelfValidation(t *testing.T) {
 		t.Errorf("expected 11 checks, got %d", result.Summary.Total)
 	}
 
-	// binary-release is expected to warn for local validation.
-	expectedPass := 10
+	// The repo's .goreleaser.yml satisfies the binary-release check.
+	expectedPass := 11
 	if result.Summary.Pass != expectedPass {
 		t.Errorf("expected %d pass, got %d", expectedPass, result.Summary.Pass)
 	}
@@ -0,0 +1,242 @@
+package validator
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Sources of a Target's files other than a forge.
+const (
+	ForgeLocal   = "local"
+	ForgeArchive = "archive"
+	ForgeMemory  = "memory"
+)
+
+// Target is a repository to validate: its files and where they come from.
+// Checks only read files through FS, so any source of files can be
+// validated.
+type Target interface {
+	// FS returns the repo's files, rooted at the repo root.
+	FS() fs.FS
+	// Meta describes where the files come from.
+	Meta() TargetMeta
+}
+
+// TargetMeta describes a Target.
+type TargetMeta struct {
+	// Path identifies the target in results: a directory, archive or URL.
+	Path string
+	// Name is the project name release tooling falls back to, usually the
+	// directory or repo name.
+	Name string
+	// Forge is ForgeLocal, ForgeArchive, ForgeMemory or the name of the
+	// forge a remote repo was downloaded from.
+	Forge string
+	// Origin is the forge repo whose releases are inspected, or nil.
+	Origin *RemoteRepo
+	// Ref and Commit are the git ref requested and the commit read, when
+	// known.
+	Ref    string
+	Commit string
+}
+
+type fsTarget struct {
+	fsys fs.FS
+	meta TargetMeta
+}
+
+func (t *fsTarget) FS() fs.FS        { return t.fsys }
+func (t *fsTarget) Meta() TargetMeta { return t.meta }
+
+// LocalTarget is a directory on disk. Its origin is read from .git/config
+// when it is a checkout of a repo on a supported forge.
+func LocalTarget(dir string) (Target, error) {
+	return localTarget(dir, Forges{})
+}
+
+// localTarget is LocalTarget with forges recognizing the origin.
+func localTarget(dir string, forges Forges) (Target, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("resolving path: %w", err)
+	}
+	// Without a forge origin the release checks read the local config.
+	origin, _ := forges.remoteOrigin(dir)
+	return &fsTarget{
+		fsys: os.DirFS(dir),
+		meta: TargetMeta{Path: dir, Name: filepath.Base(dir), Forge: ForgeLocal, Origin: origin},
+	}, nil
+}
+
+// GitHubTarget downloads a GitHub repo at ref, or at its default branch
+// when ref is empty.
+func GitHubTarget(owner, repo, ref string) (Target, error) {
+	return openRemote(newGitHubClient(defaultHTTPClient), gitHubRepo(owner, repo, ref))
+}
+
+// RemoteTarget downloads a repo from its forge at r.Ref, or at its default
+// branch when r.Ref is empty.
+func RemoteTarget(r *RemoteRepo) (Target, error) {
+	client, err := NewForge(r)
+	if err != nil {
+		return nil, err
+	}
+	return openRemote(client, r)
+}
+
+// openRemote is the testable core of RemoteTarget. The repo is downloaded
+// at the commit r.Ref resolves to, so every check sees the same tree even
+// if the branch moves meanwhile.
+func openRemote(client Forge, r *RemoteRepo) (Target, error) {
+	meta := TargetMeta{
+		Path:   r.String(),
+		Name:   r.Repo,
+		Forge:  r.Forge,
+		Origin: r,
+		Ref:    r.Ref,
+	}
+	sha, err := client.ResolveRef(r.Owner, r.Repo, r.Ref)
+	switch {
+	case err == nil:
+		meta.Commit = sha
+	case r.Ref != "":
+		return nil, err
+	}
+	// An unresolved default branch is read by name; the commit stays unknown.
+	readAt := meta.Commit
+	if readAt == "" {
+		readAt = r.Ref
+	}
+
+	fsys, err := client.FetchArchive(r.Owner, r.Repo, readAt)
+	if err != nil {
+		return nil, err
+	}
+	return &fsTarget{fsys: fsys, meta: meta}, nil
+}
+
+// MemoryTarget wraps files already in memory, such as an fstest.MapFS.
+func MemoryTarget(name string, fsys fs.FS) Target {
+	return &fsTarget{fsys: fsys, meta: TargetMeta{Path: name, Name: name, Forge: ForgeMemory}}
+}
+
+// archiveExts are the archive formats ArchiveTarget reads.
+var archiveExts = []string{".tar.gz", ".tgz", ".zip"}
+
+// IsArchive reports whether path names an archive ArchiveTarget reads.
+func IsArchive(name string) bool {
+	_, ok := trimAnySuffix(name, archiveExts)
+	return ok
+}
+
+// ArchiveTarget reads a .tar.gz, .tgz or .zip file into memory. A single
+// top-level directory, as in GitHub and `git archive --prefix` archives,
+// becomes the repo root.
+func ArchiveTarget(file string) (Target, error) {
+	base, ok := trimAnySuffix(filepath.Base(file), archiveExts)
+	if !ok {
+		return nil, fmt.Errorf("%s: not a .tar.gz, .tgz or .zip archive", file)
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("opening archive: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	data, err := io.ReadAll(io.LimitReader(f, maxArchiveSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading archive: %w", err)
+	}
+	if len(data) > maxArchiveSize {
+		return nil, fmt.Errorf("%s exceeds %d bytes", file, maxArchiveSize)
+	}
+
+	var fsys fs.FS
+	if strings.HasSuffix(strings.ToLower(file), ".zip") {
+		fsys, err = zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	} else {
+		fsys, err = readTarGz(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", file, err)
+	}
+	if fsys, err = archiveRoot(fsys); err != nil {
+		return nil, err
+	}
+	if abs, err := filepath.Abs(file); err == nil {
+		file = abs
+	}
+	return &fsTarget{fsys: fsys, meta: TargetMeta{Path: file, Name: base, Forge: ForgeArchive}}, nil
+}
+
+// readTarGz loads the regular files and directories of a gzipped tarball
+// into memory. They are repacked as an uncompressed zip, whose reader is
+// already an fs.FS.
+func readTarGz(data []byte) (fs.FS, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	tr := tar.NewReader(io.LimitReader(gz, maxArchiveSize))
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		name := path.Clean(strings.TrimPrefix(hdr.Name, "./"))
+		if !fs.ValidPath(name) || name == "." {
+			continue
+		}
+		zh := &zip.FileHeader{Name: name, Method: zip.Store, Modified: hdr.ModTime}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			zh.Name += "/"
+			zh.SetMode(fs.ModeDir | 0o755)
+		case tar.TypeReg:
+			zh.SetMode(fs.FileMode(hdr.Mode).Perm())
+		default:
+			continue
+		}
+		w, err := zw.CreateHeader(zh)
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag == tar.TypeReg {
+			if _, err := io.Copy(w, tr); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+}
+
+// archiveRoot descends into the single top-level directory archives
+// commonly wrap a repo in ("owner-repo-<sha>/").
+func archiveRoot(fsys fs.FS) (fs.FS, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("reading archive: %w", err)
+	}
+	if len(entries) != 1 || !entries[0].IsDir() {
+		return fsys, nil
+	}
+	return fs.Sub(fsys, entries[0].Name())
+}
@@ -0,0 +1,161 @@
+package validator
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// targetFiles is a repo with a valid SKILL.md and a GoReleaser config.
+func targetFiles(t *testing.T) map[string]string {
+	t.Helper()
+	content, err := readFile(testdataPath("valid-skill.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]string{
+		"SKILL.md":        string(content),
+		".goreleaser.yml": "builds:\n  - goos: [linux, darwin]\n    goarch: [amd64, arm64]\n",
+	}
+}
+
+func tarGz(t *testing.T, prefix string, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	if prefix != "" {
+		if err := tw.WriteHeader(&tar.Header{Name: prefix, Typeflag: tar.TypeDir, Mode: 0o755}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, data := range files {
+		hdr := &tar.Header{Name: prefix + name, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(data))}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// TestTargets_SameChecks validates the same files as a directory, an
+// in-memory MapFS, a tarball and a zip archive.
+func TestTargets_SameChecks(t *testing.T) {
+	files := targetFiles(t)
+
+	dir := t.TempDir()
+	mapFS := fstest.MapFS{}
+	for name, data := range files {
+		if err := writeFile(filepath.Join(dir, name), []byte(data)); err != nil {
+			t.Fatal(err)
+		}
+		mapFS[name] = &fstest.MapFile{Data: []byte(data)}
+	}
+	archives := t.TempDir()
+	tgz := filepath.Join(archives, "repo-1.0.0.tar.gz")
+	if err := writeFile(tgz, tarGz(t, "repo-1.0.0/", files)); err != nil {
+		t.Fatal(err)
+	}
+	zipFile := filepath.Join(archives, "repo.zip")
+	if err := writeFile(zipFile, zipball(t, files)); err != nil {
+		t.Fatal(err)
+	}
+
+	local, err := LocalTarget(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	targets := []Target{local, MemoryTarget("mem", mapFS)}
+	for _, f := range []string{tgz, zipFile} {
+		a, err := ArchiveTarget(f)
+		if err != nil {
+			t.Fatalf("%s: %v", f, err)
+		}
+		targets = append(targets, a)
+	}
+
+	var want *ValidationResult
+	for _, target := range targets {
+		got, err := ValidateTarget(target, Options{Offline: true})
+		if err != nil {
+			t.Fatalf("%s: %v", target.Meta().Forge, err)
+		}
+		if got.Path != target.Meta().Path {
+			t.Errorf("path = %q, want %q", got.Path, target.Meta().Path)
+		}
+		if want == nil {
+			want = got
+			if got.Status != OverallPass {
+				t.Fatalf("local status = %s: %+v", got.Status, got.Checks)
+			}
+			continue
+		}
+		if len(got.Checks) != len(want.Checks) {
+			t.Fatalf("%s: %d checks, want %d", target.Meta().Forge, len(got.Checks), len(want.Checks))
+		}
+		for i := range got.Checks {
+			if !reflect.DeepEqual(got.Checks[i], want.Checks[i]) {
+				t.Errorf("%s: check %d = %+v, want %+v", target.Meta().Forge, i, got.Checks[i], want.Checks[i])
+			}
+		}
+	}
+}
+
+func TestArchiveTarget(t *testing.T) {
+	dir := t.TempDir()
+	flat := filepath.Join(dir, "flat.tgz")
+	if err := writeFile(flat, tarGz(t, "", map[string]string{"SKILL.md": "# x\n", "docs/a.md": "a"})); err != nil {
+		t.Fatal(err)
+	}
+	target, err := ArchiveTarget(flat)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m := target.Meta(); m.Forge != ForgeArchive || m.Name != "flat" {
+		t.Errorf("meta = %+v", m)
+	}
+	for _, name := range []string{"SKILL.md", "docs/a.md"} {
+		if _, err := target.FS().Open(name); err != nil {
+			t.Errorf("open %s: %v", name, err)
+		}
+	}
+
+	bad := filepath.Join(dir, "bad.tar.gz")
+	if err := writeFile(bad, []byte("not gzip")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ArchiveTarget(bad); err == nil {
+		t.Error("expected an error for a corrupt archive")
+	}
+	if _, err := ArchiveTarget(filepath.Join(dir, "repo.rar")); err == nil || !strings.Contains(err.Error(), "not a .tar.gz") {
+		t.Errorf("expected unsupported format error, got %v", err)
+	}
+}
+
+func TestValidateWithOptions_Archive(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "repo.tar.gz")
+	if err := writeFile(file, tarGz(t, "repo/", targetFiles(t))); err != nil {
+		t.Fatal(err)
+	}
+	result, err := ValidateWithOptions(file, Options{Offline: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Status != OverallPass {
+		t.Errorf("status = %s, want pass: %+v", result.Status, result.Checks)
+	}
+}
@@ -1,6 +1,11 @@
 package validator
 
-import "os"
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"testing"
+)
 
 func readFile(path string) ([]byte, error) {
 	return os.ReadFile(path)
@@ -9,3 +14,27 @@ func readFile(path string) ([]byte, error) {
 func writeFile(path string, data []byte) error {
 	return os.WriteFile(path, data, 0o644)
 }
+
+// zipball builds a repo archive the way GitHub's zipball endpoint serves
+// it, with every file under one top-level directory.
+func zipball(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	if _, err := zw.Create("owner-repo-0123456/"); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range files {
+		w, err := zw.Create("owner-repo-0123456/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
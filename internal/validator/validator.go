@@ -1,114 +1,306 @@
 package validator
 
 import (
+	"errors"
 	"fmt"
-	"path/filepath"
+	"io/fs"
+	"path"
+	"strings"
 
+	"github.com/ppiankov/ancc/internal/drift"
+	"github.com/ppiankov/ancc/internal/release"
 	"github.com/ppiankov/ancc/internal/skillmd"
 )
 
+// Options controls how a repo is validated.
+type Options struct {
+	// Offline disables network access. GitHub URLs cannot be validated and
+	// local checkouts are not matched to their GitHub origin.
+	Offline bool
+	// Platforms is the minimum os/arch matrix a binary release must ship.
+	// Empty means release.DefaultPlatforms.
+	Platforms []release.Target
+	// Integrity sets the severity of each integrity check, keyed by check
+	// name: warn (the default), fail or off.
+	Integrity map[string]string
+	// Release selects the GitHub release under test: ReleaseLatest, ReleaseAny
+	// or a tag. Empty means the release tagged Ref, if there is one, else
+	// ReleaseLatest.
+	Release string
+	// Ref is the branch, tag or commit of a remote repo to validate. It
+	// must agree with a ref given in the URL.
+	Ref string
+	// Forges recognizes GitHub Enterprise hosts in URLs and origin remotes.
+	Forges Forges
+	// MaxSkillSize is the largest SKILL.md read, in bytes. Zero means
+	// skillmd.DefaultLimits.MaxBytes.
+	MaxSkillSize int64
+	// Tools lists the directories of a monorepo's tools, each with its own
+	// SKILL.md. Empty means the tools in the target's .ancc.yml, else every
+	// directory holding a SKILL.md.
+	Tools []string
+}
+
+// skillLimits bounds the SKILL.md of an untrusted repo.
+func (o Options) skillLimits() skillmd.Limits {
+	l := skillmd.DefaultLimits
+	if o.MaxSkillSize > 0 {
+		l.MaxBytes = o.MaxSkillSize
+	}
+	return l
+}
+
+func (o Options) platforms() []release.Target {
+	if len(o.Platforms) == 0 {
+		return release.DefaultPlatforms
+	}
+	return o.Platforms
+}
+
 // Validate runs all checks against the repo at path and returns results.
 func Validate(path string) (*ValidationResult, error) {
-	// Check if path is a GitHub URL.
-	if gh := ParseGitHubURL(path); gh != nil {
-		return ValidateGitHub(gh.Owner, gh.Repo)
+	return ValidateWithOptions(path, Options{})
+}
+
+// ValidateWithOptions runs all checks against the repo at path: a repo URL
+// on GitHub, GitLab, Gitea/Forgejo or Bitbucket, a .tar.gz or .zip
+// archive, or a directory. When path is a local checkout whose origin is on
+// one of those forges and network use is allowed, the release checks look
+// at its published releases, as validating the repo URL would.
+func ValidateWithOptions(path string, opts Options) (*ValidationResult, error) {
+	// Check if path is a repo URL.
+	if r := opts.Forges.ParseRepoURL(path); r != nil {
+		if opts.Offline {
+			return nil, fmt.Errorf("cannot validate %s offline", path)
+		}
+		if r.Ref != "" && opts.Ref != "" && r.Ref != opts.Ref {
+			return nil, fmt.Errorf("ref %q conflicts with %q in %s", opts.Ref, r.Ref, path)
+		}
+		if opts.Ref == "" {
+			opts.Ref = r.Ref
+		}
+		client, err := opts.Forges.NewForge(r)
+		if err != nil {
+			return nil, err
+		}
+		return validateRemote(client, r, opts)
+	}
+	if opts.Ref != "" {
+		return nil, fmt.Errorf("a ref applies only to remote repos; check out %s locally instead", opts.Ref)
 	}
 
-	path, err := filepath.Abs(path)
+	var t Target
+	var err error
+	if IsArchive(path) {
+		t, err = ArchiveTarget(path)
+	} else {
+		t, err = localTarget(path, opts.Forges)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("resolving path: %w", err)
+		return nil, err
 	}
+	return ValidateTarget(t, opts)
+}
 
-	result := &ValidationResult{Path: path}
+// ValidateTarget runs all checks against t. Unless opts.Offline is set,
+// the release checks inspect the published releases of t's origin.
+func ValidateTarget(t Target, opts Options) (*ValidationResult, error) {
+	var client Forge
+	if origin := t.Meta().Origin; origin != nil && !opts.Offline {
+		// An unsupported forge leaves the release checks to the local config.
+		client, _ = opts.Forges.NewForge(origin)
+	}
+	return validateTarget(t, client, opts)
+}
 
-	// Check 1: SKILL.md exists (filesystem check).
-	existsResult := checkSkillMDExists(path)
-	result.Checks = append(result.Checks, existsResult)
+// ValidateGitHub runs all checks against a GitHub repo.
+func ValidateGitHub(owner, repo string) (*ValidationResult, error) {
+	client := newGitHubClient(defaultHTTPClient)
+	return validateGitHubWithClient(client, owner, repo, Options{})
+}
 
-	// If SKILL.md doesn't exist, remaining checks fail.
-	if existsResult.Status == StatusFail {
-		result.Checks = append(result.Checks,
-			fail(CheckSkillMDInstall, "SKILL.md not found"),
-			fail(CheckSkillMDCommands, "SKILL.md not found"),
-			fail(CheckSkillMDFlags, "SKILL.md not found"),
-			fail(CheckSkillMDJSON, "SKILL.md not found"),
-			fail(CheckSkillMDExitCodes, "SKILL.md not found"),
-			fail(CheckSkillMDNotDo, "SKILL.md not found"),
-			fail(CheckSkillMDParsing, "SKILL.md not found"),
-			fail(CheckHasInitCommand, "SKILL.md not found"),
-			warn(CheckHasDoctorCommand, "SKILL.md not found"),
-			checkBinaryRelease(""),
-		)
-		computeSummary(result)
-		return result, nil
+// validateGitHubWithClient is the testable core of ValidateGitHub.
+func validateGitHubWithClient(client Forge, owner, repo string, opts Options) (*ValidationResult, error) {
+	return validateRemote(client, gitHubRepo(owner, repo, opts.Ref), opts)
+}
+
+// validateRemote validates a repo on a forge at opts.Ref. The checks run
+// exactly as for a local checkout.
+func validateRemote(client Forge, r *RemoteRepo, opts Options) (*ValidationResult, error) {
+	at := *r
+	at.Ref = opts.Ref
+	t, err := openRemote(client, &at)
+	if err != nil {
+		return nil, err
 	}
+	return validateTarget(t, client, opts)
+}
 
-	// Parse SKILL.md.
-	sf, err := skillmd.ParseFile(filepath.Join(path, "SKILL.md"))
+// validateTarget is the testable core of ValidateTarget. A nil client keeps
+// validation offline.
+func validateTarget(t Target, client Forge, opts Options) (*ValidationResult, error) {
+	meta := t.Meta()
+	fsys := t.FS()
+	// Release selection follows the ref the files were read at.
+	opts.Ref = meta.Ref
+	result := &ValidationResult{Path: meta.Path, Ref: meta.Ref, Commit: meta.Commit}
+
+	dirs, err := toolDirs(fsys, opts.Tools)
 	if err != nil {
-		return nil, fmt.Errorf("parsing SKILL.md: %w", err)
+		return nil, err
+	}
+	if len(dirs) == 0 {
+		dirs = []string{"."}
+	}
+	var tools []toolChecks
+	for _, dir := range dirs {
+		tc, err := skillChecks(t, client, dir, opts)
+		if err != nil {
+			return nil, err
+		}
+		tools = append(tools, tc...)
+	}
+	checks, tag := checkReleases(t, client, opts)
+	result.Release = tag
+	// Release checks have no SKILL.md line of their own; they point at the
+	// release config, else the first SKILL.md, so every finding has a place
+	// to be shown.
+	loc := releaseLocation(fsys, path.Join(tools[0].dir, "SKILL.md"))
+	for i := range checks {
+		if len(checks[i].Locations) == 0 {
+			checks[i].Locations = []Location{loc}
+		}
 	}
 
-	// Run content checks.
-	result.Checks = append(result.Checks,
-		checkInstall(sf),
-		checkCommands(sf),
-		checkFlags(sf),
-		checkJSONOutput(sf),
-		checkExitCodes(sf),
-		checkNotDo(sf),
-		checkParsing(sf),
-		checkInitCommand(sf),
-		checkDoctorCommand(sf),
-		checkBinaryRelease(""),
-	)
+	// A single tool at the root keeps the flat report.
+	if len(tools) == 1 && tools[0].dir == "." && tools[0].name == "" {
+		result.Checks = append(append(tools[0].skill, checks...), tools[0].after...)
+		computeSummary(result)
+		return result, nil
+	}
 
+	// Several tools, from the SKILL.md files of a monorepo or the tools of
+	// one SKILL.md, are each reported on their own; the repo-level release
+	// checks run once.
+	for _, tc := range tools {
+		tool := ToolResult{Path: tc.dir, Name: tc.name, Checks: append(tc.skill, tc.after...)}
+		tool.Summary, tool.Status = summarize(tool.Checks)
+		result.Tools = append(result.Tools, tool)
+	}
+	result.Checks = checks
 	computeSummary(result)
 	return result, nil
 }
 
-// ValidateGitHub runs all checks against a GitHub repo.
-func ValidateGitHub(owner, repo string) (*ValidationResult, error) {
-	client := newGitHubClient()
-	return validateGitHubWithClient(client, owner, repo)
+// toolChecks holds the checks of one tool: the SKILL.md checks, which
+// precede the release checks in a report, and the checks that follow them.
+type toolChecks struct {
+	dir, name    string
+	skill, after []CheckResult
 }
 
-// validateGitHubWithClient is the testable core of ValidateGitHub.
-func validateGitHubWithClient(client *gitHubClient, owner, repo string) (*ValidationResult, error) {
-	ref := fmt.Sprintf("github.com/%s/%s", owner, repo)
-	result := &ValidationResult{Path: ref}
+// skillChecks runs the checks of the SKILL.md in dir against the subtree it
+// documents, once per tool when it documents several.
+func skillChecks(t Target, client Forge, dir string, opts Options) ([]toolChecks, error) {
+	fsys := t.FS()
+	if dir != "." {
+		var err error
+		if fsys, err = fs.Sub(fsys, dir); err != nil {
+			return nil, err
+		}
+	}
 
-	// Fetch SKILL.md.
-	content, err := client.FetchSkillMD(owner, repo)
-	if err != nil {
-		// SKILL.md not found — fail all content checks.
-		result.Checks = append(result.Checks,
-			fail(CheckSkillMDExists, fmt.Sprintf("SKILL.md not found in %s/%s", owner, repo)),
-			fail(CheckSkillMDInstall, "SKILL.md not found"),
-			fail(CheckSkillMDCommands, "SKILL.md not found"),
-			fail(CheckSkillMDFlags, "SKILL.md not found"),
-			fail(CheckSkillMDJSON, "SKILL.md not found"),
-			fail(CheckSkillMDExitCodes, "SKILL.md not found"),
-			fail(CheckSkillMDNotDo, "SKILL.md not found"),
-			fail(CheckSkillMDParsing, "SKILL.md not found"),
-			fail(CheckHasInitCommand, "SKILL.md not found"),
-			warn(CheckHasDoctorCommand, "SKILL.md not found"),
-		)
-		// Still check binary releases.
-		result.Checks = append(result.Checks, checkBinaryReleaseGitHub(client, owner, repo))
-		computeSummary(result)
-		return result, nil
+	// Check 1: SKILL.md exists.
+	existsResult := checkSkillMDExists(fsys, dir)
+
+	// Parse SKILL.md. Content too large, binary or not UTF-8 fails the
+	// check rather than validation.
+	var sf *skillmd.SkillFile
+	reason := "SKILL.md not found"
+	if existsResult.Status == StatusPass {
+		var err error
+		sf, err = skillmd.ParseFS(fsys, "SKILL.md", opts.skillLimits())
+		if err != nil {
+			if !isSkillInputError(err) {
+				return nil, fmt.Errorf("reading %s: %w", path.Join(dir, "SKILL.md"), err)
+			}
+			reason = "SKILL.md unusable"
+			existsResult = at(fail(CheckSkillMDExists, fmt.Sprintf("%s: %v", reason, err)), 0)
+		}
 	}
 
-	result.Checks = append(result.Checks, pass(CheckSkillMDExists, "SKILL.md found in GitHub repo"))
+	// Without a usable SKILL.md, remaining checks fail.
+	if existsResult.Status == StatusFail {
+		tc := toolChecks{dir: dir, skill: []CheckResult{
+			existsResult,
+			at(fail(CheckSkillMDInstall, reason), 0),
+			at(fail(CheckSkillMDCommands, reason), 0),
+			at(fail(CheckSkillMDFlags, reason), 0),
+			at(fail(CheckSkillMDJSON, reason), 0),
+			at(fail(CheckSkillMDExitCodes, reason), 0),
+			at(fail(CheckSkillMDNotDo, reason), 0),
+			at(fail(CheckSkillMDParsing, reason), 0),
+			at(fail(CheckHasInitCommand, reason), 0),
+			at(warn(CheckHasDoctorCommand, reason), 0),
+		}}
+		tc.rebase()
+		return []toolChecks{tc}, nil
+	}
 
-	// Parse content.
-	sf, err := skillmd.Parse(content)
-	if err != nil {
-		return nil, fmt.Errorf("parsing SKILL.md: %w", err)
+	files := sf.ToolFiles()
+	if files == nil {
+		tc := contentChecks(t, client, existsResult, sf)
+		tc.dir = dir
+		// Drift needs the one command tree the sources implement, so it
+		// only runs for a SKILL.md documenting a single tool.
+		if c, ok := checkCommandDrift(fsys, sf); ok {
+			tc.after = append(tc.after, c)
+		}
+		tc.rebase()
+		return []toolChecks{tc}, nil
 	}
+	// Drift is not compared per tool, but a repo whose source it applies to
+	// still hears that it did not run.
+	analyzer := drift.Detect(fsys)
+	tools := make([]toolChecks, 0, len(files))
+	for _, f := range files {
+		tc := contentChecks(t, client, existsResult, f)
+		tc.dir, tc.name = dir, f.Name
+		if analyzer != nil {
+			tc.after = append(tc.after, at(warn(CheckCommandDrift,
+				fmt.Sprintf("%s: not checked for multi-tool SKILL.md", analyzer.Name())), f.Line))
+		}
+		tc.rebase()
+		tools = append(tools, tc)
+	}
+	return tools, nil
+}
+
+// rebase makes the locations of checks run on the subtree of a tool
+// relative to the repo root.
+func (tc *toolChecks) rebase() {
+	if tc.dir == "." {
+		return
+	}
+	for _, checks := range [][]CheckResult{tc.skill, tc.after} {
+		for i, c := range checks {
+			if len(c.Locations) == 0 {
+				continue
+			}
+			locs := make([]Location, len(c.Locations))
+			for j, l := range c.Locations {
+				locs[j] = Location{Path: path.Join(tc.dir, l.Path), Line: l.Line}
+			}
+			checks[i].Locations = locs
+		}
+	}
+}
 
-	result.Checks = append(result.Checks,
+// contentChecks runs the checks of one tool documented by a usable
+// SKILL.md.
+func contentChecks(t Target, client Forge, exists CheckResult, sf *skillmd.SkillFile) toolChecks {
+	tc := toolChecks{skill: []CheckResult{
+		exists,
 		checkInstall(sf),
 		checkCommands(sf),
 		checkFlags(sf),
@@ -118,45 +310,138 @@ func validateGitHubWithClient(client *gitHubClient, owner, repo string) (*Valida
 		checkParsing(sf),
 		checkInitCommand(sf),
 		checkDoctorCommand(sf),
-		checkBinaryReleaseGitHub(client, owner, repo),
-	)
+	}}
+	// Download paths are checked against the repo's releases and release
+	// config.
+	if c, ok := checkInstallAssets(t, client, sf); ok {
+		tc.after = append(tc.after, c)
+	}
+	return tc
+}
 
-	computeSummary(result)
-	return result, nil
+// isSkillInputError reports whether err rejects the SKILL.md content itself.
+func isSkillInputError(err error) bool {
+	var ie *skillmd.InputError
+	var le *skillmd.LimitError
+	return errors.As(err, &ie) || errors.As(err, &le)
+}
+
+// checkReleases inspects the published releases of the target's origin
+// when a forge client is available, so local and remote validation agree.
+// Without either, or when the forge cannot be reached, the release config in
+// the target decides the binary release check and the integrity checks,
+// which need published assets, are left out. It also returns the tag
+// inspected, if any.
+func checkReleases(t Target, client Forge, opts Options) ([]CheckResult, string) {
+	origin := t.Meta().Origin
+	if client == nil || origin == nil {
+		return []CheckResult{checkBinaryRelease(t.FS(), opts.platforms())}, ""
+	}
+	checks, rel, err := releaseChecksRemote(client, origin.Owner, origin.Repo, opts)
+	if err != nil {
+		r := checkBinaryRelease(t.FS(), opts.platforms())
+		r.Message += fmt.Sprintf(" (releases of %s unavailable: %v)", origin, err)
+		return []CheckResult{r}, ""
+	}
+	return checks, tagOf(rel)
+}
+
+// releaseLocation is where release findings are shown: the file defining
+// the release pipeline, else the top of skill.
+func releaseLocation(fsys fs.FS, skill string) Location {
+	if p, err := release.Detect(fsys); err == nil && p != nil {
+		// A Makefile pipeline names its target after the file.
+		file, _, _ := strings.Cut(p.Source, ":")
+		return Location{Path: file}
+	}
+	return Location{Path: skill, Line: 1}
 }
 
-// checkBinaryReleaseGitHub checks GitHub releases for binary assets.
-func checkBinaryReleaseGitHub(client *gitHubClient, owner, repo string) CheckResult {
-	hasBinary, err := client.HasBinaryRelease(owner, repo)
+func tagOf(r *Release) string {
+	if r == nil {
+		return ""
+	}
+	return r.TagName
+}
+
+func releaseChecksRemote(client Forge, owner, repo string, opts Options) ([]CheckResult, *Release, error) {
+	rel, reason, err := selectRelease(client, owner, repo, opts.releaseSelector(client, owner, repo))
 	if err != nil {
-		return warn(CheckHasBinaryRelease, fmt.Sprintf("could not check releases: %v", err))
+		return nil, nil, err
+	}
+	checks := []CheckResult{binaryReleaseResult(rel, reason, opts.platforms())}
+	return append(checks, integrityChecks(client, owner, repo, rel, opts)...), rel, nil
+}
+
+// releaseSelector returns opts.Release, defaulting to Ref when a release
+// is tagged with it, so validating a tag checks that tag's release.
+func (o Options) releaseSelector(client Forge, owner, repo string) string {
+	if o.Release != "" || o.Ref == "" {
+		return o.Release
 	}
-	if !hasBinary {
-		return warn(CheckHasBinaryRelease, "no binary release assets found")
+	if rel, err := client.ReleaseByTag(owner, repo, o.Ref); err == nil && rel != nil {
+		return o.Ref
 	}
-	return pass(CheckHasBinaryRelease, "binary release assets found")
+	return ReleaseLatest
+}
+
+// binaryReleaseResult checks that rel ships binaries for every required
+// platform. reason explains a nil rel.
+func binaryReleaseResult(rel *Release, reason string, required []release.Target) CheckResult {
+	if rel == nil {
+		return warn(CheckHasBinaryRelease, reason)
+	}
+
+	label := releaseLabel(rel)
+	targets, formats, unplaced := releaseMatrix(rel)
+	if len(targets) == 0 {
+		if unplaced > 0 {
+			return warn(CheckHasBinaryRelease, fmt.Sprintf("%s: %d binary asset(s), but none names its platform", label, unplaced))
+		}
+		return warn(CheckHasBinaryRelease, fmt.Sprintf("%s: no binary release assets found", label))
+	}
+
+	ships := release.JoinTargets(targets) + " as " + strings.Join(formats, ", ")
+	if missing := release.Missing(required, targets); len(missing) > 0 {
+		return warn(CheckHasBinaryRelease, fmt.Sprintf("%s: missing %s (ships %s)", label, release.JoinTargets(missing), ships))
+	}
+	return pass(CheckHasBinaryRelease, fmt.Sprintf("%s: ships %s", label, ships))
+}
+
+// NewResult builds a ValidationResult from checks produced outside
+// Validate, such as runtime probes, and computes its summary.
+func NewResult(path string, checks []CheckResult) *ValidationResult {
+	r := &ValidationResult{Path: path, Checks: checks}
+	computeSummary(r)
+	return r
 }
 
 // computeSummary tallies results and sets the overall status.
 func computeSummary(r *ValidationResult) {
-	for _, c := range r.Checks {
-		r.Summary.Total++
+	r.Summary, r.Status = summarize(r.AllChecks())
+}
+
+// summarize counts checks by status and derives the overall status.
+func summarize(checks []CheckResult) (Summary, string) {
+	var s Summary
+	for _, c := range checks {
+		s.Total++
 		switch c.Status {
 		case StatusPass:
-			r.Summary.Pass++
+			s.Pass++
 		case StatusFail:
-			r.Summary.Fail++
+			s.Fail++
 		case StatusWarn:
-			r.Summary.Warn++
+			s.Warn++
 		}
 	}
 
 	switch {
-	case r.Summary.Fail > 0:
-		r.Status = OverallFail
-	case r.Summary.Warn > 0:
-		r.Status = OverallPartial
+	case s.Fail > 0:
+		return s, OverallFail
+	case s.Warn > 0:
+		return s, OverallPartial
 	default:
-		r.Status = OverallPass
+		return s, OverallPass
 	}
 }
@@ -1,10 +1,15 @@
 package validator
 
 import (
+	"os"
 	"path/filepath"
+	"reflect"
 	"runtime"
+	"strings"
 	"testing"
+	"testing/fstest"
 
+	"github.com/ppiankov/ancc/internal/release"
 	"github.com/ppiankov/ancc/internal/skillmd"
 )
 
@@ -28,14 +33,14 @@ func TestCheckSkillMDExists_Present(t *testing.T) {
 	// testdata dir doesn't have SKILL.md, but repo root does.
 	_, file, _, _ := runtime.Caller(0)
 	repoRoot := filepath.Join(filepath.Dir(file), "..", "..")
-	r := checkSkillMDExists(repoRoot)
+	r := checkSkillMDExists(os.DirFS(repoRoot), ".")
 	if r.Status != StatusPass {
 		t.Errorf("status = %q, want %q", r.Status, StatusPass)
 	}
 }
 
 func TestCheckSkillMDExists_Missing(t *testing.T) {
-	r := checkSkillMDExists(t.TempDir())
+	r := checkSkillMDExists(os.DirFS(t.TempDir()), ".")
 	if r.Status != StatusFail {
 		t.Errorf("status = %q, want %q", r.Status, StatusFail)
 	}
@@ -197,13 +202,83 @@ func TestCheckDoctorCommand_Missing(t *testing.T) {
 	}
 }
 
-func TestCheckBinaryRelease_Skipped(t *testing.T) {
-	r := checkBinaryRelease("/some/local/path")
+func TestCheckBinaryRelease_NoConfig(t *testing.T) {
+	r := checkBinaryRelease(fstest.MapFS{"go.mod": {}}, release.DefaultPlatforms)
 	if r.Status != StatusWarn {
 		t.Errorf("status = %q, want %q", r.Status, StatusWarn)
 	}
 }
 
+func TestCheckBinaryRelease_Goreleaser(t *testing.T) {
+	fsys := fstest.MapFS{".goreleaser.yml": {Data: []byte("builds:\n  - goos: [linux]\n    goarch: [amd64, arm64]\n")}}
+	linux := []release.Target{{OS: "linux", Arch: "amd64"}, {OS: "linux", Arch: "arm64"}}
+	r := checkBinaryRelease(fsys, linux)
+	if r.Status != StatusPass {
+		t.Fatalf("status = %q, want %q (%s)", r.Status, StatusPass, r.Message)
+	}
+	if !strings.Contains(r.Message, "linux/amd64, linux/arm64") {
+		t.Errorf("message = %q, want the platform matrix", r.Message)
+	}
+
+	r = checkBinaryRelease(fsys, release.DefaultPlatforms)
+	if r.Status != StatusWarn || !strings.Contains(r.Message, "missing darwin/amd64, darwin/arm64") {
+		t.Errorf("got %s %q, want a warning naming the missing darwin targets", r.Status, r.Message)
+	}
+}
+
+func TestCheckCommandDrift_NoAnalyzer(t *testing.T) {
+	sf := loadFixture(t, "valid-skill.md")
+	if _, ok := checkCommandDrift(fstest.MapFS{"go.mod": {}}, sf); ok {
+		t.Error("expected drift check to be skipped without a source analyzer")
+	}
+}
+
+func TestCheckCommandDrift_Python(t *testing.T) {
+	sf := loadFixture(t, "valid-skill.md")
+	src := `import argparse
+
+parser = argparse.ArgumentParser(prog="mytool")
+sub = parser.add_subparsers()
+run = sub.add_parser("run")
+run.add_argument("--format")
+run.add_argument("--verbose")
+check = sub.add_parser("check")
+check.add_argument("--format")
+init = sub.add_parser("init")
+doctor = sub.add_parser("doctor")
+doctor.add_argument("--format")
+`
+	fsys := fstest.MapFS{
+		"pyproject.toml":     {},
+		"mytool/__main__.py": {Data: []byte(src)},
+	}
+
+	r, ok := checkCommandDrift(fsys, sf)
+	if !ok {
+		t.Fatal("expected drift check to run for a Python repo")
+	}
+	if r.Status != StatusPass {
+		t.Errorf("status = %q, want %q; message: %s", r.Status, StatusPass, r.Message)
+	}
+
+	// Drop a documented flag from the source.
+	fsys["mytool/__main__.py"] = &fstest.MapFile{Data: []byte(strings.Replace(src, `run.add_argument("--verbose")`, "", 1))}
+	r, _ = checkCommandDrift(fsys, sf)
+	if r.Status != StatusFail {
+		t.Errorf("status = %q, want %q", r.Status, StatusFail)
+	}
+	if !strings.Contains(r.Message, "run --verbose") {
+		t.Errorf("message should name the missing flag, got %q", r.Message)
+	}
+
+	// An extra command is undocumented, which only warns.
+	fsys["mytool/__main__.py"] = &fstest.MapFile{Data: []byte(src + `sub.add_parser("secret")` + "\n")}
+	r, _ = checkCommandDrift(fsys, sf)
+	if r.Status != StatusWarn {
+		t.Errorf("status = %q, want %q; message: %s", r.Status, StatusWarn, r.Message)
+	}
+}
+
 // --- Orchestrator tests ---
 
 func TestValidate_ValidFixture(t *testing.T) {
@@ -235,10 +310,10 @@ func TestValidate_ValidFixture(t *testing.T) {
 	if result.Summary.Fail != 0 {
 		t.Errorf("fail = %d, want 0", result.Summary.Fail)
 	}
-	// binary-release and doctor are warn for valid fixture with doctor
-	// Actually valid-skill.md has doctor, so only binary-release warns.
+	// valid-skill.md has doctor, so only binary-release warns: the temp
+	// dir has no release config.
 	if result.Summary.Warn != 1 {
-		t.Errorf("warn = %d, want 1 (binary-release skipped)", result.Summary.Warn)
+		t.Errorf("warn = %d, want 1 (no release config)", result.Summary.Warn)
 	}
 	if result.Status != OverallPartial {
 		t.Errorf("status = %q, want %q", result.Status, OverallPartial)
@@ -260,6 +335,250 @@ func TestValidate_MissingSkillMD(t *testing.T) {
 	}
 }
 
+func TestValidateTarget_UnusableSkillMD(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		opts    Options
+		wantMsg string
+	}{
+		{"binary", "\x7fELF\x02\x01\x00\x00", Options{}, "binary content"},
+		{"invalid UTF-8", "# tool\n\xc3\x28", Options{}, "invalid UTF-8 at byte 7"},
+		{"oversized", "# tool\n" + strings.Repeat("x", 100), Options{MaxSkillSize: 64}, "exceeds the limit of 64"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := fstest.MapFS{"SKILL.md": {Data: []byte(tt.data)}}
+			result, err := ValidateTarget(MemoryTarget("mem", fsys), tt.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			exists := result.Checks[0]
+			if exists.Name != CheckSkillMDExists || exists.Status != StatusFail || !strings.Contains(exists.Message, tt.wantMsg) {
+				t.Errorf("%s = %+v, want a failure mentioning %q", CheckSkillMDExists, exists, tt.wantMsg)
+			}
+			if c := result.Checks[1]; c.Status != StatusFail || c.Message != "SKILL.md unusable" {
+				t.Errorf("%s = %+v, want unusable", c.Name, c)
+			}
+			if result.Summary.Total != 11 {
+				t.Errorf("total = %d, want 11", result.Summary.Total)
+			}
+		})
+	}
+}
+
+func TestValidateTarget_Monorepo(t *testing.T) {
+	valid, err := readFile(testdataPath("valid-skill.md"))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	fsys := fstest.MapFS{
+		"go.mod":                {},
+		"cmd/a/SKILL.md":        {Data: valid},
+		"cmd/a/pyproject.toml":  {Data: []byte("[project]\nname = \"mytool\"\n")},
+		"cmd/b/SKILL.md":        {Data: []byte("# b\n\nA tool with nothing documented.\n")},
+		"testdata/x/SKILL.md":   {},
+		"cmd/b/.goreleaser.yml": {},
+	}
+
+	result, err := ValidateTarget(MemoryTarget("mem", fsys), Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Tools) != 2 || result.Tools[0].Path != "cmd/a" || result.Tools[1].Path != "cmd/b" {
+		t.Fatalf("tools = %+v, want cmd/a and cmd/b", result.Tools)
+	}
+
+	// Drift is checked against the tool's own subtree.
+	a := result.Tools[0]
+	if last := a.Checks[len(a.Checks)-1]; last.Name != CheckCommandDrift {
+		t.Errorf("last check of cmd/a = %q, want %q", last.Name, CheckCommandDrift)
+	}
+	b := result.Tools[1]
+	if b.Status != OverallFail || len(b.Checks) != 10 {
+		t.Errorf("cmd/b = %s with %d checks, want fail with 10", b.Status, len(b.Checks))
+	}
+
+	// The release check runs once, at the repo root, which has no release config.
+	if len(result.Checks) != 1 || result.Checks[0].Name != CheckHasBinaryRelease || result.Checks[0].Status != StatusWarn {
+		t.Errorf("repo checks = %+v, want one warning %s", result.Checks, CheckHasBinaryRelease)
+	}
+	if result.Summary.Total != len(a.Checks)+len(b.Checks)+1 {
+		t.Errorf("total = %d, want every tool and repo check", result.Summary.Total)
+	}
+	if result.Status != OverallFail {
+		t.Errorf("status = %q, want %q", result.Status, OverallFail)
+	}
+	if all := result.AllChecks(); len(all) != result.Summary.Total || !reflect.DeepEqual(all[0], a.Checks[0]) {
+		t.Errorf("AllChecks = %d checks starting with %+v", len(all), all[0])
+	}
+}
+
+func TestValidateTarget_MonorepoMissingTool(t *testing.T) {
+	fsys := fstest.MapFS{"cmd/a/SKILL.md": {Data: []byte("# a\n")}}
+	result, err := ValidateTarget(MemoryTarget("mem", fsys), Options{Tools: []string{"cmd/a", "cmd/gone"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Tools) != 2 {
+		t.Fatalf("tools = %d, want 2", len(result.Tools))
+	}
+	if c := result.Tools[1].Checks[0]; c.Status != StatusFail || !strings.Contains(c.Message, "in cmd/gone") {
+		t.Errorf("%s = %+v, want a failure naming cmd/gone", c.Name, c)
+	}
+
+	if _, err := ValidateTarget(MemoryTarget("mem", fsys), Options{Tools: []string{"../x"}}); err == nil {
+		t.Error("want an error for a tool outside the repo")
+	}
+}
+
+func TestValidateTarget_MultiToolSkillMD(t *testing.T) {
+	data, err := readFile(testdataPath("multi-tool-skill.md"))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	fsys := fstest.MapFS{"SKILL.md": {Data: data}, "pyproject.toml": {}}
+	result, err := ValidateTarget(MemoryTarget("mem", fsys), Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Tools) != 3 {
+		t.Fatalf("tools = %+v, want foo, foo-agent and foo-migrate", result.Tools)
+	}
+
+	status := func(tool ToolResult, name string) string {
+		for _, c := range tool.Checks {
+			if c.Name == name {
+				return c.Status
+			}
+		}
+		return ""
+	}
+	foo, agent, migrate := result.Tools[0], result.Tools[1], result.Tools[2]
+	if foo.Path != "." || foo.Name != "foo" || agent.Name != "foo-agent" || migrate.Name != "foo-migrate" {
+		t.Errorf("tools = %s %s, %s, %s", foo.Path, foo.Name, agent.Name, migrate.Name)
+	}
+	if foo.Status != OverallPartial {
+		t.Errorf("foo = %s: %+v", foo.Status, foo.Checks)
+	}
+	if got := status(agent, CheckHasDoctorCommand); got != StatusWarn {
+		t.Errorf("foo-agent %s = %q, want warn", CheckHasDoctorCommand, got)
+	}
+	for _, name := range []string{CheckSkillMDFlags, CheckSkillMDExitCodes, CheckSkillMDNotDo, CheckHasInitCommand} {
+		if got := status(migrate, name); got != StatusFail {
+			t.Errorf("foo-migrate %s = %q, want fail", name, got)
+		}
+	}
+	// The shared sections count for every tool.
+	if got := status(migrate, CheckSkillMDInstall); got != StatusPass {
+		t.Errorf("foo-migrate %s = %q, want pass", CheckSkillMDInstall, got)
+	}
+
+	// Drift needs a single command tree, so it is not checked, and each
+	// tool says so.
+	for _, tool := range result.Tools {
+		if got := status(tool, CheckCommandDrift); got != StatusWarn {
+			t.Errorf("%s: %s = %q, want warn", tool.Name, CheckCommandDrift, got)
+		}
+	}
+	if len(result.Checks) != 1 || result.Status != OverallFail {
+		t.Errorf("repo = %s with %+v", result.Status, result.Checks)
+	}
+
+	// A single ## Tool: heading turns drift off too, and says so.
+	one := "# foo\n\n## Tool: foo\n\n### Commands\n\n#### foo run\n\nRuns.\n"
+	result, err = ValidateTarget(MemoryTarget("mem", fstest.MapFS{"SKILL.md": {Data: []byte(one)}, "pyproject.toml": {}}), Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Tools) != 1 || status(result.Tools[0], CheckCommandDrift) != StatusWarn {
+		t.Errorf("single tool = %+v, want a %s warning", result.Tools, CheckCommandDrift)
+	}
+
+	// Without source an analyzer applies to, there is nothing to skip.
+	result, err = ValidateTarget(MemoryTarget("mem", fstest.MapFS{"SKILL.md": {Data: data}}), Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := status(result.Tools[0], CheckCommandDrift); got != "" {
+		t.Errorf("no source: %s = %q, want none", CheckCommandDrift, got)
+	}
+}
+
+func TestValidateTarget_Locations(t *testing.T) {
+	valid, err := readFile(testdataPath("valid-skill.md"))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	src := `import argparse
+
+parser = argparse.ArgumentParser(prog="mytool")
+sub = parser.add_subparsers()
+run = sub.add_parser("run")
+run.add_argument("--format")
+check = sub.add_parser("check")
+check.add_argument("--format")
+init = sub.add_parser("init")
+doctor = sub.add_parser("doctor")
+doctor.add_argument("--format")
+secret = sub.add_parser("secret")
+`
+	fsys := fstest.MapFS{
+		"cmd/a/SKILL.md":           {Data: valid},
+		"cmd/a/pyproject.toml":     {},
+		"cmd/a/mytool/__main__.py": {Data: []byte(src)},
+		"cmd/b/SKILL.md":           {Data: []byte("# b\n")},
+	}
+	result, err := ValidateTarget(MemoryTarget("mem", fsys), Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	find := func(tool ToolResult, name string) CheckResult {
+		for _, c := range tool.Checks {
+			if c.Name == name {
+				return c
+			}
+		}
+		t.Fatalf("%s: no %s check", tool.Path, name)
+		return CheckResult{}
+	}
+	a, b := result.Tools[0], result.Tools[1]
+	tests := []struct {
+		check CheckResult
+		want  []Location
+	}{
+		{find(a, CheckSkillMDExists), []Location{{Path: "cmd/a/SKILL.md"}}},
+		{find(a, CheckSkillMDInstall), []Location{{Path: "cmd/a/SKILL.md", Line: 5}}},
+		{find(a, CheckSkillMDFlags), []Location{{Path: "cmd/a/SKILL.md", Line: 18}}},
+		{find(a, CheckHasInitCommand), []Location{{Path: "cmd/a/SKILL.md", Line: 51}}},
+		// --verbose is documented at line 19 but not implemented; secret is
+		// implemented at line 12 but not documented.
+		{find(a, CheckCommandDrift), []Location{{Path: "cmd/a/SKILL.md", Line: 19}, {Path: "cmd/a/mytool/__main__.py", Line: 12}}},
+		// Missing sections point at the H1 heading they belong under.
+		{find(b, CheckSkillMDInstall), []Location{{Path: "cmd/b/SKILL.md", Line: 1}}},
+		{find(b, CheckHasInitCommand), []Location{{Path: "cmd/b/SKILL.md", Line: 1}}},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.check.Locations, tt.want) {
+			t.Errorf("%s locations = %+v, want %+v", tt.check.Name, tt.check.Locations, tt.want)
+		}
+	}
+	// Release checks point at the top of the first SKILL.md without a
+	// release config, and at the config with one.
+	if locs, want := result.Checks[0].Locations, []Location{{Path: "cmd/a/SKILL.md", Line: 1}}; !reflect.DeepEqual(locs, want) {
+		t.Errorf("%s locations = %+v, want %+v", result.Checks[0].Name, locs, want)
+	}
+	fsys[".goreleaser.yaml"] = &fstest.MapFile{Data: []byte("builds:\n  - goos: [linux]\n")}
+	result, err = ValidateTarget(MemoryTarget("mem", fsys), Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if locs, want := result.Checks[0].Locations, []Location{{Path: ".goreleaser.yaml"}}; !reflect.DeepEqual(locs, want) {
+		t.Errorf("%s locations = %+v, want %+v", result.Checks[0].Name, locs, want)
+	}
+}
+
 func TestValidate_MissingSections(t *testing.T) {
 	dir := t.TempDir()
 	data, err := readFile(testdataPath("missing-sections.md"))
@@ -328,3 +647,29 @@ func TestComputeSummary_WithFail(t *testing.T) {
 		t.Errorf("total = %d, want 3", r.Summary.Total)
 	}
 }
+
+func TestValidate_PythonRepoAddsDriftCheck(t *testing.T) {
+	dir := t.TempDir()
+	data, err := readFile(testdataPath("valid-skill.md"))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	if err := writeFile(filepath.Join(dir, "SKILL.md"), data); err != nil {
+		t.Fatalf("failed to write SKILL.md: %v", err)
+	}
+	if err := writeFile(filepath.Join(dir, "pyproject.toml"), []byte("[project]\nname = \"mytool\"\n")); err != nil {
+		t.Fatalf("failed to write pyproject.toml: %v", err)
+	}
+
+	result, err := Validate(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Summary.Total != 12 {
+		t.Errorf("total = %d, want 12", result.Summary.Total)
+	}
+	last := result.Checks[len(result.Checks)-1]
+	if last.Name != CheckCommandDrift {
+		t.Errorf("last check = %q, want %q", last.Name, CheckCommandDrift)
+	}
+}